@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth/jwt"
+	"mall/pkg/ginx"
+)
+
+// Claim 取出中间件写入的当前用户，没有登录时返回 nil
+func Claim(c *gin.Context) (*jwt.Claim, bool) {
+	claims, ok := c.Get("claims")
+	if !ok {
+		return nil, false
+	}
+
+	return claims.(*jwt.Claim), true
+}
+
+// UserClaim 取出当前用户，没有登录时直接写回 401
+func UserClaim(c *gin.Context) (*jwt.Claim, bool) {
+	claim, ok := Claim(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ginx.GetResponse(ginx.WithStatus(http.StatusUnauthorized), ginx.WithMsg("you need login")))
+		return nil, false
+	}
+
+	return claim, true
+}
+
+// MerchantClaim 取出当前用户，非商家时直接写回 403
+func MerchantClaim(c *gin.Context) (*jwt.Claim, bool) {
+	claim, ok := UserClaim(c)
+	if !ok {
+		return nil, false
+	}
+	if !claim.IsMerchant {
+		c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("merchant only")))
+		return nil, false
+	}
+
+	return claim, true
+}
+
+// AdminClaim 取出当前用户，非管理员时直接写回 403
+func AdminClaim(c *gin.Context) (*jwt.Claim, bool) {
+	claim, ok := UserClaim(c)
+	if !ok {
+		return nil, false
+	}
+	if !claim.IsAdmin {
+		c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("admin only")))
+		return nil, false
+	}
+
+	return claim, true
+}
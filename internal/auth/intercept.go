@@ -3,6 +3,7 @@ package auth
 import (
 	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +13,8 @@ import (
 
 type TokenEffectiveBuilder struct {
 	paths      map[string]struct{}
+	prefixes   []string
+	optional   map[string]struct{}
 	jwtHdl     *jwt.TokenHandler
 	sessionHdl *jwt.RedisSession
 }
@@ -19,6 +22,7 @@ type TokenEffectiveBuilder struct {
 func NewTokenEffectiveBuilder(jwtHdl *jwt.TokenHandler, sessionHdl *jwt.RedisSession) *TokenEffectiveBuilder {
 	return &TokenEffectiveBuilder{
 		paths:      make(map[string]struct{}),
+		optional:   make(map[string]struct{}),
 		jwtHdl:     jwtHdl,
 		sessionHdl: sessionHdl,
 	}
@@ -29,15 +33,38 @@ func (b *TokenEffectiveBuilder) IgnorePath(path string) *TokenEffectiveBuilder {
 	return b
 }
 
+// IgnorePrefix 忽略以 prefix 开头的所有路径
+func (b *TokenEffectiveBuilder) IgnorePrefix(prefix string) *TokenEffectiveBuilder {
+	b.prefixes = append(b.prefixes, prefix)
+	return b
+}
+
+// Optional 登录可选的路由，fullPath 为注册时的路由模式，如 /api/products/:id
+// 同一路由模式的其他方法仍需登录，没有携带 token 时作为匿名请求放行，携带了则照常校验并写入 claims
+func (b *TokenEffectiveBuilder) Optional(method, fullPath string) *TokenEffectiveBuilder {
+	b.optional[method+" "+fullPath] = struct{}{}
+	return b
+}
+
 func (b *TokenEffectiveBuilder) Check() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if _, ok := b.paths[c.Request.URL.Path]; ok {
 			c.Next()
 			return
 		}
+		for _, prefix := range b.prefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
 
 		// 提取并检查 token
 		tokenHeader := b.jwtHdl.ExtractToken(c)
+		if _, ok := b.optional[c.Request.Method+" "+c.FullPath()]; ok && tokenHeader == "" {
+			c.Next()
+			return
+		}
 
 		claims, err := b.jwtHdl.ParseToken(tokenHeader)
 		if err != nil {
@@ -66,7 +93,7 @@ func (b *TokenEffectiveBuilder) Check() gin.HandlerFunc {
 
 		// 检查 JWT 是否快过期，若是，则生成新 JWT
 		if time.Until(time.Unix(claims.ExpiresAt, 0)) < time.Minute*5 {
-			err := b.jwtHdl.GenerateToken(c, claims.Id, claims.SessionId, claims.IsMerchant)
+			err := b.jwtHdl.GenerateToken(c, claims.Id, claims.SessionId, claims.IsMerchant, claims.IsAdmin)
 			if err != nil {
 				c.AbortWithStatus(http.StatusBadRequest)
 				return
@@ -25,6 +25,7 @@ type Claim struct {
 	Id         uint64
 	SessionId  string
 	IsMerchant bool
+	IsAdmin    bool
 }
 
 func NewJwtHandler() *TokenHandler {
@@ -33,11 +34,12 @@ func NewJwtHandler() *TokenHandler {
 	}
 }
 
-func (h *TokenHandler) GenerateToken(ctx *gin.Context, id uint64, sessionId string, isMerchant bool) error {
+func (h *TokenHandler) GenerateToken(ctx *gin.Context, id uint64, sessionId string, isMerchant, isAdmin bool) error {
 	claim := Claim{
 		Id:         id,
 		SessionId:  sessionId,
 		IsMerchant: isMerchant,
+		IsAdmin:    isAdmin,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(time.Hour * 1).Unix(),
 		},
@@ -1,7 +1,113 @@
 package domain
 
+import "time"
+
+const (
+	// MaxCartItems 购物车中不同商品的数量上限
+	MaxCartItems = 100
+	// MaxItemQuantity 单个商品的数量上限
+	MaxItemQuantity = 99
+)
+
 type CartItem struct {
-	ProductID uint64
-	Quantity  int
-	UserID    uint64
+	ProductID uint64 `json:"productId"`
+	Quantity  int    `json:"quantity"`
+	UserID    uint64 `json:"-"`
+	Selected  bool   `json:"selected"` // 是否勾选结算
+	// 加入购物车时的单价
+	AddedPrice float64   `json:"addedPrice"`
+	AddedAt    time.Time `json:"addedAt"`
+	Note       string    `json:"note"` // 备注，如礼品留言
+}
+
+// SavedItem 稍后购买的商品，移回购物车时带回数量和备注
+type SavedItem struct {
+	ProductID  uint64  `json:"productId"`
+	UserID     uint64  `json:"-"`
+	Quantity   int     `json:"quantity"`
+	Note       string  `json:"note"`
+	AddedPrice float64 `json:"addedPrice"`
+	SavedAt    int64   `json:"savedAt"`
+}
+
+const (
+	LineAvailable    = "available"
+	LineOffList      = "offlist"      // 已下架
+	LineDeleted      = "deleted"      // 已删除
+	LineOutOfStock   = "outofstock"   // 无货
+	LineInsufficient = "insufficient" // 库存少于购买数量
+)
+
+// CartLine 购物车中的一行，商品信息为读取时的实时数据
+type CartLine struct {
+	ProductId    uint64  `json:"productId"`
+	Name         string  `json:"name"`
+	ImageUrl     string  `json:"imageUrl"`
+	Price        float64 `json:"price"`      // 当前单价
+	AddedPrice   float64 `json:"addedPrice"` // 加入购物车时的单价
+	PriceChanged bool    `json:"priceChanged"`
+	PriceDiff    float64 `json:"priceDiff"` // 当前单价减去加购价，负数为降价
+	Quantity     int     `json:"quantity"`
+	Stock        int     `json:"stock"`
+	Selected     bool    `json:"selected"`
+	Status       string  `json:"status"`
+	Subtotal     float64 `json:"subtotal"` // 当前单价乘以数量
+	Note         string  `json:"note,omitempty"`
+}
+
+// CartView 购物车视图，合计只统计勾选且可购买的行
+type CartView struct {
+	Lines            []CartLine `json:"lines"`
+	SelectedQuantity int        `json:"selectedQuantity"`
+	Subtotal         float64    `json:"subtotal"`
+}
+
+const (
+	MergeMerged  = "merged"  // 已合并
+	MergeCapped  = "capped"  // 已合并，数量超过库存或上限被截断
+	MergeSkipped = "skipped" // 没有合并，原因见 Reason
+
+	MergeReasonCartFull = "cartfull" // 购物车中的商品种数已达上限
+)
+
+// MergeLine 游客购物车中的一个商品合并到用户购物车的结果
+type MergeLine struct {
+	ProductId        uint64 `json:"productId"`
+	GuestQuantity    int    `json:"guestQuantity"`    // 游客购物车中的数量
+	PreviousQuantity int    `json:"previousQuantity"` // 合并前用户购物车中的数量
+	Quantity         int    `json:"quantity"`         // 合并后的数量
+	Status           string `json:"status"`
+	// 没有合并的原因，为 LineDeleted、LineOffList、LineOutOfStock 或 MergeReasonCartFull
+	Reason string `json:"reason,omitempty"`
+}
+
+// MergeResult 登录时合并游客购物车的结果，客户端据此提示用户并丢弃游客凭证
+type MergeResult struct {
+	Lines   []MergeLine `json:"lines"`
+	Merged  int         `json:"merged"`
+	Skipped int         `json:"skipped"`
+}
+
+// HoldLine 结算时一个商品的库存预占
+type HoldLine struct {
+	ProductId uint64 `json:"productId"`
+	Quantity  int    `json:"quantity"`
+	Stock     int    `json:"-"`         // 数据库中的库存
+	Available int    `json:"available"` // 库存不足时为扣除其他用户预占后的可用数量
+	Status    string `json:"status"`    // 同 CartLine.Status
+}
+
+// Reservation 结算时的预占结果，库存不足时 Shortages 不为空且没有预占任何商品
+type Reservation struct {
+	Lines     []HoldLine `json:"lines"`
+	Shortages []HoldLine `json:"shortages"`
+	ExpireAt  int64      `json:"expireAt"` // 预占的过期时间，毫秒
+}
+
+// Inventory 商品库存中可售和已被预占的数量
+type Inventory struct {
+	ProductId uint64 `json:"productId"`
+	Stock     int    `json:"stock"`
+	Reserved  int    `json:"reserved"`
+	Available int    `json:"available"`
 }
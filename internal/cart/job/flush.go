@@ -0,0 +1,93 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mall/internal/cart/repository"
+	"mall/pkg/logger"
+	"mall/pkg/redisx"
+)
+
+const (
+	flushLockKey = "cart:flush:lock"
+	flushBatch   = 200
+)
+
+// Flusher 异步写回购物车改动，只有 Redis 优先的实现需要
+type Flusher interface {
+	Flush(ctx context.Context, batch int64) (int, error)
+}
+
+// FlushJob 定期把 Redis 中的购物车改动写回 MySQL，多实例通过 Redis 锁互斥，
+// 避免同一用户的旧数据覆盖新数据
+type FlushJob struct {
+	repo     repository.CartRepository
+	cmd      redis.Cmdable
+	l        logger.Logger
+	interval time.Duration
+}
+
+func NewFlushJob(repo repository.CartRepository, cmd redis.Cmdable, l logger.Logger) *FlushJob {
+	return &FlushJob{
+		repo:     repo,
+		cmd:      cmd,
+		l:        l,
+		interval: time.Second * 5,
+	}
+}
+
+func (j *FlushJob) Start(ctx context.Context) {
+	flusher, ok := j.repo.(Flusher)
+	// 只使用 MySQL 时没有需要写回的数据
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.run(ctx, flusher)
+		}
+	}
+}
+
+func (j *FlushJob) run(ctx context.Context, flusher Flusher) {
+	lock := redisx.NewLock(j.cmd, flushLockKey, time.Minute)
+	ok, err := lock.TryLock(ctx)
+	if err != nil {
+		j.l.Error("购物车写回:获取锁失败", logger.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := lock.Unlock(context.Background()); err != nil {
+			j.l.Warn("购物车写回:释放锁失败", logger.Error(err))
+		}
+	}()
+
+	total := 0
+	for ctx.Err() == nil {
+		n, err := flusher.Flush(ctx, flushBatch)
+		total += n
+		if err != nil {
+			j.l.Error("购物车写回:写回失败", logger.Error(err))
+			break
+		}
+		if n < flushBatch {
+			break
+		}
+	}
+	if total > 0 {
+		j.l.Debug("购物车写回:写回成功", logger.Field{Key: "count", Val: total})
+	}
+}
@@ -0,0 +1,361 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mall/internal/cart/domain"
+	"mall/internal/cart/repository/dao"
+)
+
+var (
+	ErrCartNotLoaded    = errors.New("cart is not loaded into cache")
+	ErrQuantityExceeded = fmt.Errorf("quantity of one product must not exceed %d", domain.MaxItemQuantity)
+	ErrCartFull         = fmt.Errorf("cart can hold at most %d products", domain.MaxCartItems)
+)
+
+//go:embed lua/load.lua
+var luaLoad string
+
+//go:embed lua/add.lua
+var luaAdd string
+
+//go:embed lua/quantity.lua
+var luaQuantity string
+
+//go:embed lua/delete.lua
+var luaDelete string
+
+//go:embed lua/select.lua
+var luaSelect string
+
+//go:embed lua/empty.lua
+var luaEmpty string
+
+//go:embed lua/note.lua
+var luaNote string
+
+//go:embed lua/settle.lua
+var luaSettle string
+
+const (
+	// 购物车长时间不操作后从缓存淘汰，再次访问时从数据库回填。
+	// 有改动尚未写回时不设过期时间，写回后由 Settle 恢复，Redis 的淘汰策略需要使用 volatile-*
+	cartTTL = time.Hour * 24 * 7
+	// 游客购物车只在 Redis 中，过期后即丢失
+	guestCartTTL = time.Hour * 24 * 30
+	// 登录时游客购物车的合并结果，客户端登录后读取
+	mergeResultTTL = time.Minute * 10
+	// 有改动尚未写回数据库的用户
+	dirtyKey = "cart:dirty"
+	// 标记字段，空购物车也保留该字段，用来区分空购物车和未回填
+	loadedField = "_"
+)
+
+// CartCache 购物车存放在 hash 中，每个商品占四到五个字段：
+// q:<商品 id> 数量，s:<商品 id> 是否勾选，p:<商品 id> 加购价，t:<商品 id> 加入时间（毫秒），
+// 有备注时还有 n:<商品 id> 备注
+type CartCache struct {
+	cmd    redis.Cmdable
+	prefix string
+	// 为空时改动不写回数据库
+	dirtyKey string
+	ttl      time.Duration
+}
+
+func NewCartCache(cmd redis.Cmdable) *CartCache {
+	return &CartCache{
+		cmd:      cmd,
+		prefix:   "cart",
+		dirtyKey: dirtyKey,
+		ttl:      cartTTL,
+	}
+}
+
+// GuestCartCache 游客购物车，以游客 id 代替用户 id，改动不写回数据库
+type GuestCartCache struct {
+	CartCache
+}
+
+func NewGuestCartCache(cmd redis.Cmdable) *GuestCartCache {
+	return &GuestCartCache{
+		CartCache: CartCache{
+			cmd:    cmd,
+			prefix: "cart:guest",
+			ttl:    guestCartTTL,
+		},
+	}
+}
+
+func (cache *GuestCartCache) SetMergeResult(ctx context.Context, uid uint64, res domain.MergeResult) error {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+
+	return cache.cmd.Set(ctx, cache.mergeKey(uid), data, mergeResultTTL).Err()
+}
+
+func (cache *GuestCartCache) GetMergeResult(ctx context.Context, uid uint64) (*domain.MergeResult, error) {
+	data, err := cache.cmd.Get(ctx, cache.mergeKey(uid)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var res domain.MergeResult
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (cache *GuestCartCache) mergeKey(uid uint64) string {
+	return fmt.Sprintf("cart:merge:%d", uid)
+}
+
+// Load 用数据库中的购物车回填缓存，缓存中已有时不覆盖
+func (cache *CartCache) Load(ctx context.Context, uid uint64, items []domain.CartItem) error {
+	args := []any{int64(cache.ttl.Seconds()), loadedField, 1}
+	for _, item := range items {
+		pid := strconv.FormatUint(item.ProductID, 10)
+		selected := 0
+		if item.Selected {
+			selected = 1
+		}
+		args = append(args,
+			"q:"+pid, item.Quantity,
+			"s:"+pid, selected,
+			"p:"+pid, item.AddedPrice,
+			"t:"+pid, item.AddedAt.UnixMilli(),
+		)
+		if item.Note != "" {
+			args = append(args, "n:"+pid, item.Note)
+		}
+	}
+
+	return cache.cmd.Eval(ctx, luaLoad, []string{cache.key(uid)}, args...).Err()
+}
+
+// Get 读取购物车，购物车不在缓存中时返回 ErrCartNotLoaded
+func (cache *CartCache) Get(ctx context.Context, uid uint64) ([]domain.CartItem, error) {
+	fields, err := cache.cmd.HGetAll(ctx, cache.key(uid)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, ErrCartNotLoaded
+	}
+
+	return cache.parse(uid, fields), nil
+}
+
+// parse 把 hash 的字段还原为购物车商品，按加入时间排序
+func (cache *CartCache) parse(uid uint64, fields map[string]string) []domain.CartItem {
+	items := make(map[uint64]*domain.CartItem)
+	for field, val := range fields {
+		kind, rawPid, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+		pid, err := strconv.ParseUint(rawPid, 10, 64)
+		if err != nil {
+			continue
+		}
+		item, ok := items[pid]
+		if !ok {
+			item = &domain.CartItem{ProductID: pid, UserID: uid}
+			items[pid] = item
+		}
+
+		switch kind {
+		case "q":
+			item.Quantity, _ = strconv.Atoi(val)
+		case "s":
+			item.Selected = val == "1"
+		case "p":
+			item.AddedPrice, _ = strconv.ParseFloat(val, 64)
+		case "t":
+			ms, _ := strconv.ParseInt(val, 10, 64)
+			item.AddedAt = time.UnixMilli(ms)
+		case "n":
+			item.Note = val
+		}
+	}
+
+	res := make([]domain.CartItem, 0, len(items))
+	for _, item := range items {
+		// 只剩部分字段的商品视为已删除
+		if item.Quantity > 0 {
+			res = append(res, *item)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].AddedAt.Equal(res[j].AddedAt) {
+			return res[i].ProductID < res[j].ProductID
+		}
+		return res[i].AddedAt.Before(res[j].AddedAt)
+	})
+	return res
+}
+
+// Add 累加商品数量，返回累加后的数量；超过单个商品或商品种数上限时不修改
+func (cache *CartCache) Add(ctx context.Context, item domain.CartItem) (int, error) {
+	res, err := cache.eval(ctx, luaAdd, item.UserID,
+		item.ProductID, item.Quantity, item.AddedPrice, time.Now().UnixMilli(), item.Note,
+		domain.MaxItemQuantity, domain.MaxCartItems)
+	if err != nil {
+		return 0, err
+	}
+	switch res {
+	case -2:
+		return 0, ErrQuantityExceeded
+	case -3:
+		return 0, ErrCartFull
+	}
+
+	return int(res), nil
+}
+
+func (cache *CartCache) SetQuantity(ctx context.Context, uid, productId uint64, quantity int) error {
+	res, err := cache.eval(ctx, luaQuantity, uid, productId, quantity)
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return dao.ErrItemNotFound
+	}
+
+	return nil
+}
+
+// SetNote 修改备注，note 为空时清除备注
+func (cache *CartCache) SetNote(ctx context.Context, uid, productId uint64, note string) error {
+	res, err := cache.eval(ctx, luaNote, uid, productId, note)
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return dao.ErrItemNotFound
+	}
+
+	return nil
+}
+
+// Delete 删除购物车中的商品，返回实际删除的数量
+func (cache *CartCache) Delete(ctx context.Context, uid uint64, productIds []uint64) (int64, error) {
+	args := make([]any, 0, len(productIds))
+	for _, id := range productIds {
+		args = append(args, id)
+	}
+
+	return cache.eval(ctx, luaDelete, uid, args...)
+}
+
+// SetSelected 修改勾选状态，productIds 为空时修改全部商品
+func (cache *CartCache) SetSelected(ctx context.Context, uid uint64, productIds []uint64, selected bool) error {
+	args := make([]any, 0, len(productIds)+1)
+	if selected {
+		args = append(args, 1)
+	} else {
+		args = append(args, 0)
+	}
+	for _, id := range productIds {
+		args = append(args, id)
+	}
+
+	_, err := cache.eval(ctx, luaSelect, uid, args...)
+	return err
+}
+
+func (cache *CartCache) Empty(ctx context.Context, uid uint64) error {
+	_, err := cache.eval(ctx, luaEmpty, uid)
+	return err
+}
+
+// PopDirty 取出最多 count 个有改动待写回的用户，取出后由调用方负责写回
+func (cache *CartCache) PopDirty(ctx context.Context, count int64) ([]uint64, error) {
+	vals, err := cache.cmd.SPopN(ctx, cache.dirtyKey, count).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make([]uint64, 0, len(vals))
+	for _, val := range vals {
+		uid, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			continue
+		}
+		uids = append(uids, uid)
+	}
+	return uids, nil
+}
+
+// DirtyUsers 有改动待写回的全部用户，不取出
+func (cache *CartCache) DirtyUsers(ctx context.Context) ([]uint64, error) {
+	vals, err := cache.cmd.SMembers(ctx, cache.dirtyKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make([]uint64, 0, len(vals))
+	for _, val := range vals {
+		uid, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			continue
+		}
+		uids = append(uids, uid)
+	}
+	return uids, nil
+}
+
+// MarkDirty 把用户重新放回待写回集合，写回失败时使用
+func (cache *CartCache) MarkDirty(ctx context.Context, uids ...uint64) error {
+	if len(uids) == 0 {
+		return nil
+	}
+
+	members := make([]any, 0, len(uids))
+	for _, uid := range uids {
+		members = append(members, uid)
+	}
+	return cache.cmd.SAdd(ctx, cache.dirtyKey, members...).Err()
+}
+
+// Settle 写回数据库后恢复购物车的过期时间，期间又有改动时不恢复
+func (cache *CartCache) Settle(ctx context.Context, uid uint64) error {
+	return cache.cmd.Eval(ctx, luaSettle, []string{cache.key(uid), cache.dirtyKey}, uid, int64(cache.ttl.Seconds())).Err()
+}
+
+// eval 执行修改购物车的脚本，脚本返回 -1 表示购物车不在缓存中
+func (cache *CartCache) eval(ctx context.Context, script string, uid uint64, args ...any) (int64, error) {
+	keys := []string{cache.key(uid)}
+	if cache.dirtyKey != "" {
+		keys = append(keys, cache.dirtyKey)
+	}
+	argv := append([]any{uid, int64(cache.ttl.Seconds())}, args...)
+	res, err := cache.cmd.Eval(ctx, script, keys, argv...).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if res == -1 {
+		return 0, ErrCartNotLoaded
+	}
+
+	return res, nil
+}
+
+func (cache *CartCache) key(uid uint64) string {
+	return fmt.Sprintf("%s:%d", cache.prefix, uid)
+}
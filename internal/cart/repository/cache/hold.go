@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mall/internal/cart/domain"
+)
+
+//go:embed lua/hold_reserve.lua
+var luaHoldReserve string
+
+//go:embed lua/hold_release.lua
+var luaHoldRelease string
+
+//go:embed lua/hold_reserved.lua
+var luaHoldReserved string
+
+// HoldCache 结算时的库存预占。每个商品一个 hash 记录各用户预占的数量和未过期预占的总数，
+// 一个 zset 记录各用户预占的过期时间；每个用户一个 hash 记录自己预占了哪些商品
+type HoldCache struct {
+	cmd redis.Cmdable
+}
+
+func NewHoldCache(cmd redis.Cmdable) *HoldCache {
+	return &HoldCache{
+		cmd: cmd,
+	}
+}
+
+// Reserve 预占库存，全部成功时返回 nil；
+// 任一商品可用库存不足时一个都不预占，返回库存不足的商品及其可用数量
+func (cache *HoldCache) Reserve(ctx context.Context, uid uint64, lines []domain.HoldLine, ttl time.Duration) ([]domain.HoldLine, error) {
+	now := time.Now()
+	keys := make([]string, 0, len(lines)*2+1)
+	keys = append(keys, cache.userKey(uid))
+	args := make([]any, 0, len(lines)*3+4)
+	args = append(args, uid, now.UnixMilli(), now.Add(ttl).UnixMilli(), int64(ttl.Seconds()))
+	for _, line := range lines {
+		keys = append(keys, cache.holdKey(line.ProductId), cache.expireKey(line.ProductId))
+		args = append(args, line.ProductId, line.Quantity, line.Stock)
+	}
+
+	res, err := cache.cmd.Eval(ctx, luaHoldReserve, keys, args...).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	quantityOf := make(map[uint64]domain.HoldLine, len(lines))
+	for _, line := range lines {
+		quantityOf[line.ProductId] = line
+	}
+	short := make([]domain.HoldLine, 0, len(res)/2)
+	for i := 0; i+1 < len(res); i += 2 {
+		pid, _ := strconv.ParseUint(fmt.Sprint(res[i]), 10, 64)
+		available, _ := res[i+1].(int64)
+		line := quantityOf[pid]
+		line.Available = int(available)
+		short = append(short, line)
+	}
+	if len(short) == 0 {
+		return nil, nil
+	}
+	return short, nil
+}
+
+// Release 释放用户对商品的预占，返回实际释放的商品数
+func (cache *HoldCache) Release(ctx context.Context, uid uint64, productIds []uint64) (int64, error) {
+	if len(productIds) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, 0, len(productIds)*2+1)
+	keys = append(keys, cache.userKey(uid))
+	args := make([]any, 0, len(productIds)+1)
+	args = append(args, uid)
+	for _, id := range productIds {
+		keys = append(keys, cache.holdKey(id), cache.expireKey(id))
+		args = append(args, id)
+	}
+
+	return cache.cmd.Eval(ctx, luaHoldRelease, keys, args...).Int64()
+}
+
+// Reserved 商品未过期预占的总数，和 productIds 一一对应
+func (cache *HoldCache) Reserved(ctx context.Context, productIds []uint64) ([]int, error) {
+	if len(productIds) == 0 {
+		return []int{}, nil
+	}
+
+	keys := make([]string, 0, len(productIds)*2)
+	for _, id := range productIds {
+		keys = append(keys, cache.holdKey(id), cache.expireKey(id))
+	}
+	vals, err := cache.cmd.Eval(ctx, luaHoldReserved, keys, time.Now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]int, 0, len(vals))
+	for _, v := range vals {
+		res = append(res, max(int(v), 0))
+	}
+	return res, nil
+}
+
+// UserHolds 用户当前预占的商品和数量，以及预占的过期时间
+func (cache *HoldCache) UserHolds(ctx context.Context, uid uint64) (map[uint64]int, time.Time, error) {
+	var (
+		getAll *redis.MapStringStringCmd
+		ttl    *redis.DurationCmd
+	)
+	_, err := cache.cmd.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		getAll = pipe.HGetAll(ctx, cache.userKey(uid))
+		ttl = pipe.PTTL(ctx, cache.userKey(uid))
+		return nil
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	holds := make(map[uint64]int, len(getAll.Val()))
+	for field, val := range getAll.Val() {
+		pid, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			continue
+		}
+		holds[pid], _ = strconv.Atoi(val)
+	}
+	var expireAt time.Time
+	if ttl.Val() > 0 {
+		expireAt = time.Now().Add(ttl.Val())
+	}
+	return holds, expireAt, nil
+}
+
+func (cache *HoldCache) userKey(uid uint64) string {
+	return fmt.Sprintf("stock:hold:user:%d", uid)
+}
+
+func (cache *HoldCache) holdKey(productId uint64) string {
+	return fmt.Sprintf("stock:hold:%d", productId)
+}
+
+func (cache *HoldCache) expireKey(productId uint64) string {
+	return fmt.Sprintf("stock:hold:expire:%d", productId)
+}
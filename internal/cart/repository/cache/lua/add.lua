@@ -0,0 +1,50 @@
+-- 加入购物车，已在购物车中时累加数量
+-- KEYS[1] 购物车 hash，KEYS[2] 待写回的用户集合（可选）
+-- ARGV: 用户 id，过期时间，商品 id，数量，加购价，加入时间，备注（为空时保留原备注），
+-- 单个商品的数量上限，不同商品的数量上限
+-- 返回累加后的数量，-1 购物车不在缓存中，-2 超过单个商品上限，-3 超过商品种数上限
+local key = KEYS[1]
+local uid = ARGV[1]
+local pid = ARGV[3]
+local maxQuantity = tonumber(ARGV[8])
+local maxItems = tonumber(ARGV[9])
+
+-- 购物车不在缓存中，需要先从数据库回填
+if redis.call("exists", key) == 0 then
+    return -1
+end
+
+local current = redis.call("hget", key, "q:"..pid)
+if current then
+    if tonumber(current) + tonumber(ARGV[4]) > maxQuantity then
+        return -2
+    end
+else
+    if tonumber(ARGV[4]) > maxQuantity then
+        return -2
+    end
+    local count = 0
+    for _, field in ipairs(redis.call("hkeys", key)) do
+        if string.sub(field, 1, 2) == "q:" then
+            count = count + 1
+        end
+    end
+    if count >= maxItems then
+        return -3
+    end
+    -- 新加入的商品默认勾选
+    redis.call("hset", key, "s:"..pid, 1, "t:"..pid, ARGV[6])
+end
+local quantity = redis.call("hincrby", key, "q:"..pid, ARGV[4])
+redis.call("hset", key, "p:"..pid, ARGV[5])
+if ARGV[7] ~= "" then
+    redis.call("hset", key, "n:"..pid, ARGV[7])
+end
+
+if KEYS[2] then
+    redis.call("persist", key)
+    redis.call("sadd", KEYS[2], uid)
+else
+    redis.call("expire", key, ARGV[2])
+end
+return quantity
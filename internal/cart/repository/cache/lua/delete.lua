@@ -0,0 +1,25 @@
+-- 删除购物车中的商品，返回实际删除的数量
+-- KEYS[1] 购物车 hash，KEYS[2] 待写回的用户集合（可选）
+-- ARGV: 用户 id，过期时间，商品 id...
+local key = KEYS[1]
+
+if redis.call("exists", key) == 0 then
+    return -1
+end
+
+local removed = 0
+for i = 3, #ARGV do
+    local pid = ARGV[i]
+    removed = removed + redis.call("hdel", key, "q:"..pid)
+    redis.call("hdel", key, "s:"..pid, "p:"..pid, "t:"..pid, "n:"..pid)
+end
+
+if removed > 0 then
+    if KEYS[2] then
+        redis.call("persist", key)
+        redis.call("sadd", KEYS[2], ARGV[1])
+    else
+        redis.call("expire", key, ARGV[2])
+    end
+end
+return removed
@@ -0,0 +1,14 @@
+-- 清空购物车，保留标记字段表示购物车已在缓存中
+-- KEYS[1] 购物车 hash，KEYS[2] 待写回的用户集合（可选）
+-- ARGV: 用户 id，过期时间
+local key = KEYS[1]
+
+redis.call("del", key)
+redis.call("hset", key, "_", 1)
+if KEYS[2] then
+    redis.call("persist", key)
+    redis.call("sadd", KEYS[2], ARGV[1])
+else
+    redis.call("expire", key, ARGV[2])
+end
+return 1
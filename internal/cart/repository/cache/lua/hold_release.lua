@@ -0,0 +1,19 @@
+-- 释放用户对商品的预占
+-- KEYS[1] 用户的预占记录，之后每个商品两个 key：预占数量 hash 和过期时间 zset
+-- ARGV: 用户 id，之后为每个商品的 id
+local uid = ARGV[1]
+local n = (#KEYS - 1) / 2
+
+local released = 0
+for i = 1, n do
+    local holdKey, expireKey = KEYS[2 * i], KEYS[2 * i + 1]
+    local q = redis.call("hget", holdKey, uid)
+    if q then
+        redis.call("hincrby", holdKey, "_reserved", -tonumber(q))
+        redis.call("hdel", holdKey, uid)
+        released = released + 1
+    end
+    redis.call("zrem", expireKey, uid)
+    redis.call("hdel", KEYS[1], ARGV[i + 1])
+end
+return released
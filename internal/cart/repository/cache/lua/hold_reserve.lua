@@ -0,0 +1,60 @@
+-- 结算时预占库存，所有商品都有足够的可用库存时才预占，否则一个都不预占
+-- KEYS[1] 用户的预占记录，之后每个商品两个 key：预占数量 hash 和过期时间 zset
+-- ARGV: 用户 id，当前时间（毫秒），过期时间（毫秒），用户预占记录的过期时间（秒），
+-- 之后每个商品三个参数：商品 id，数量，数据库中的库存
+local uid = ARGV[1]
+local now = tonumber(ARGV[2])
+local expireAt = tonumber(ARGV[3])
+local n = (#KEYS - 1) / 2
+
+-- 清理已过期的预占，hash 中的 _reserved 字段为未过期预占的总数
+local function purge(holdKey, expireKey)
+    local expired = redis.call("zrangebyscore", expireKey, "-inf", now)
+    for _, member in ipairs(expired) do
+        local q = redis.call("hget", holdKey, member)
+        if q then
+            redis.call("hincrby", holdKey, "_reserved", -tonumber(q))
+            redis.call("hdel", holdKey, member)
+        end
+        redis.call("zrem", expireKey, member)
+    end
+end
+
+-- 返回库存不足的商品 id 和可用数量
+local short = {}
+for i = 1, n do
+    local holdKey, expireKey = KEYS[2 * i], KEYS[2 * i + 1]
+    purge(holdKey, expireKey)
+
+    local base = 4 + (i - 1) * 3
+    local quantity = tonumber(ARGV[base + 2])
+    local stock = tonumber(ARGV[base + 3])
+    local reserved = tonumber(redis.call("hget", holdKey, "_reserved") or "0")
+    -- 重复结算时自己原有的预占不占用可用库存
+    local mine = tonumber(redis.call("hget", holdKey, uid) or "0")
+    local available = stock - reserved + mine
+    if available < quantity then
+        table.insert(short, ARGV[base + 1])
+        table.insert(short, math.max(available, 0))
+    end
+end
+if #short > 0 then
+    return short
+end
+
+for i = 1, n do
+    local holdKey, expireKey = KEYS[2 * i], KEYS[2 * i + 1]
+    local base = 4 + (i - 1) * 3
+    local quantity = tonumber(ARGV[base + 2])
+    local mine = tonumber(redis.call("hget", holdKey, uid) or "0")
+
+    redis.call("hset", holdKey, uid, quantity)
+    redis.call("hincrby", holdKey, "_reserved", quantity - mine)
+    redis.call("zadd", expireKey, expireAt, uid)
+    -- 预占时长相同，最新的预占最晚过期
+    redis.call("pexpireat", holdKey, expireAt)
+    redis.call("pexpireat", expireKey, expireAt)
+    redis.call("hset", KEYS[1], ARGV[base + 1], quantity)
+end
+redis.call("expire", KEYS[1], ARGV[4])
+return short
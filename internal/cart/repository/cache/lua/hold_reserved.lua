@@ -0,0 +1,21 @@
+-- 读取商品未过期的预占总数，顺便清理已过期的预占
+-- KEYS 每个商品两个 key：预占数量 hash 和过期时间 zset
+-- ARGV[1] 当前时间（毫秒）
+local now = tonumber(ARGV[1])
+local n = #KEYS / 2
+
+local res = {}
+for i = 1, n do
+    local holdKey, expireKey = KEYS[2 * i - 1], KEYS[2 * i]
+    local expired = redis.call("zrangebyscore", expireKey, "-inf", now)
+    for _, member in ipairs(expired) do
+        local q = redis.call("hget", holdKey, member)
+        if q then
+            redis.call("hincrby", holdKey, "_reserved", -tonumber(q))
+            redis.call("hdel", holdKey, member)
+        end
+        redis.call("zrem", expireKey, member)
+    end
+    table.insert(res, tonumber(redis.call("hget", holdKey, "_reserved") or "0"))
+end
+return res
@@ -0,0 +1,11 @@
+-- 从数据库回填购物车，已被其他请求回填时不覆盖
+-- KEYS[1] 购物车 hash，ARGV[1] 过期时间，ARGV[2..] 字段和值
+local key = KEYS[1]
+
+if redis.call("exists", key) == 1 then
+    return 0
+end
+
+redis.call("hset", key, unpack(ARGV, 2))
+redis.call("expire", key, ARGV[1])
+return 1
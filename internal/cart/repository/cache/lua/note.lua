@@ -0,0 +1,27 @@
+-- 修改购物车中商品的备注
+-- KEYS[1] 购物车 hash，KEYS[2] 待写回的用户集合（可选）
+-- ARGV: 用户 id，过期时间，商品 id，备注
+local key = KEYS[1]
+local pid = ARGV[3]
+
+if redis.call("exists", key) == 0 then
+    return -1
+end
+-- 商品不在购物车中
+if redis.call("hexists", key, "q:"..pid) == 0 then
+    return 0
+end
+
+-- 空备注直接删除字段
+if ARGV[4] == "" then
+    redis.call("hdel", key, "n:"..pid)
+else
+    redis.call("hset", key, "n:"..pid, ARGV[4])
+end
+if KEYS[2] then
+    redis.call("persist", key)
+    redis.call("sadd", KEYS[2], ARGV[1])
+else
+    redis.call("expire", key, ARGV[2])
+end
+return 1
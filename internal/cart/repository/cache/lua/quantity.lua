@@ -0,0 +1,22 @@
+-- 修改购物车中商品的数量
+-- KEYS[1] 购物车 hash，KEYS[2] 待写回的用户集合（可选）
+-- ARGV: 用户 id，过期时间，商品 id，数量
+local key = KEYS[1]
+local field = "q:"..ARGV[3]
+
+if redis.call("exists", key) == 0 then
+    return -1
+end
+-- 商品不在购物车中
+if redis.call("hexists", key, field) == 0 then
+    return 0
+end
+
+redis.call("hset", key, field, ARGV[4])
+if KEYS[2] then
+    redis.call("persist", key)
+    redis.call("sadd", KEYS[2], ARGV[1])
+else
+    redis.call("expire", key, ARGV[2])
+end
+return 1
@@ -0,0 +1,36 @@
+-- 修改勾选状态，没有传商品 id 时修改全部商品
+-- KEYS[1] 购物车 hash，KEYS[2] 待写回的用户集合（可选）
+-- ARGV: 用户 id，过期时间，勾选状态，商品 id...
+local key = KEYS[1]
+local selected = ARGV[3]
+
+if redis.call("exists", key) == 0 then
+    return -1
+end
+
+local pids = {}
+if #ARGV > 3 then
+    for i = 4, #ARGV do
+        table.insert(pids, ARGV[i])
+    end
+else
+    for _, field in ipairs(redis.call("hkeys", key)) do
+        if string.sub(field, 1, 2) == "q:" then
+            table.insert(pids, string.sub(field, 3))
+        end
+    end
+end
+
+for _, pid in ipairs(pids) do
+    if redis.call("hexists", key, "q:"..pid) == 1 then
+        redis.call("hset", key, "s:"..pid, selected)
+    end
+end
+
+if KEYS[2] then
+    redis.call("persist", key)
+    redis.call("sadd", KEYS[2], ARGV[1])
+else
+    redis.call("expire", key, ARGV[2])
+end
+return 1
@@ -0,0 +1,8 @@
+-- 写回数据库后恢复购物车的过期时间，写回期间又有改动时保持不过期，等下次写回
+-- KEYS[1] 购物车 hash，KEYS[2] 待写回的用户集合
+-- ARGV: 用户 id，过期时间
+if redis.call("sismember", KEYS[2], ARGV[1]) == 1 then
+    return 0
+end
+redis.call("expire", KEYS[1], ARGV[2])
+return 1
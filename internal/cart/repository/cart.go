@@ -3,53 +3,132 @@ package repository
 import (
 	"context"
 	"mall/internal/cart/domain"
+	"mall/internal/cart/repository/cache"
 	"mall/internal/cart/repository/dao"
 )
 
-type CartRepository struct {
+var (
+	ErrItemNotFound     = dao.ErrItemNotFound
+	ErrQuantityExceeded = cache.ErrQuantityExceeded
+	ErrCartFull         = cache.ErrCartFull
+)
+
+type CartRepository interface {
+	AddToCart(ctx context.Context, item domain.CartItem) error
+	GetCart(ctx context.Context, uid uint64) ([]domain.CartItem, error)
+	EmptyCart(ctx context.Context, uid uint64) error
+	FindItem(ctx context.Context, uid, productId uint64) (domain.CartItem, error)
+	CountItems(ctx context.Context, uid uint64) (int64, error)
+	UpdateQuantity(ctx context.Context, uid, productId uint64, quantity int) error
+	DeleteItems(ctx context.Context, uid uint64, productIds []uint64) (int64, error)
+	UpdateSelected(ctx context.Context, uid uint64, productIds []uint64, selected bool) error
+	UpdateNote(ctx context.Context, uid, productId uint64, note string) error
+	// FindBaskets 按用户分批读取购物车，结果按用户 id 排序
+	FindBaskets(ctx context.Context, afterUid uint64, limit int) ([]domain.CartItem, error)
+	// RemoveProduct 从所有用户的购物车中移除商品，商品被彻底删除时调用
+	RemoveProduct(ctx context.Context, productId uint64) error
+}
+
+// DBCartRepository 只使用 MySQL 的实现
+type DBCartRepository struct {
 	dao *dao.CartDao
 }
 
-func NewCartRepository(dao *dao.CartDao) *CartRepository {
-	return &CartRepository{
+func NewDBCartRepository(dao *dao.CartDao) CartRepository {
+	return &DBCartRepository{
 		dao: dao,
 	}
 }
 
-func (repo *CartRepository) AddToCart(ctx context.Context, item domain.CartItem) error {
-	return repo.dao.InsertItem(ctx, repo.domainToDao(item))
+func (repo *DBCartRepository) AddToCart(ctx context.Context, item domain.CartItem) error {
+	return repo.dao.InsertItem(ctx, domainToDao(item))
 }
 
-func (repo *CartRepository) GetCart(ctx context.Context, uid uint64) ([]domain.CartItem, error) {
-	var items []domain.CartItem
-
+func (repo *DBCartRepository) GetCart(ctx context.Context, uid uint64) ([]domain.CartItem, error) {
 	carts, err := repo.dao.GetCart(ctx, uid)
 	if err != nil {
-		return items, err
+		return nil, err
 	}
+
+	items := make([]domain.CartItem, 0, len(carts))
 	for _, item := range carts {
-		items = append(items, repo.daoToDomain(item))
+		items = append(items, daoToDomain(item))
 	}
 
 	return items, nil
 }
 
-func (repo *CartRepository) EmptyCart(ctx context.Context, uid uint64) error {
+func (repo *DBCartRepository) EmptyCart(ctx context.Context, uid uint64) error {
 	return repo.dao.EmptyCart(ctx, uid)
 }
 
-func (repo *CartRepository) domainToDao(item domain.CartItem) dao.Cart {
-	return dao.Cart{
-		UserID:    item.UserID,
-		ProductID: item.ProductID,
-		Quantity:  item.Quantity,
+func (repo *DBCartRepository) FindItem(ctx context.Context, uid, productId uint64) (domain.CartItem, error) {
+	item, err := repo.dao.FindItem(ctx, uid, productId)
+	if err != nil {
+		return domain.CartItem{}, err
+	}
+
+	return daoToDomain(item), nil
+}
+
+func (repo *DBCartRepository) CountItems(ctx context.Context, uid uint64) (int64, error) {
+	return repo.dao.CountItems(ctx, uid)
+}
+
+func (repo *DBCartRepository) UpdateQuantity(ctx context.Context, uid, productId uint64, quantity int) error {
+	return repo.dao.UpdateQuantity(ctx, uid, productId, quantity)
+}
+
+func (repo *DBCartRepository) DeleteItems(ctx context.Context, uid uint64, productIds []uint64) (int64, error) {
+	return repo.dao.DeleteItems(ctx, uid, productIds)
+}
+
+func (repo *DBCartRepository) UpdateSelected(ctx context.Context, uid uint64, productIds []uint64, selected bool) error {
+	return repo.dao.UpdateSelected(ctx, uid, productIds, selected)
+}
+
+func (repo *DBCartRepository) UpdateNote(ctx context.Context, uid, productId uint64, note string) error {
+	return repo.dao.UpdateNote(ctx, uid, productId, note)
+}
+
+func (repo *DBCartRepository) FindBaskets(ctx context.Context, afterUid uint64, limit int) ([]domain.CartItem, error) {
+	carts, err := repo.dao.FindBaskets(ctx, afterUid, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.CartItem, 0, len(carts))
+	for _, item := range carts {
+		items = append(items, daoToDomain(item))
+	}
+	return items, nil
+}
+
+func (repo *DBCartRepository) RemoveProduct(ctx context.Context, productId uint64) error {
+	return repo.dao.DeleteByProduct(ctx, productId)
+}
+
+func domainToDao(item domain.CartItem) dao.Cart {
+	cart := dao.Cart{
+		UserID:     item.UserID,
+		ProductID:  item.ProductID,
+		Quantity:   item.Quantity,
+		Selected:   item.Selected,
+		AddedPrice: item.AddedPrice,
+		Note:       item.Note,
 	}
+	cart.CreatedAt = item.AddedAt
+	return cart
 }
 
-func (repo *CartRepository) daoToDomain(item dao.Cart) domain.CartItem {
+func daoToDomain(item dao.Cart) domain.CartItem {
 	return domain.CartItem{
-		ProductID: item.ProductID,
-		UserID:    item.UserID,
-		Quantity:  item.Quantity,
+		ProductID:  item.ProductID,
+		UserID:     item.UserID,
+		Quantity:   item.Quantity,
+		Selected:   item.Selected,
+		AddedPrice: item.AddedPrice,
+		AddedAt:    item.CreatedAt,
+		Note:       item.Note,
 	}
 }
@@ -7,6 +7,8 @@ import (
 	"gorm.io/gorm"
 )
 
+var ErrItemNotFound = errors.New("product is not in the cart")
+
 type CartDao struct {
 	db *gorm.DB
 }
@@ -17,28 +19,176 @@ func NewCartDao(db *gorm.DB) *CartDao {
 	}
 }
 
+// InsertItem 加入购物车，已在购物车中时累加数量，并把加购价和备注更新为本次的值
 func (dao *CartDao) InsertItem(ctx context.Context, cart Cart) error {
+	var existing Cart
 	err := dao.db.WithContext(ctx).
 		Model(&Cart{}).
 		Where(&Cart{UserID: cart.UserID, ProductID: cart.ProductID}).
-		First(&cart).Error
+		First(&existing).Error
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return err
 	}
 
-	if cart.ID > 0 {
+	if existing.ID > 0 {
+		columns := map[string]any{
+			"quantity":    gorm.Expr("quantity+?", cart.Quantity),
+			"added_price": cart.AddedPrice,
+		}
+		// 没有带备注时保留原备注
+		if cart.Note != "" {
+			columns["note"] = cart.Note
+		}
 		return dao.db.WithContext(ctx).
 			Model(&Cart{}).
-			Where(&Cart{UserID: cart.UserID, ProductID: cart.ProductID}).
-			UpdateColumn("quantity", gorm.Expr("quantity+?", cart.Quantity)).Error
+			Where("id = ?", existing.ID).
+			UpdateColumns(columns).Error
 	}
 
+	// 新加入的商品默认勾选
+	cart.Selected = true
 	return dao.db.WithContext(ctx).Create(&cart).Error
 }
 
+func (dao *CartDao) FindItem(ctx context.Context, uid, productId uint64) (Cart, error) {
+	var cart Cart
+	err := dao.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", uid, productId).First(&cart).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Cart{}, ErrItemNotFound
+	}
+
+	return cart, err
+}
+
+func (dao *CartDao) CountItems(ctx context.Context, uid uint64) (int64, error) {
+	var count int64
+	err := dao.db.WithContext(ctx).Model(&Cart{}).Where("user_id = ?", uid).Count(&count).Error
+	return count, err
+}
+
+func (dao *CartDao) UpdateQuantity(ctx context.Context, uid, productId uint64, quantity int) error {
+	res := dao.db.WithContext(ctx).Model(&Cart{}).
+		Where("user_id = ? AND product_id = ?", uid, productId).
+		Update("quantity", quantity)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrItemNotFound
+	}
+
+	return nil
+}
+
+func (dao *CartDao) UpdateNote(ctx context.Context, uid, productId uint64, note string) error {
+	res := dao.db.WithContext(ctx).Model(&Cart{}).
+		Where("user_id = ? AND product_id = ?", uid, productId).
+		Update("note", note)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrItemNotFound
+	}
+
+	return nil
+}
+
+// DeleteItems 删除购物车中的商品，返回实际删除的数量
+func (dao *CartDao) DeleteItems(ctx context.Context, uid uint64, productIds []uint64) (int64, error) {
+	res := dao.db.WithContext(ctx).Where("user_id = ? AND product_id IN ?", uid, productIds).Delete(&Cart{})
+	return res.RowsAffected, res.Error
+}
+
+// UpdateSelected 修改勾选状态，productIds 为空时修改全部商品
+func (dao *CartDao) UpdateSelected(ctx context.Context, uid uint64, productIds []uint64, selected bool) error {
+	query := dao.db.WithContext(ctx).Model(&Cart{}).Where("user_id = ?", uid)
+	if len(productIds) > 0 {
+		query = query.Where("product_id IN ?", productIds)
+	}
+
+	return query.Update("selected", selected).Error
+}
+
+// SyncCart 把用户的购物车整体写回数据库，carts 为写回后的全部商品
+func (dao *CartDao) SyncCart(ctx context.Context, uid uint64, carts []Cart) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []Cart
+		if err := tx.Where("user_id = ?", uid).Find(&existing).Error; err != nil {
+			return err
+		}
+
+		rows := make(map[uint64]Cart, len(existing))
+		var stale []uint
+		for _, row := range existing {
+			// 并发加购留下的重复行一并删除
+			if _, ok := rows[row.ProductID]; ok {
+				stale = append(stale, row.ID)
+				continue
+			}
+			rows[row.ProductID] = row
+		}
+
+		for _, cart := range carts {
+			row, ok := rows[cart.ProductID]
+			if !ok {
+				cart.UserID = uid
+				if err := tx.Create(&cart).Error; err != nil {
+					return err
+				}
+				// selected 带默认值，false 不会随 Create 写入
+				if !cart.Selected {
+					if err := tx.Model(&cart).Update("selected", false).Error; err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			delete(rows, cart.ProductID)
+
+			if row.Quantity == cart.Quantity && row.Selected == cart.Selected && row.AddedPrice == cart.AddedPrice &&
+				row.Note == cart.Note {
+				continue
+			}
+			err := tx.Model(&Cart{}).Where("id = ?", row.ID).UpdateColumns(map[string]any{
+				"quantity":    cart.Quantity,
+				"selected":    cart.Selected,
+				"added_price": cart.AddedPrice,
+				"note":        cart.Note,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, row := range rows {
+			stale = append(stale, row.ID)
+		}
+		if len(stale) == 0 {
+			return nil
+		}
+		return tx.Delete(&Cart{}, stale).Error
+	})
+}
+
+// FindUsersByProduct 购物车中有该商品的用户
+func (dao *CartDao) FindUsersByProduct(ctx context.Context, productId uint64) ([]uint64, error) {
+	var uids []uint64
+	err := dao.db.WithContext(ctx).Model(&Cart{}).
+		Distinct("user_id").
+		Where("product_id = ?", productId).
+		Pluck("user_id", &uids).Error
+	return uids, err
+}
+
+// DeleteByProduct 从所有购物车中删除该商品
+func (dao *CartDao) DeleteByProduct(ctx context.Context, productId uint64) error {
+	return dao.db.WithContext(ctx).Delete(&Cart{}, "product_id = ?", productId).Error
+}
+
 func (dao *CartDao) GetCart(ctx context.Context, uid uint64) ([]Cart, error) {
 	var carts []Cart
-	err := dao.db.WithContext(ctx).Model(&Cart{}).Where(&Cart{UserID: uid}).Find(&carts).Error
+	err := dao.db.WithContext(ctx).Model(&Cart{}).Where(&Cart{UserID: uid}).Order("id").Find(&carts).Error
 	return carts, err
 }
 
@@ -49,3 +199,32 @@ func (dao *CartDao) EmptyCart(ctx context.Context, uid uint64) error {
 
 	return dao.db.WithContext(ctx).Delete(&Cart{}, "user_id = ?", uid).Error
 }
+
+// FindBaskets 按用户 id 分批读取购物车，返回 id 大于 afterUid 的 limit 个用户的全部商品
+func (dao *CartDao) FindBaskets(ctx context.Context, afterUid uint64, limit int) ([]Cart, error) {
+	uids, err := dao.FindBasketUsers(ctx, afterUid, limit)
+	if err != nil || len(uids) == 0 {
+		return nil, err
+	}
+
+	return dao.FindByUsers(ctx, uids)
+}
+
+// FindBasketUsers 购物车不为空的用户，返回 id 大于 afterUid 的 limit 个，按 id 排序
+func (dao *CartDao) FindBasketUsers(ctx context.Context, afterUid uint64, limit int) ([]uint64, error) {
+	var uids []uint64
+	err := dao.db.WithContext(ctx).Model(&Cart{}).
+		Distinct("user_id").
+		Where("user_id > ?", afterUid).
+		Order("user_id").
+		Limit(limit).
+		Pluck("user_id", &uids).Error
+	return uids, err
+}
+
+// FindByUsers 读取多个用户的购物车，按用户 id 排序
+func (dao *CartDao) FindByUsers(ctx context.Context, uids []uint64) ([]Cart, error) {
+	var carts []Cart
+	err := dao.db.WithContext(ctx).Where("user_id IN ?", uids).Order("user_id").Find(&carts).Error
+	return carts, err
+}
@@ -5,9 +5,24 @@ import "gorm.io/gorm"
 // Cart 购物车项模型
 type Cart struct {
 	gorm.Model
-	ProductID uint64 `json:"product_id"`
+	ProductID uint64 `json:"product_id" gorm:"index"`
 	Quantity  int    `json:"quantity"`
 	UserID    uint64 `json:"user_id"`
+	Selected  bool   `json:"selected" gorm:"not null;default:true"` // 是否勾选结算
+	// 加入购物车时的单价，和当前价格比较提示降价或涨价
+	AddedPrice float64 `json:"added_price" gorm:"not null;default:0"`
+	Note       string  `json:"note" gorm:"type:varchar(255);not null;default:''"` // 备注，如礼品留言
+}
+
+// SavedItem 稍后购买，从购物车移出但保留数量和备注
+type SavedItem struct {
+	Id         uint64  `gorm:"primaryKey,autoIncrement"`
+	UserId     uint64  `gorm:"not null;uniqueIndex:uk_user_product,priority:1"`
+	ProductId  uint64  `gorm:"not null;uniqueIndex:uk_user_product,priority:2"`
+	Quantity   int     `gorm:"not null"`
+	Note       string  `gorm:"type:varchar(255);not null;default:''"`
+	AddedPrice float64 `gorm:"not null;default:0"` // 加入购物车时的单价
+	CreateAt   int64
 }
 
 //// ShoppingCart 购物车模型
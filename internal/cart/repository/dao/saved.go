@@ -0,0 +1,71 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrSavedNotFound = errors.New("product is not in the saved list")
+
+type SavedDao struct {
+	db *gorm.DB
+}
+
+func NewSavedDao(db *gorm.DB) *SavedDao {
+	return &SavedDao{
+		db: db,
+	}
+}
+
+// UpsertSaved 加入稍后购买，已存在时覆盖数量、备注、加购价和加入时间
+func (dao *SavedDao) UpsertSaved(ctx context.Context, item SavedItem) error {
+	item.CreateAt = time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "note", "added_price", "create_at"}),
+	}).Create(&item).Error
+}
+
+func (dao *SavedDao) FindSaved(ctx context.Context, uid, productId uint64) (SavedItem, error) {
+	var item SavedItem
+	err := dao.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", uid, productId).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return SavedItem{}, ErrSavedNotFound
+	}
+
+	return item, err
+}
+
+// ListSaved 按加入时间倒序
+func (dao *SavedDao) ListSaved(ctx context.Context, uid uint64) ([]SavedItem, error) {
+	var items []SavedItem
+	err := dao.db.WithContext(ctx).Where("user_id = ?", uid).Order("create_at DESC, id DESC").Find(&items).Error
+	return items, err
+}
+
+func (dao *SavedDao) CountSaved(ctx context.Context, uid uint64) (int64, error) {
+	var count int64
+	err := dao.db.WithContext(ctx).Model(&SavedItem{}).Where("user_id = ?", uid).Count(&count).Error
+	return count, err
+}
+
+// DeleteByProduct 商品被彻底删除后从所有用户的稍后购买中移除
+func (dao *SavedDao) DeleteByProduct(ctx context.Context, productId uint64) error {
+	return dao.db.WithContext(ctx).Where("product_id = ?", productId).Delete(&SavedItem{}).Error
+}
+
+func (dao *SavedDao) DeleteSaved(ctx context.Context, uid, productId uint64) error {
+	res := dao.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", uid, productId).Delete(&SavedItem{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrSavedNotFound
+	}
+
+	return nil
+}
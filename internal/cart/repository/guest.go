@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"mall/internal/cart/domain"
+	"mall/internal/cart/repository/cache"
+)
+
+// GuestCartRepository 游客购物车，只存放在 Redis 中并带有过期时间，uid 参数为游客 id
+type GuestCartRepository struct {
+	cache *cache.GuestCartCache
+}
+
+func NewGuestCartRepository(cache *cache.GuestCartCache) *GuestCartRepository {
+	return &GuestCartRepository{
+		cache: cache,
+	}
+}
+
+func (repo *GuestCartRepository) AddToCart(ctx context.Context, item domain.CartItem) error {
+	return repo.retry(ctx, item.UserID, func() error {
+		_, err := repo.cache.Add(ctx, item)
+		return err
+	})
+}
+
+// GetCart 不存在或已过期的游客购物车视为空购物车
+func (repo *GuestCartRepository) GetCart(ctx context.Context, uid uint64) ([]domain.CartItem, error) {
+	items, err := repo.cache.Get(ctx, uid)
+	if errors.Is(err, cache.ErrCartNotLoaded) {
+		return []domain.CartItem{}, nil
+	}
+
+	return items, err
+}
+
+// SaveMergeResult 保存登录时的合并结果，uid 为用户 id
+func (repo *GuestCartRepository) SaveMergeResult(ctx context.Context, uid uint64, res domain.MergeResult) error {
+	return repo.cache.SetMergeResult(ctx, uid, res)
+}
+
+// FindMergeResult 已过期或没有合并过时返回 nil
+func (repo *GuestCartRepository) FindMergeResult(ctx context.Context, uid uint64) (*domain.MergeResult, error) {
+	return repo.cache.GetMergeResult(ctx, uid)
+}
+
+func (repo *GuestCartRepository) EmptyCart(ctx context.Context, uid uint64) error {
+	return repo.cache.Empty(ctx, uid)
+}
+
+func (repo *GuestCartRepository) FindItem(ctx context.Context, uid, productId uint64) (domain.CartItem, error) {
+	items, err := repo.GetCart(ctx, uid)
+	if err != nil {
+		return domain.CartItem{}, err
+	}
+
+	for _, item := range items {
+		if item.ProductID == productId {
+			return item, nil
+		}
+	}
+	return domain.CartItem{}, ErrItemNotFound
+}
+
+func (repo *GuestCartRepository) CountItems(ctx context.Context, uid uint64) (int64, error) {
+	items, err := repo.GetCart(ctx, uid)
+	return int64(len(items)), err
+}
+
+func (repo *GuestCartRepository) UpdateQuantity(ctx context.Context, uid, productId uint64, quantity int) error {
+	err := repo.cache.SetQuantity(ctx, uid, productId, quantity)
+	if errors.Is(err, cache.ErrCartNotLoaded) {
+		return ErrItemNotFound
+	}
+
+	return err
+}
+
+func (repo *GuestCartRepository) DeleteItems(ctx context.Context, uid uint64, productIds []uint64) (int64, error) {
+	n, err := repo.cache.Delete(ctx, uid, productIds)
+	if errors.Is(err, cache.ErrCartNotLoaded) {
+		return 0, nil
+	}
+
+	return n, err
+}
+
+func (repo *GuestCartRepository) UpdateSelected(ctx context.Context, uid uint64, productIds []uint64, selected bool) error {
+	err := repo.cache.SetSelected(ctx, uid, productIds, selected)
+	if errors.Is(err, cache.ErrCartNotLoaded) {
+		return nil
+	}
+
+	return err
+}
+
+func (repo *GuestCartRepository) UpdateNote(ctx context.Context, uid, productId uint64, note string) error {
+	err := repo.cache.SetNote(ctx, uid, productId, note)
+	if errors.Is(err, cache.ErrCartNotLoaded) {
+		return ErrItemNotFound
+	}
+
+	return err
+}
+
+// FindBaskets 游客购物车不参与离线统计
+func (repo *GuestCartRepository) FindBaskets(ctx context.Context, afterUid uint64, limit int) ([]domain.CartItem, error) {
+	return nil, nil
+}
+
+// RemoveProduct 游客购物车很快过期，展示时会标记已删除的商品，不逐个清理
+func (repo *GuestCartRepository) RemoveProduct(ctx context.Context, productId uint64) error {
+	return nil
+}
+
+// retry 游客购物车不存在时先创建空购物车再执行一次
+func (repo *GuestCartRepository) retry(ctx context.Context, uid uint64, fn func() error) error {
+	err := fn()
+	if !errors.Is(err, cache.ErrCartNotLoaded) {
+		return err
+	}
+
+	if err := repo.cache.Load(ctx, uid, nil); err != nil {
+		return err
+	}
+	return fn()
+}
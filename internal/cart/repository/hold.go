@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"mall/internal/cart/domain"
+	"mall/internal/cart/repository/cache"
+)
+
+type HoldRepository struct {
+	cache *cache.HoldCache
+}
+
+func NewHoldRepository(cache *cache.HoldCache) *HoldRepository {
+	return &HoldRepository{
+		cache: cache,
+	}
+}
+
+// Reserve 预占库存，返回库存不足的商品，为空时表示全部预占成功
+func (repo *HoldRepository) Reserve(ctx context.Context, uid uint64, lines []domain.HoldLine, ttl time.Duration) ([]domain.HoldLine, error) {
+	return repo.cache.Reserve(ctx, uid, lines, ttl)
+}
+
+func (repo *HoldRepository) Release(ctx context.Context, uid uint64, productIds []uint64) (int64, error) {
+	return repo.cache.Release(ctx, uid, productIds)
+}
+
+// ReleaseAll 释放用户的全部预占
+func (repo *HoldRepository) ReleaseAll(ctx context.Context, uid uint64) (int64, error) {
+	holds, _, err := repo.cache.UserHolds(ctx, uid)
+	if err != nil || len(holds) == 0 {
+		return 0, err
+	}
+
+	ids := make([]uint64, 0, len(holds))
+	for id := range holds {
+		ids = append(ids, id)
+	}
+	return repo.cache.Release(ctx, uid, ids)
+}
+
+func (repo *HoldRepository) Reserved(ctx context.Context, productIds []uint64) ([]int, error) {
+	return repo.cache.Reserved(ctx, productIds)
+}
+
+func (repo *HoldRepository) UserHolds(ctx context.Context, uid uint64) (map[uint64]int, time.Time, error) {
+	return repo.cache.UserHolds(ctx, uid)
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"mall/internal/cart/domain"
+	"mall/internal/cart/repository/cache"
+	"mall/internal/cart/repository/dao"
+)
+
+// ErrCartLost 有改动待写回的购物车已不在缓存中
+var ErrCartLost = errors.New("cart with unflushed changes is missing from cache")
+
+// RedisCartRepository 购物车以 Redis 为准，改动记录到待写回集合，由 Flush 异步写回 MySQL；
+// 缓存中没有的购物车先从 MySQL 回填
+type RedisCartRepository struct {
+	dao   *dao.CartDao
+	cache *cache.CartCache
+}
+
+func NewRedisCartRepository(dao *dao.CartDao, cache *cache.CartCache) *RedisCartRepository {
+	return &RedisCartRepository{
+		dao:   dao,
+		cache: cache,
+	}
+}
+
+func (repo *RedisCartRepository) AddToCart(ctx context.Context, item domain.CartItem) error {
+	return repo.retry(ctx, item.UserID, func() error {
+		_, err := repo.cache.Add(ctx, item)
+		return err
+	})
+}
+
+func (repo *RedisCartRepository) GetCart(ctx context.Context, uid uint64) ([]domain.CartItem, error) {
+	items, err := repo.cache.Get(ctx, uid)
+	if !errors.Is(err, cache.ErrCartNotLoaded) {
+		return items, err
+	}
+
+	if err := repo.load(ctx, uid); err != nil {
+		return nil, err
+	}
+	return repo.cache.Get(ctx, uid)
+}
+
+func (repo *RedisCartRepository) EmptyCart(ctx context.Context, uid uint64) error {
+	if uid == 0 {
+		return errors.New("user id is required")
+	}
+
+	return repo.cache.Empty(ctx, uid)
+}
+
+func (repo *RedisCartRepository) FindItem(ctx context.Context, uid, productId uint64) (domain.CartItem, error) {
+	items, err := repo.GetCart(ctx, uid)
+	if err != nil {
+		return domain.CartItem{}, err
+	}
+
+	for _, item := range items {
+		if item.ProductID == productId {
+			return item, nil
+		}
+	}
+	return domain.CartItem{}, ErrItemNotFound
+}
+
+func (repo *RedisCartRepository) CountItems(ctx context.Context, uid uint64) (int64, error) {
+	items, err := repo.GetCart(ctx, uid)
+	return int64(len(items)), err
+}
+
+func (repo *RedisCartRepository) UpdateQuantity(ctx context.Context, uid, productId uint64, quantity int) error {
+	return repo.retry(ctx, uid, func() error {
+		return repo.cache.SetQuantity(ctx, uid, productId, quantity)
+	})
+}
+
+func (repo *RedisCartRepository) DeleteItems(ctx context.Context, uid uint64, productIds []uint64) (int64, error) {
+	var n int64
+	err := repo.retry(ctx, uid, func() error {
+		var err error
+		n, err = repo.cache.Delete(ctx, uid, productIds)
+		return err
+	})
+	return n, err
+}
+
+func (repo *RedisCartRepository) UpdateSelected(ctx context.Context, uid uint64, productIds []uint64, selected bool) error {
+	return repo.retry(ctx, uid, func() error {
+		return repo.cache.SetSelected(ctx, uid, productIds, selected)
+	})
+}
+
+func (repo *RedisCartRepository) UpdateNote(ctx context.Context, uid, productId uint64, note string) error {
+	return repo.retry(ctx, uid, func() error {
+		return repo.cache.SetNote(ctx, uid, productId, note)
+	})
+}
+
+// FindBaskets 以 MySQL 中的用户分页，再加上同一区间内尚未写回的用户；
+// 购物车在缓存中的以缓存为准，这样离线任务也能看到最近的改动
+func (repo *RedisCartRepository) FindBaskets(ctx context.Context, afterUid uint64, limit int) ([]domain.CartItem, error) {
+	dirty, err := repo.cache.DirtyUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		uids, err := repo.dao.FindBasketUsers(ctx, afterUid, limit)
+		if err != nil {
+			return nil, err
+		}
+		// 不是最后一页时只取到本页最后一个用户，之后的留给下一页
+		upper := uint64(math.MaxUint64)
+		if len(uids) == limit {
+			upper = uids[len(uids)-1]
+		}
+		for _, uid := range dirty {
+			if uid > afterUid && uid <= upper && !slices.Contains(uids, uid) {
+				uids = append(uids, uid)
+			}
+		}
+		if len(uids) == 0 {
+			return nil, nil
+		}
+		slices.Sort(uids)
+
+		items, err := repo.findBaskets(ctx, uids)
+		// 这一页的购物车都已在缓存中清空时继续读下一页，空结果只表示读完了
+		if err != nil || len(items) > 0 || upper == math.MaxUint64 {
+			return items, err
+		}
+		afterUid = upper
+	}
+}
+
+func (repo *RedisCartRepository) findBaskets(ctx context.Context, uids []uint64) ([]domain.CartItem, error) {
+	var (
+		items    []domain.CartItem
+		uncached []uint64
+	)
+	cached := make(map[uint64][]domain.CartItem, len(uids))
+	for _, uid := range uids {
+		basket, err := repo.cache.Get(ctx, uid)
+		switch {
+		case errors.Is(err, cache.ErrCartNotLoaded):
+			uncached = append(uncached, uid)
+		case err != nil:
+			return nil, err
+		default:
+			cached[uid] = basket
+		}
+	}
+
+	fromDB := make(map[uint64][]domain.CartItem, len(uncached))
+	if len(uncached) > 0 {
+		carts, err := repo.dao.FindByUsers(ctx, uncached)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range carts {
+			fromDB[item.UserID] = append(fromDB[item.UserID], daoToDomain(item))
+		}
+	}
+
+	for _, uid := range uids {
+		if basket, ok := cached[uid]; ok {
+			items = append(items, basket...)
+			continue
+		}
+		items = append(items, fromDB[uid]...)
+	}
+	return items, nil
+}
+
+// RemoveProduct 按 MySQL 找出有该商品的用户，逐个从 Redis 中删除，再随写回同步到 MySQL。
+// 商品在回收站中保留期间不能加购，到彻底删除时所有改动都已写回
+func (repo *RedisCartRepository) RemoveProduct(ctx context.Context, productId uint64) error {
+	uids, err := repo.dao.FindUsersByProduct(ctx, productId)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, uid := range uids {
+		if _, err := repo.DeleteItems(ctx, uid, []uint64{productId}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flush 把最多 batch 个用户的改动写回 MySQL，返回写回的用户数；
+// 写回失败的用户放回待写回集合，下次重试
+func (repo *RedisCartRepository) Flush(ctx context.Context, batch int64) (int, error) {
+	uids, err := repo.cache.PopDirty(ctx, batch)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		failed []uint64
+		errs   []error
+	)
+	for _, uid := range uids {
+		err := repo.flush(ctx, uid)
+		switch {
+		case errors.Is(err, ErrCartLost):
+			// 缓存中已经没有数据，重试也写不回
+			errs = append(errs, err)
+		case err != nil:
+			failed = append(failed, uid)
+			errs = append(errs, err)
+		}
+	}
+	if err := repo.cache.MarkDirty(context.Background(), failed...); err != nil {
+		errs = append(errs, err)
+	}
+
+	return len(uids) - len(failed), errors.Join(errs...)
+}
+
+func (repo *RedisCartRepository) flush(ctx context.Context, uid uint64) error {
+	items, err := repo.cache.Get(ctx, uid)
+	// 待写回的购物车不会过期，仍然不在缓存中说明被 Redis 按内存淘汰或手动删除，改动已经丢失
+	if errors.Is(err, cache.ErrCartNotLoaded) {
+		return fmt.Errorf("%w: user %d", ErrCartLost, uid)
+	}
+	if err != nil {
+		return err
+	}
+
+	carts := make([]dao.Cart, 0, len(items))
+	for _, item := range items {
+		carts = append(carts, domainToDao(item))
+	}
+	if err := repo.dao.SyncCart(ctx, uid, carts); err != nil {
+		return err
+	}
+	return repo.cache.Settle(ctx, uid)
+}
+
+// load 从 MySQL 回填购物车
+func (repo *RedisCartRepository) load(ctx context.Context, uid uint64) error {
+	carts, err := repo.dao.GetCart(ctx, uid)
+	if err != nil {
+		return err
+	}
+
+	items := make([]domain.CartItem, 0, len(carts))
+	for _, item := range carts {
+		items = append(items, daoToDomain(item))
+	}
+	return repo.cache.Load(ctx, uid, items)
+}
+
+// retry 执行修改，购物车不在缓存中时回填后再执行一次
+func (repo *RedisCartRepository) retry(ctx context.Context, uid uint64, fn func() error) error {
+	err := fn()
+	if !errors.Is(err, cache.ErrCartNotLoaded) {
+		return err
+	}
+
+	if err := repo.load(ctx, uid); err != nil {
+		return err
+	}
+	return fn()
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+
+	"mall/internal/cart/domain"
+	"mall/internal/cart/repository/dao"
+)
+
+var ErrSavedNotFound = dao.ErrSavedNotFound
+
+type SavedRepository struct {
+	dao *dao.SavedDao
+}
+
+func NewSavedRepository(dao *dao.SavedDao) *SavedRepository {
+	return &SavedRepository{
+		dao: dao,
+	}
+}
+
+func (repo *SavedRepository) SaveItem(ctx context.Context, item domain.SavedItem) error {
+	return repo.dao.UpsertSaved(ctx, dao.SavedItem{
+		UserId:     item.UserID,
+		ProductId:  item.ProductID,
+		Quantity:   item.Quantity,
+		Note:       item.Note,
+		AddedPrice: item.AddedPrice,
+	})
+}
+
+func (repo *SavedRepository) FindItem(ctx context.Context, uid, productId uint64) (domain.SavedItem, error) {
+	item, err := repo.dao.FindSaved(ctx, uid, productId)
+	if err != nil {
+		return domain.SavedItem{}, err
+	}
+
+	return repo.toDomain(item), nil
+}
+
+func (repo *SavedRepository) FindItems(ctx context.Context, uid uint64) ([]domain.SavedItem, error) {
+	items, err := repo.dao.ListSaved(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.SavedItem, 0, len(items))
+	for _, item := range items {
+		res = append(res, repo.toDomain(item))
+	}
+	return res, nil
+}
+
+func (repo *SavedRepository) CountItems(ctx context.Context, uid uint64) (int64, error) {
+	return repo.dao.CountSaved(ctx, uid)
+}
+
+func (repo *SavedRepository) DeleteItem(ctx context.Context, uid, productId uint64) error {
+	return repo.dao.DeleteSaved(ctx, uid, productId)
+}
+
+func (repo *SavedRepository) RemoveProduct(ctx context.Context, productId uint64) error {
+	return repo.dao.DeleteByProduct(ctx, productId)
+}
+
+func (repo *SavedRepository) toDomain(item dao.SavedItem) domain.SavedItem {
+	return domain.SavedItem{
+		ProductID:  item.ProductId,
+		UserID:     item.UserId,
+		Quantity:   item.Quantity,
+		Note:       item.Note,
+		AddedPrice: item.AddedPrice,
+		SavedAt:    item.CreateAt,
+	}
+}
@@ -2,61 +2,268 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
 	"mall/internal/cart/domain"
 	"mall/internal/cart/repository"
+	pdomain "mall/internal/product/domain"
 	repository2 "mall/internal/product/repository"
-	"net/http"
+	pservice "mall/internal/product/service"
+)
+
+var (
+	ErrProductNotFound  = repository2.ErrProductNotFound
+	ErrProductNotOnList = repository2.ErrProductNotOnList
+	ErrItemNotFound     = repository.ErrItemNotFound
+	ErrInvalidQuantity  = errors.New("quantity must be positive")
+	ErrQuantityExceeded = repository.ErrQuantityExceeded
+	ErrCartFull         = repository.ErrCartFull
+	ErrNoteTooLong      = fmt.Errorf("note must not exceed %d characters", maxNoteLength)
+)
+
+const (
+	maxCartItems    = domain.MaxCartItems
+	maxItemQuantity = domain.MaxItemQuantity
+	// 备注的字符数上限
+	maxNoteLength = 200
 )
 
 type CartService struct {
-	cartRepo    *repository.CartRepository
+	cartRepo repository.CartRepository
+	// 游客购物车不能结算，没有预占，为 nil
+	holdRepo    *repository.HoldRepository
 	productRepo *repository2.ProductRepository
+	events      *pservice.EventPublisher
 }
 
-type CustomErr struct {
-	Code int
-	Msg  string
-}
-
-func NewCartService(cartRepo *repository.CartRepository, productRepo *repository2.ProductRepository) *CartService {
+func NewCartService(cartRepo repository.CartRepository, holdRepo *repository.HoldRepository, productRepo *repository2.ProductRepository,
+	events *pservice.EventPublisher) *CartService {
 	return &CartService{
 		cartRepo:    cartRepo,
+		holdRepo:    holdRepo,
 		productRepo: productRepo,
+		events:      events,
 	}
 }
 
-func (svc *CartService) AddCart(ctx context.Context, item domain.CartItem) *CustomErr {
+// AddCart 把商品加入购物车，已在购物车中的商品累加数量
+func (svc *CartService) AddCart(ctx context.Context, item domain.CartItem) error {
+	if item.Quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	item.Note = strings.TrimSpace(item.Note)
+	if utf8.RuneCountInString(item.Note) > maxNoteLength {
+		return ErrNoteTooLong
+	}
 	product, err := svc.productRepo.FindProductById(ctx, item.ProductID)
 	if err != nil {
-		return &CustomErr{Code: http.StatusInternalServerError, Msg: err.Error()}
+		return err
+	}
+
+	existing, err := svc.cartRepo.FindItem(ctx, item.UserID, item.ProductID)
+	switch {
+	case errors.Is(err, ErrItemNotFound):
+		count, err := svc.cartRepo.CountItems(ctx, item.UserID)
+		if err != nil {
+			return err
+		}
+		if count >= maxCartItems {
+			return ErrCartFull
+		}
+	case err != nil:
+		return err
+	}
+	if existing.Quantity+item.Quantity > maxItemQuantity {
+		return ErrQuantityExceeded
 	}
-	if product.Product.Id == 0 {
-		return &CustomErr{Code: http.StatusNotFound, Msg: "商品不存在"}
+	item.AddedPrice = product.Product.Price
+
+	// 上面的检查只为尽早给出错误，并发加购时由仓储保证不超过上限
+	if err := svc.cartRepo.AddToCart(ctx, item); err != nil {
+		return err
 	}
+	svc.events.Publish(ctx, pdomain.ProductEvent{
+		Type:       pdomain.EventAddToCart,
+		ProductId:  item.ProductID,
+		CategoryId: product.Category.ID,
+		Count:      item.Quantity,
+	})
+
+	return nil
+}
 
-	err = svc.cartRepo.AddToCart(ctx, item)
+// SetQuantity 修改购物车中商品的数量，数量为 0 时移出购物车
+func (svc *CartService) SetQuantity(ctx context.Context, uid uint64, productId string, quantity int) error {
+	id, err := strconv.ParseUint(productId, 10, 64)
 	if err != nil {
-		return &CustomErr{Code: http.StatusInternalServerError, Msg: fmt.Sprintf("用户:%d:添加商品到购物车失败", item.UserID)}
+		return err
+	}
+	switch {
+	case quantity < 0:
+		return ErrInvalidQuantity
+	case quantity > maxItemQuantity:
+		return ErrQuantityExceeded
+	case quantity == 0:
+		return svc.RemoveItem(ctx, uid, productId)
+	}
+
+	return svc.cartRepo.UpdateQuantity(ctx, uid, id, quantity)
+}
+
+// SetNote 修改购物车中商品的备注，note 为空时清除备注
+func (svc *CartService) SetNote(ctx context.Context, uid uint64, productId string, note string) error {
+	id, err := strconv.ParseUint(productId, 10, 64)
+	if err != nil {
+		return err
+	}
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > maxNoteLength {
+		return ErrNoteTooLong
 	}
 
+	return svc.cartRepo.UpdateNote(ctx, uid, id, note)
+}
+
+func (svc *CartService) RemoveItem(ctx context.Context, uid uint64, productId string) error {
+	id, err := strconv.ParseUint(productId, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	n, err := svc.cartRepo.DeleteItems(ctx, uid, []uint64{id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrItemNotFound
+	}
+	svc.releaseHolds(ctx, uid, []uint64{id})
 	return nil
 }
 
-func (svc *CartService) EmptyCart(ctx context.Context, uid uint64) *CustomErr {
-	err := svc.cartRepo.EmptyCart(ctx, uid)
+// RemoveItems 批量移出购物车，不在购物车中的商品忽略，返回实际移出的数量
+func (svc *CartService) RemoveItems(ctx context.Context, uid uint64, productIds []uint64) (int64, error) {
+	if len(productIds) == 0 {
+		return 0, nil
+	}
+	if len(productIds) > maxCartItems {
+		productIds = productIds[:maxCartItems]
+	}
+
+	n, err := svc.cartRepo.DeleteItems(ctx, uid, productIds)
 	if err != nil {
-		return &CustomErr{Code: http.StatusInternalServerError, Msg: fmt.Sprintf("用户:%d:清空购物车失败", uid)}
+		return 0, err
+	}
+	svc.releaseHolds(ctx, uid, productIds)
+	return n, nil
+}
+
+// SelectItems 勾选或取消勾选，productIds 为空时作用于全部商品
+func (svc *CartService) SelectItems(ctx context.Context, uid uint64, productIds []uint64, selected bool) error {
+	if len(productIds) > maxCartItems {
+		productIds = productIds[:maxCartItems]
 	}
 
+	return svc.cartRepo.UpdateSelected(ctx, uid, productIds, selected)
+}
+
+func (svc *CartService) EmptyCart(ctx context.Context, uid uint64) error {
+	if err := svc.cartRepo.EmptyCart(ctx, uid); err != nil {
+		return err
+	}
+	if svc.holdRepo != nil {
+		_, _ = svc.holdRepo.ReleaseAll(ctx, uid)
+	}
 	return nil
 }
 
-func (svc *CartService) GetCart(ctx context.Context, uid uint64) ([]domain.CartItem, *CustomErr) {
+// releaseHolds 商品移出购物车后释放结算时的预占，释放失败时等预占自动过期
+func (svc *CartService) releaseHolds(ctx context.Context, uid uint64, productIds []uint64) {
+	if svc.holdRepo == nil {
+		return
+	}
+	_, _ = svc.holdRepo.Release(ctx, uid, productIds)
+}
+
+// GetCart 购物车视图，批量读取商品的实时名称、主图、价格和库存
+func (svc *CartService) GetCart(ctx context.Context, uid uint64) (domain.CartView, error) {
 	items, err := svc.cartRepo.GetCart(ctx, uid)
 	if err != nil {
-		return items, &CustomErr{Code: http.StatusInternalServerError, Msg: fmt.Sprintf("用户:%d:获取购物车失败", uid)}
+		return domain.CartView{}, err
+	}
+
+	ids := make([]uint64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
 	}
+	summaries, err := svc.productRepo.FindProductSummaries(ctx, ids)
+	if err != nil {
+		return domain.CartView{}, err
+	}
+	summaryOf := make(map[uint64]pdomain.ProductSummary, len(summaries))
+	for _, s := range summaries {
+		summaryOf[s.Id] = s
+	}
+
+	view := domain.CartView{
+		Lines: make([]domain.CartLine, 0, len(items)),
+	}
+	for _, item := range items {
+		line := buildLine(item, summaryOf[item.ProductID])
+		if line.Selected && line.Status == domain.LineAvailable {
+			view.SelectedQuantity += line.Quantity
+			view.Subtotal += line.Subtotal
+		}
+		view.Lines = append(view.Lines, line)
+	}
+	view.Subtotal = roundPrice(view.Subtotal)
+
+	return view, nil
+}
+
+// buildLine 商品不存在（已被彻底删除）时 s 为零值，按已删除处理
+func buildLine(item domain.CartItem, s pdomain.ProductSummary) domain.CartLine {
+	line := domain.CartLine{
+		ProductId:  item.ProductID,
+		Name:       s.Name,
+		ImageUrl:   s.ImageUrl,
+		Price:      s.Price,
+		AddedPrice: item.AddedPrice,
+		Quantity:   item.Quantity,
+		Stock:      s.Stock,
+		Selected:   item.Selected,
+		Note:       item.Note,
+	}
+
+	switch {
+	case s.Id == 0 || s.IsDeleted:
+		line.Status = domain.LineDeleted
+	case !s.IsActive:
+		line.Status = domain.LineOffList
+	case s.Stock <= 0:
+		line.Status = domain.LineOutOfStock
+	case s.Stock < item.Quantity:
+		line.Status = domain.LineInsufficient
+	default:
+		line.Status = domain.LineAvailable
+	}
+
+	// 旧数据没有加购价，不提示价格变化
+	if line.Status != domain.LineDeleted && item.AddedPrice > 0 {
+		line.PriceDiff = roundPrice(s.Price - item.AddedPrice)
+		line.PriceChanged = line.PriceDiff != 0
+	}
+	line.Subtotal = roundPrice(s.Price * float64(item.Quantity))
+
+	return line
+}
 
-	return items, nil
+// roundPrice 保留两位小数，避免浮点误差
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
 }
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+
+	"mall/internal/cart/domain"
+	"mall/internal/cart/repository"
+	pdomain "mall/internal/product/domain"
+	repository2 "mall/internal/product/repository"
+	pservice "mall/internal/product/service"
+)
+
+var ErrInvalidDevice = errors.New("device id is invalid")
+
+// GuestCartService 未登录用户的购物车，规则和登录用户相同，商品存放在带过期时间的 Redis 中，
+// 登录后合并到用户购物车
+type GuestCartService struct {
+	*CartService
+	guestRepo   *repository.GuestCartRepository
+	userRepo    repository.CartRepository
+	productRepo *repository2.ProductRepository
+	devices     *pservice.DeviceIds
+}
+
+func NewGuestCartService(guestRepo *repository.GuestCartRepository, userRepo repository.CartRepository,
+	productRepo *repository2.ProductRepository, events *pservice.EventPublisher, devices *pservice.DeviceIds) *GuestCartService {
+	return &GuestCartService{
+		CartService: NewCartService(guestRepo, nil, productRepo, events),
+		guestRepo:   guestRepo,
+		userRepo:    userRepo,
+		productRepo: productRepo,
+		devices:     devices,
+	}
+}
+
+// Identify 校验设备 id，返回对应的游客 id
+func (svc *GuestCartService) Identify(deviceId string) (uint64, error) {
+	id, ok := svc.devices.Verify(deviceId)
+	if !ok {
+		return 0, ErrInvalidDevice
+	}
+
+	return guestId(id), nil
+}
+
+// Merge 把游客购物车合并到用户购物车，每个商品先从游客购物车中取出再合并，
+// 同一设备并发登录时每个商品只合并一次，合并失败的商品放回游客购物车，下次登录时再合并。
+// 同一商品数量相加，超过库存或单个商品上限时截断；已删除、已下架、无货的商品和超出种数上限的商品不合并
+func (svc *GuestCartService) Merge(ctx context.Context, guestId, uid uint64) (domain.MergeResult, error) {
+	res := domain.MergeResult{Lines: []domain.MergeLine{}}
+	guestItems, err := svc.guestRepo.GetCart(ctx, guestId)
+	if err != nil || len(guestItems) == 0 {
+		return res, err
+	}
+
+	userItems, err := svc.userRepo.GetCart(ctx, uid)
+	if err != nil {
+		return res, err
+	}
+	quantityOf := make(map[uint64]int, len(userItems))
+	for _, item := range userItems {
+		quantityOf[item.ProductID] = item.Quantity
+	}
+
+	ids := make([]uint64, 0, len(guestItems))
+	for _, item := range guestItems {
+		ids = append(ids, item.ProductID)
+	}
+	summaries, err := svc.productRepo.FindProductSummaries(ctx, ids)
+	if err != nil {
+		return res, err
+	}
+	summaryOf := make(map[uint64]pdomain.ProductSummary, len(summaries))
+	for _, s := range summaries {
+		summaryOf[s.Id] = s
+	}
+
+	count := len(userItems)
+	for _, item := range guestItems {
+		// 已被并发的登录取走
+		n, err := svc.guestRepo.DeleteItems(ctx, guestId, []uint64{item.ProductID})
+		if err != nil {
+			return res, err
+		}
+		if n == 0 {
+			continue
+		}
+
+		previous, exists := quantityOf[item.ProductID]
+		line := domain.MergeLine{
+			ProductId:        item.ProductID,
+			GuestQuantity:    item.Quantity,
+			PreviousQuantity: previous,
+			Quantity:         previous,
+		}
+
+		s := summaryOf[item.ProductID]
+		switch {
+		case s.Id == 0 || s.IsDeleted:
+			line.Reason = domain.LineDeleted
+		case !s.IsActive:
+			line.Reason = domain.LineOffList
+		case s.Stock <= 0:
+			line.Reason = domain.LineOutOfStock
+		case !exists && count >= maxCartItems:
+			line.Reason = domain.MergeReasonCartFull
+		}
+		if line.Reason != "" {
+			line.Status = domain.MergeSkipped
+			res.Skipped++
+			res.Lines = append(res.Lines, line)
+			continue
+		}
+
+		want := previous + item.Quantity
+		// 用户原有的数量不因合并而减少
+		line.Quantity = max(min(want, s.Stock, maxItemQuantity), previous)
+		line.Status = domain.MergeMerged
+		if line.Quantity < want {
+			line.Status = domain.MergeCapped
+		}
+
+		switch {
+		case exists && line.Quantity > previous:
+			err = svc.userRepo.UpdateQuantity(ctx, uid, item.ProductID, line.Quantity)
+		case !exists:
+			merged := item
+			if merged.AddedPrice <= 0 {
+				merged.AddedPrice = s.Price
+			}
+			merged.UserID = uid
+			merged.Quantity = line.Quantity
+			err = svc.userRepo.AddToCart(ctx, merged)
+			count++
+		}
+		if err != nil {
+			if e := svc.guestRepo.AddToCart(context.WithoutCancel(ctx), item); e != nil {
+				err = errors.Join(err, e)
+			}
+			return res, err
+		}
+		res.Merged++
+		res.Lines = append(res.Lines, line)
+	}
+
+	return res, nil
+}
+
+// GetMergeResult 最近一次登录时游客购物车的合并结果，没有时返回 nil
+func (svc *GuestCartService) GetMergeResult(ctx context.Context, uid uint64) (*domain.MergeResult, error) {
+	return svc.guestRepo.FindMergeResult(ctx, uid)
+}
+
+func (svc *GuestCartService) Name() string {
+	return "cart"
+}
+
+// AfterLogin 登录请求携带了有效的设备 id 时合并游客购物车，返回合并结果放进登录响应；
+// 合并结果同时保存一段时间，登录响应丢失时客户端可以再查询
+func (svc *GuestCartService) AfterLogin(ctx context.Context, uid uint64, deviceId string) (any, error) {
+	// 伪造的设备 id 没有可合并的购物车
+	gid, err := svc.Identify(deviceId)
+	if err != nil {
+		return nil, nil
+	}
+
+	res, err := svc.Merge(ctx, gid, uid)
+	if len(res.Lines) == 0 {
+		return nil, err
+	}
+	// 部分合并成功时也告诉客户端
+	err = errors.Join(err, svc.guestRepo.SaveMergeResult(ctx, uid, res))
+	return res, err
+}
+
+// guestId 由设备 id 得到游客 id，保留 63 位并避开 0
+func guestId(deviceId string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(deviceId))
+	return h.Sum64()>>1 | 1
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"mall/internal/cart/domain"
+	"mall/internal/cart/repository"
+	pdomain "mall/internal/product/domain"
+	repository2 "mall/internal/product/repository"
+)
+
+var (
+	ErrNothingSelected   = errors.New("no products are selected")
+	ErrInsufficientStock = errors.New("some products do not have enough stock")
+	ErrProductNotOwned   = errors.New("product does not belong to you")
+)
+
+// 结算时预占库存的时长，超时未下单自动释放
+const holdTTL = time.Minute * 15
+
+// HoldService 结算时对购物车中勾选的商品做软预占：预占只在 Redis 中扣减可用库存，不改动数据库中的库存，
+// 超时、移出购物车或取消结算时释放
+type HoldService struct {
+	cartRepo    repository.CartRepository
+	holdRepo    *repository.HoldRepository
+	productRepo *repository2.ProductRepository
+}
+
+func NewHoldService(cartRepo repository.CartRepository, holdRepo *repository.HoldRepository,
+	productRepo *repository2.ProductRepository) *HoldService {
+	return &HoldService{
+		cartRepo:    cartRepo,
+		holdRepo:    holdRepo,
+		productRepo: productRepo,
+	}
+}
+
+// Checkout 为勾选的商品预占库存，重复结算时刷新预占；
+// 任一商品不可购买或可用库存不足时不预占，返回 ErrInsufficientStock 和不足的商品
+func (svc *HoldService) Checkout(ctx context.Context, uid uint64) (domain.Reservation, error) {
+	items, err := svc.cartRepo.GetCart(ctx, uid)
+	if err != nil {
+		return domain.Reservation{}, err
+	}
+
+	ids := make([]uint64, 0, len(items))
+	for _, item := range items {
+		if item.Selected {
+			ids = append(ids, item.ProductID)
+		}
+	}
+	if len(ids) == 0 {
+		return domain.Reservation{}, ErrNothingSelected
+	}
+	summaries, err := svc.productRepo.FindProductSummaries(ctx, ids)
+	if err != nil {
+		return domain.Reservation{}, err
+	}
+	summaryOf := make(map[uint64]pdomain.ProductSummary, len(summaries))
+	for _, s := range summaries {
+		summaryOf[s.Id] = s
+	}
+
+	res := domain.Reservation{
+		Lines:     make([]domain.HoldLine, 0, len(ids)),
+		Shortages: []domain.HoldLine{},
+	}
+	for _, item := range items {
+		if !item.Selected {
+			continue
+		}
+		s := summaryOf[item.ProductID]
+		line := domain.HoldLine{
+			ProductId: item.ProductID,
+			Quantity:  item.Quantity,
+			Stock:     s.Stock,
+			Available: s.Stock,
+			Status:    buildLine(item, s).Status,
+		}
+		switch line.Status {
+		case domain.LineDeleted, domain.LineOffList, domain.LineOutOfStock:
+			line.Available = 0
+			res.Shortages = append(res.Shortages, line)
+		}
+		res.Lines = append(res.Lines, line)
+	}
+	if len(res.Shortages) > 0 {
+		return res, ErrInsufficientStock
+	}
+
+	short, err := svc.holdRepo.Reserve(ctx, uid, res.Lines, holdTTL)
+	if err != nil {
+		return domain.Reservation{}, err
+	}
+	if len(short) > 0 {
+		for i := range short {
+			short[i].Status = domain.LineInsufficient
+		}
+		res.Shortages = short
+		return res, ErrInsufficientStock
+	}
+	res.ExpireAt = time.Now().Add(holdTTL).UnixMilli()
+
+	// 上次结算预占、这次没有勾选的商品释放掉
+	holds, _, err := svc.holdRepo.UserHolds(ctx, uid)
+	if err != nil {
+		return res, err
+	}
+	for _, id := range ids {
+		delete(holds, id)
+	}
+	stale := make([]uint64, 0, len(holds))
+	for id := range holds {
+		stale = append(stale, id)
+	}
+	_, err = svc.holdRepo.Release(ctx, uid, stale)
+	return res, err
+}
+
+// CancelCheckout 取消结算，释放全部预占
+func (svc *HoldService) CancelCheckout(ctx context.Context, uid uint64) error {
+	_, err := svc.holdRepo.ReleaseAll(ctx, uid)
+	return err
+}
+
+// Inventory 商家查看商品库存中可售和已被预占的数量
+func (svc *HoldService) Inventory(ctx context.Context, merchantId uint64, productId string) (domain.Inventory, error) {
+	id, err := strconv.ParseUint(productId, 10, 64)
+	if err != nil {
+		return domain.Inventory{}, err
+	}
+	summaries, err := svc.productRepo.FindProductSummaries(ctx, []uint64{id})
+	if err != nil {
+		return domain.Inventory{}, err
+	}
+	if len(summaries) == 0 || summaries[0].IsDeleted {
+		return domain.Inventory{}, ErrProductNotFound
+	}
+	if summaries[0].MerchantId != merchantId {
+		return domain.Inventory{}, ErrProductNotOwned
+	}
+
+	reserved, err := svc.holdRepo.Reserved(ctx, []uint64{id})
+	if err != nil {
+		return domain.Inventory{}, err
+	}
+	stock := summaries[0].Stock
+	return domain.Inventory{
+		ProductId: id,
+		Stock:     stock,
+		Reserved:  reserved[0],
+		Available: max(stock-reserved[0], 0),
+	}, nil
+}
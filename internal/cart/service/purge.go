@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"mall/internal/cart/repository"
+	pdomain "mall/internal/product/domain"
+)
+
+// PurgeHook 订阅商品事件，商品被彻底删除后从所有购物车和稍后购买中移除
+type PurgeHook struct {
+	repo      repository.CartRepository
+	savedRepo *repository.SavedRepository
+}
+
+func NewPurgeHook(repo repository.CartRepository, savedRepo *repository.SavedRepository) *PurgeHook {
+	return &PurgeHook{
+		repo:      repo,
+		savedRepo: savedRepo,
+	}
+}
+
+func (h *PurgeHook) OnProductEvent(ctx context.Context, evt pdomain.ProductEvent) error {
+	if evt.Type != pdomain.EventPurge {
+		return nil
+	}
+
+	return errors.Join(
+		h.repo.RemoveProduct(ctx, evt.ProductId),
+		h.savedRepo.RemoveProduct(ctx, evt.ProductId),
+	)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"mall/internal/cart/domain"
+	"mall/internal/cart/repository"
+	pdomain "mall/internal/product/domain"
+	repository2 "mall/internal/product/repository"
+)
+
+var (
+	ErrSavedNotFound = repository.ErrSavedNotFound
+	ErrSavedFull     = fmt.Errorf("saved list can hold at most %d products", maxSavedItems)
+)
+
+// 稍后购买中的商品数量上限
+const maxSavedItems = 100
+
+// SavedService 稍后购买，只对登录用户开放，和购物车之间移动商品时带上数量和备注
+type SavedService struct {
+	cartSvc     *CartService
+	cartRepo    repository.CartRepository
+	savedRepo   *repository.SavedRepository
+	productRepo *repository2.ProductRepository
+}
+
+func NewSavedService(cartSvc *CartService, cartRepo repository.CartRepository, savedRepo *repository.SavedRepository,
+	productRepo *repository2.ProductRepository) *SavedService {
+	return &SavedService{
+		cartSvc:     cartSvc,
+		cartRepo:    cartRepo,
+		savedRepo:   savedRepo,
+		productRepo: productRepo,
+	}
+}
+
+// SaveForLater 把购物车中的商品移到稍后购买，已在稍后购买中时数量相加
+func (svc *SavedService) SaveForLater(ctx context.Context, uid uint64, productId string) error {
+	id, err := strconv.ParseUint(productId, 10, 64)
+	if err != nil {
+		return err
+	}
+	item, err := svc.cartRepo.FindItem(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+
+	saved, err := svc.savedRepo.FindItem(ctx, uid, id)
+	switch {
+	case errors.Is(err, ErrSavedNotFound):
+		count, err := svc.savedRepo.CountItems(ctx, uid)
+		if err != nil {
+			return err
+		}
+		if count >= maxSavedItems {
+			return ErrSavedFull
+		}
+	case err != nil:
+		return err
+	}
+	if item.Note == "" {
+		item.Note = saved.Note
+	}
+
+	// 先写入稍后购买再移出购物车，中途失败时商品不会丢失
+	err = svc.savedRepo.SaveItem(ctx, domain.SavedItem{
+		ProductID:  id,
+		UserID:     uid,
+		Quantity:   min(saved.Quantity+item.Quantity, maxItemQuantity),
+		Note:       item.Note,
+		AddedPrice: item.AddedPrice,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = svc.cartRepo.DeleteItems(ctx, uid, []uint64{id}); err != nil {
+		return err
+	}
+	svc.cartSvc.releaseHolds(ctx, uid, []uint64{id})
+	return nil
+}
+
+// MoveToCart 把稍后购买中的商品移回购物车，加入购物车的校验和直接加购相同
+func (svc *SavedService) MoveToCart(ctx context.Context, uid uint64, productId string) error {
+	id, err := strconv.ParseUint(productId, 10, 64)
+	if err != nil {
+		return err
+	}
+	saved, err := svc.savedRepo.FindItem(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+
+	err = svc.cartSvc.AddCart(ctx, domain.CartItem{
+		ProductID: id,
+		UserID:    uid,
+		Quantity:  saved.Quantity,
+		Note:      saved.Note,
+	})
+	if err != nil {
+		return err
+	}
+	return svc.savedRepo.DeleteItem(ctx, uid, id)
+}
+
+func (svc *SavedService) RemoveSaved(ctx context.Context, uid uint64, productId string) error {
+	id, err := strconv.ParseUint(productId, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	return svc.savedRepo.DeleteItem(ctx, uid, id)
+}
+
+// GetSaved 稍后购买列表，按加入时间倒序，商品信息为实时数据
+func (svc *SavedService) GetSaved(ctx context.Context, uid uint64) ([]domain.CartLine, error) {
+	items, err := svc.savedRepo.FindItems(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	summaries, err := svc.productRepo.FindProductSummaries(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	summaryOf := make(map[uint64]pdomain.ProductSummary, len(summaries))
+	for _, s := range summaries {
+		summaryOf[s.Id] = s
+	}
+
+	lines := make([]domain.CartLine, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, buildLine(domain.CartItem{
+			ProductID:  item.ProductID,
+			Quantity:   item.Quantity,
+			AddedPrice: item.AddedPrice,
+			Note:       item.Note,
+		}, summaryOf[item.ProductID]))
+	}
+	return lines, nil
+}
@@ -1,5 +1,18 @@
 package cart
 
-import "mall/internal/cart/web"
+import (
+	"mall/internal/cart/job"
+	"mall/internal/cart/repository"
+	"mall/internal/cart/service"
+	"mall/internal/cart/web"
+)
 
-type Handler web.CartHandler
+type Handler = web.CartHandler // 暴露出去给 ioc 使用
+
+type Repository = repository.CartRepository
+
+type FlushJob = job.FlushJob
+
+type GuestCartService = service.GuestCartService
+
+type PurgeHook = service.PurgeHook
@@ -1,84 +1,269 @@
 package web
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth"
 	"mall/internal/cart/domain"
 	"mall/internal/cart/service"
-	"net/http"
+	pservice "mall/internal/product/service"
+	"mall/pkg/ginx"
 )
 
 type CartHandler struct {
-	svc *service.CartService
+	svc      *service.CartService
+	guestSvc *service.GuestCartService
+	savedSvc *service.SavedService
+	holdSvc  *service.HoldService
 }
 
-func NewCartHandler(svc *service.CartService) *CartHandler {
+func NewCartHandler(svc *service.CartService, guestSvc *service.GuestCartService, savedSvc *service.SavedService,
+	holdSvc *service.HoldService) *CartHandler {
 	return &CartHandler{
-		svc: svc,
+		svc:      svc,
+		guestSvc: guestSvc,
+		savedSvc: savedSvc,
+		holdSvc:  holdSvc,
 	}
 }
 
+func (ctl *CartHandler) RegisterRoute(r *gin.Engine) {
+	group := r.Group("api/cart")
+	{
+		group.GET("/", ctl.GetCart())                       // 购物车
+		group.POST("/items", ctl.AddCartItem())             // 加入购物车
+		group.PUT("/items/:productId", ctl.SetQuantity())   // 修改数量，为 0 时移出
+		group.DELETE("/items/:productId", ctl.RemoveItem()) // 移出购物车
+		group.POST("/items/remove", ctl.RemoveItems())      // 批量移出
+		group.PUT("/selection", ctl.SelectItems())          // 勾选或取消勾选
+		group.DELETE("/", ctl.EmptyCart())                  // 清空购物车
+		group.GET("/merge", ctl.GetMergeResult())           // 登录时游客购物车的合并结果，登录响应中已经带上，供响应丢失时补查
+
+		group.PUT("/items/:productId/note", ctl.SetNote())       // 修改备注
+		group.POST("/items/:productId/save", ctl.SaveForLater()) // 移到稍后购买
+		group.GET("/saved", ctl.GetSaved())                      // 稍后购买列表
+		group.POST("/saved/:productId/move", ctl.MoveToCart())   // 移回购物车
+		group.DELETE("/saved/:productId", ctl.RemoveSaved())     // 从稍后购买中删除
+
+		group.POST("/checkout", ctl.Checkout())         // 进入结算并预占库存
+		group.DELETE("/checkout", ctl.CancelCheckout()) // 取消结算并释放预占
+	}
+
+	r.GET("api/inventory/products/:id", ctl.Inventory()) // 商家查看可售和已预占的库存
+}
+
 func (ctl *CartHandler) AddCartItem() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		type Req struct {
-			ProductID uint64 `json:"productI_id"`
-			UserID    uint64 `json:"user_id"`
+			ProductID uint64 `json:"productId"`
 			Quantity  int    `json:"quantity"`
+			Note      string `json:"note"`
 		}
 		var req Req
-		if err := c.Bind(&req); err != nil {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("invalid request body")))
+			return
+		}
+		svc, uid, ok := ctl.owner(c)
+		if !ok {
 			return
 		}
 
-		err := ctl.svc.AddCart(c.Request.Context(), domain.CartItem{
-			UserID:    req.UserID,
+		err := svc.AddCart(c.Request.Context(), domain.CartItem{
+			UserID:    uid,
 			ProductID: req.ProductID,
 			Quantity:  req.Quantity,
+			Note:      req.Note,
 		})
-		if err != nil {
-			c.JSON(err.Code, err.Msg)
+		if !writeErr(c, err) {
 			return
 		}
 
-		c.JSON(http.StatusOK, "添加购物车成功")
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("add to cart successfully")))
 	}
 }
 
 func (ctl *CartHandler) GetCart() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		svc, uid, ok := ctl.owner(c)
+		if !ok {
+			return
+		}
+
+		items, err := svc.GetCart(c.Request.Context(), uid)
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(items)))
+	}
+}
+
+func (ctl *CartHandler) SetQuantity() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		type Req struct {
-			UserID uint64 `json:"user_id"`
+			Quantity int `json:"quantity"`
 		}
 		var req Req
-		if err := c.Bind(&req); err != nil {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("invalid request body")))
+			return
+		}
+		svc, uid, ok := ctl.owner(c)
+		if !ok {
 			return
 		}
 
-		carts, err := ctl.svc.GetCart(c.Request.Context(), req.UserID)
-		if err != nil {
-			c.JSON(err.Code, err.Msg)
+		err := svc.SetQuantity(c.Request.Context(), uid, c.Param("productId"), req.Quantity)
+		if !writeErr(c, err) {
 			return
 		}
 
-		c.JSON(http.StatusOK, carts)
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("update quantity successfully")))
 	}
 }
 
-func (ctl *CartHandler) EmptyCart() gin.HandlerFunc {
+func (ctl *CartHandler) RemoveItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		svc, uid, ok := ctl.owner(c)
+		if !ok {
+			return
+		}
+
+		err := svc.RemoveItem(c.Request.Context(), uid, c.Param("productId"))
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("remove from cart successfully")))
+	}
+}
+
+func (ctl *CartHandler) RemoveItems() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		type Req struct {
-			UserID uint64 `json:"user_id"`
+			ProductIds []uint64 `json:"productIds"`
 		}
 		var req Req
-		if err := c.Bind(&req); err != nil {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("invalid request body")))
+			return
+		}
+		svc, uid, ok := ctl.owner(c)
+		if !ok {
 			return
 		}
 
-		err := ctl.svc.EmptyCart(c.Request.Context(), req.UserID)
-		if err != nil {
-			c.JSON(err.Code, err.Msg)
+		n, err := svc.RemoveItems(c.Request.Context(), uid, req.ProductIds)
+		if !writeErr(c, err) {
 			return
 		}
 
-		c.JSON(http.StatusOK, "清空购物车成功")
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(map[string]any{
+			"removed": n,
+		})))
 	}
 }
+
+func (ctl *CartHandler) SelectItems() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			ProductIds []uint64 `json:"productIds"` // 为空时作用于全部商品
+			Selected   bool     `json:"selected"`
+		}
+		var req Req
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("invalid request body")))
+			return
+		}
+		svc, uid, ok := ctl.owner(c)
+		if !ok {
+			return
+		}
+
+		err := svc.SelectItems(c.Request.Context(), uid, req.ProductIds, req.Selected)
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("update selection successfully")))
+	}
+}
+
+func (ctl *CartHandler) EmptyCart() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		svc, uid, ok := ctl.owner(c)
+		if !ok {
+			return
+		}
+
+		err := svc.EmptyCart(c.Request.Context(), uid)
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("empty cart successfully")))
+	}
+}
+
+// GetMergeResult 登录后读取游客购物车的合并结果，登录时没有合并或已过期时 data 为 null
+func (ctl *CartHandler) GetMergeResult() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		res, err := ctl.guestSvc.GetMergeResult(c.Request.Context(), claim.Id)
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(res)))
+	}
+}
+
+// writeErr 把错误写回响应，没有错误时返回 true
+func writeErr(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrInvalidQuantity):
+		c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("quantity must be positive")))
+	case errors.Is(err, service.ErrQuantityExceeded), errors.Is(err, service.ErrCartFull),
+		errors.Is(err, service.ErrSavedFull), errors.Is(err, service.ErrNoteTooLong), errors.Is(err, service.ErrNothingSelected):
+		c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg(err.Error())))
+	case errors.Is(err, service.ErrItemNotFound):
+		c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product is not in the cart")))
+	case errors.Is(err, service.ErrProductNotOwned):
+		c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("product does not belong to you")))
+	case errors.Is(err, service.ErrSavedNotFound):
+		c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product is not in the saved list")))
+	case errors.Is(err, service.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+	case errors.Is(err, service.ErrProductNotOnList):
+		c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("product is not on list")))
+	default:
+		c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+	}
+
+	return false
+}
+
+// owner 登录用户使用自己的购物车，未登录时使用 X-Device-Id 对应的游客购物车
+func (ctl *CartHandler) owner(c *gin.Context) (*service.CartService, uint64, bool) {
+	if claim, ok := auth.Claim(c); ok {
+		return ctl.svc, claim.Id, true
+	}
+
+	guestId, err := ctl.guestSvc.Identify(c.GetHeader(pservice.DeviceIdHeader))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ginx.GetResponse(ginx.WithStatus(http.StatusUnauthorized), ginx.WithMsg("login or provide a valid X-Device-Id header")))
+		return nil, 0, false
+	}
+
+	return ctl.guestSvc.CartService, guestId, true
+}
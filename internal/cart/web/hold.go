@@ -0,0 +1,67 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth"
+	"mall/internal/cart/service"
+	"mall/pkg/ginx"
+)
+
+// Checkout 进入结算，为勾选的商品预占库存
+func (ctl *CartHandler) Checkout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		res, err := ctl.holdSvc.Checkout(c.Request.Context(), claim.Id)
+		if errors.Is(err, service.ErrInsufficientStock) {
+			c.JSON(http.StatusConflict, ginx.GetResponse(ginx.WithStatus(http.StatusConflict), ginx.WithMsg(err.Error()), ginx.WithData(res)))
+			return
+		}
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(res)))
+	}
+}
+
+// CancelCheckout 离开结算，释放预占
+func (ctl *CartHandler) CancelCheckout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.holdSvc.CancelCheckout(c.Request.Context(), claim.Id)
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("cancel checkout successfully")))
+	}
+}
+
+// Inventory 商家查看商品的库存、已预占和可售数量
+func (ctl *CartHandler) Inventory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		inv, err := ctl.holdSvc.Inventory(c.Request.Context(), claim.Id, c.Param("id"))
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(inv)))
+	}
+}
@@ -0,0 +1,97 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"mall/internal/auth"
+	"mall/pkg/ginx"
+)
+
+func (ctl *CartHandler) SetNote() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			Note string `json:"note"` // 为空时清除备注
+		}
+		var req Req
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("invalid request body")))
+			return
+		}
+		svc, uid, ok := ctl.owner(c)
+		if !ok {
+			return
+		}
+
+		err := svc.SetNote(c.Request.Context(), uid, c.Param("productId"), req.Note)
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("update note successfully")))
+	}
+}
+
+func (ctl *CartHandler) SaveForLater() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.savedSvc.SaveForLater(c.Request.Context(), claim.Id, c.Param("productId"))
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("save for later successfully")))
+	}
+}
+
+func (ctl *CartHandler) GetSaved() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		lines, err := ctl.savedSvc.GetSaved(c.Request.Context(), claim.Id)
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(lines)))
+	}
+}
+
+func (ctl *CartHandler) MoveToCart() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.savedSvc.MoveToCart(c.Request.Context(), claim.Id, c.Param("productId"))
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("move to cart successfully")))
+	}
+}
+
+func (ctl *CartHandler) RemoveSaved() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.savedSvc.RemoveSaved(c.Request.Context(), claim.Id, c.Param("productId"))
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("remove from saved list successfully")))
+	}
+}
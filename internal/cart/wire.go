@@ -6,24 +6,93 @@ import (
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"mall/internal/cart/job"
 	"mall/internal/cart/repository"
+	"mall/internal/cart/repository/cache"
 	"mall/internal/cart/repository/dao"
 	"mall/internal/cart/service"
 	"mall/internal/cart/web"
 	"mall/internal/product"
+	pservice "mall/internal/product/service"
+	"mall/pkg/logger"
 )
 
-func NewCartHandler(db *gorm.DB, cmd redis.Cmdable) *web.CartHandler {
+func InitCartHandler(repo repository.CartRepository, guestSvc *service.GuestCartService, db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache,
+	hooks []pservice.EventHook, l logger.Logger) *web.CartHandler {
 	wire.Build(
-		dao.NewCartDao,
-
-		repository.NewCartRepository,
+		dao.NewSavedDao,
+		repository.NewSavedRepository,
+		cache.NewHoldCache,
+		repository.NewHoldRepository,
 
 		product.NewProductRepository,
+		pservice.NewEventPublisher,
 
 		service.NewCartService,
+		service.NewSavedService,
+		service.NewHoldService,
 
 		web.NewCartHandler,
 	)
 	return new(web.CartHandler)
 }
+
+func InitGuestCartService(repo repository.CartRepository, db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache, hooks []pservice.EventHook,
+	devices *pservice.DeviceIds, l logger.Logger) *service.GuestCartService {
+	wire.Build(
+		cache.NewGuestCartCache,
+		repository.NewGuestCartRepository,
+
+		product.NewProductRepository,
+		pservice.NewEventPublisher,
+
+		service.NewGuestCartService,
+	)
+	return new(service.GuestCartService)
+}
+
+func InitPurgeHook(repo repository.CartRepository, db *gorm.DB) *service.PurgeHook {
+	wire.Build(
+		dao.NewSavedDao,
+		repository.NewSavedRepository,
+
+		service.NewPurgeHook,
+	)
+	return new(service.PurgeHook)
+}
+
+func InitFlushJob(repo repository.CartRepository, cmd redis.Cmdable, l logger.Logger) *job.FlushJob {
+	wire.Build(
+		job.NewFlushJob,
+	)
+	return new(job.FlushJob)
+}
+
+// NewDBCartRepository 只使用 MySQL 的购物车
+func NewDBCartRepository(db *gorm.DB) repository.CartRepository {
+	wire.Build(
+		dao.NewCartDao,
+		repository.NewDBCartRepository,
+	)
+	return nil
+}
+
+// NewRedisCartRepository 以 Redis 为准、异步写回 MySQL 的购物车
+func NewRedisCartRepository(db *gorm.DB, cmd redis.Cmdable) repository.CartRepository {
+	wire.Build(
+		dao.NewCartDao,
+		cache.NewCartCache,
+		repository.NewRedisCartRepository,
+		wire.Bind(new(repository.CartRepository), new(*repository.RedisCartRepository)),
+	)
+	return nil
+}
+
+// NewHoldRepository 结算时的库存预占，下单后由订单模块释放
+func NewHoldRepository(cmd redis.Cmdable) *repository.HoldRepository {
+	wire.Build(
+		cache.NewHoldCache,
+		repository.NewHoldRepository,
+	)
+	return new(repository.HoldRepository)
+}
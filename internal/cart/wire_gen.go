@@ -9,20 +9,74 @@ package cart
 import (
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"mall/internal/cart/job"
 	"mall/internal/cart/repository"
+	"mall/internal/cart/repository/cache"
 	"mall/internal/cart/repository/dao"
 	"mall/internal/cart/service"
 	"mall/internal/cart/web"
 	"mall/internal/product"
+	pservice "mall/internal/product/service"
+	"mall/pkg/logger"
 )
 
 // Injectors from wire.go:
 
-func NewCartHandler(db *gorm.DB, cmd redis.Cmdable) *web.CartHandler {
-	cartDao := dao.NewCartDao(db)
-	cartRepository := repository.NewCartRepository(cartDao)
-	productRepository := product.NewProductRepository(db, cmd)
-	cartService := service.NewCartService(cartRepository, productRepository)
-	cartHandler := web.NewCartHandler(cartService)
+func InitCartHandler(repo repository.CartRepository, guestSvc *service.GuestCartService, db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache,
+	hooks []pservice.EventHook, l logger.Logger) *web.CartHandler {
+	productRepository := product.NewProductRepository(db, cmd, pc)
+	eventPublisher := pservice.NewEventPublisher(hooks, l)
+	holdCache := cache.NewHoldCache(cmd)
+	holdRepository := repository.NewHoldRepository(holdCache)
+	cartService := service.NewCartService(repo, holdRepository, productRepository, eventPublisher)
+	savedDao := dao.NewSavedDao(db)
+	savedRepository := repository.NewSavedRepository(savedDao)
+	savedService := service.NewSavedService(cartService, repo, savedRepository, productRepository)
+	holdService := service.NewHoldService(repo, holdRepository, productRepository)
+	cartHandler := web.NewCartHandler(cartService, guestSvc, savedService, holdService)
 	return cartHandler
 }
+
+func InitGuestCartService(repo repository.CartRepository, db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache, hooks []pservice.EventHook,
+	devices *pservice.DeviceIds, l logger.Logger) *service.GuestCartService {
+	guestCartCache := cache.NewGuestCartCache(cmd)
+	guestCartRepository := repository.NewGuestCartRepository(guestCartCache)
+	productRepository := product.NewProductRepository(db, cmd, pc)
+	eventPublisher := pservice.NewEventPublisher(hooks, l)
+	guestCartService := service.NewGuestCartService(guestCartRepository, repo, productRepository, eventPublisher, devices)
+	return guestCartService
+}
+
+func InitPurgeHook(repo repository.CartRepository, db *gorm.DB) *service.PurgeHook {
+	savedDao := dao.NewSavedDao(db)
+	savedRepository := repository.NewSavedRepository(savedDao)
+	purgeHook := service.NewPurgeHook(repo, savedRepository)
+	return purgeHook
+}
+
+func InitFlushJob(repo repository.CartRepository, cmd redis.Cmdable, l logger.Logger) *job.FlushJob {
+	flushJob := job.NewFlushJob(repo, cmd, l)
+	return flushJob
+}
+
+// NewDBCartRepository 只使用 MySQL 的购物车
+func NewDBCartRepository(db *gorm.DB) repository.CartRepository {
+	cartDao := dao.NewCartDao(db)
+	cartRepository := repository.NewDBCartRepository(cartDao)
+	return cartRepository
+}
+
+// NewRedisCartRepository 以 Redis 为准、异步写回 MySQL 的购物车
+func NewRedisCartRepository(db *gorm.DB, cmd redis.Cmdable) repository.CartRepository {
+	cartDao := dao.NewCartDao(db)
+	cartCache := cache.NewCartCache(cmd)
+	redisCartRepository := repository.NewRedisCartRepository(cartDao, cartCache)
+	return redisCartRepository
+}
+
+// NewHoldRepository 结算时的库存预占，下单后由订单模块释放
+func NewHoldRepository(cmd redis.Cmdable) *repository.HoldRepository {
+	holdCache := cache.NewHoldCache(cmd)
+	holdRepository := repository.NewHoldRepository(holdCache)
+	return holdRepository
+}
@@ -0,0 +1,20 @@
+package domain
+
+// 通知类型
+const (
+	TypePriceDrop   = "price_drop"
+	TypeBackInStock = "back_in_stock"
+	TypeNewAnswer   = "new_answer"
+)
+
+// Notification 站内通知
+type Notification struct {
+	Id        uint64 `json:"id"`
+	UserId    uint64 `json:"-"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	ProductId uint64 `json:"productId"` // 关联的商品，没有时为 0
+	IsRead    bool   `json:"isRead"`
+	CreateAt  int64  `json:"createAt"`
+}
@@ -0,0 +1,12 @@
+package dao
+
+type Notification struct {
+	Id        uint64 `gorm:"primaryKey,autoIncrement"`
+	UserId    uint64 `gorm:"not null;index:idx_user_read,priority:1"`
+	Type      string `gorm:"type:varchar(32);not null"`
+	Title     string `gorm:"type:varchar(128);not null"`
+	Content   string `gorm:"type:text"`
+	ProductId uint64 `gorm:"not null;default:0"`
+	IsRead    bool   `gorm:"not null;default:false;index:idx_user_read,priority:2"`
+	CreateAt  int64
+}
@@ -0,0 +1,84 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mall/internal/notification/domain"
+)
+
+type NotificationDao struct {
+	db *gorm.DB
+}
+
+func NewNotificationDao(db *gorm.DB) *NotificationDao {
+	return &NotificationDao{
+		db: db,
+	}
+}
+
+func (dao *NotificationDao) Insert(ctx context.Context, ns []domain.Notification) error {
+	if len(ns) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	rows := make([]Notification, 0, len(ns))
+	for _, n := range ns {
+		rows = append(rows, Notification{
+			UserId:    n.UserId,
+			Type:      n.Type,
+			Title:     n.Title,
+			Content:   n.Content,
+			ProductId: n.ProductId,
+			CreateAt:  now,
+		})
+	}
+
+	return dao.db.WithContext(ctx).CreateInBatches(rows, 100).Error
+}
+
+func (dao *NotificationDao) FindByUser(ctx context.Context, uid uint64, unreadOnly bool, offset, limit int) ([]domain.Notification, error) {
+	query := dao.db.WithContext(ctx).Where("user_id = ?", uid)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+
+	var rows []Notification
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Notification, 0, len(rows))
+	for _, n := range rows {
+		res = append(res, domain.Notification{
+			Id:        n.Id,
+			UserId:    n.UserId,
+			Type:      n.Type,
+			Title:     n.Title,
+			Content:   n.Content,
+			ProductId: n.ProductId,
+			IsRead:    n.IsRead,
+			CreateAt:  n.CreateAt,
+		})
+	}
+	return res, nil
+}
+
+func (dao *NotificationDao) CountUnread(ctx context.Context, uid uint64) (int64, error) {
+	var count int64
+	err := dao.db.WithContext(ctx).Model(&Notification{}).Where("user_id = ? AND is_read = ?", uid, false).Count(&count).Error
+	return count, err
+}
+
+// MarkRead 标记已读，ids 为空时标记该用户的全部通知
+func (dao *NotificationDao) MarkRead(ctx context.Context, uid uint64, ids []uint64) error {
+	query := dao.db.WithContext(ctx).Model(&Notification{}).Where("user_id = ? AND is_read = ?", uid, false)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	return query.Update("is_read", true).Error
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"mall/internal/notification/domain"
+	"mall/internal/notification/repository/dao"
+)
+
+type NotificationRepository struct {
+	dao *dao.NotificationDao
+}
+
+func NewNotificationRepository(dao *dao.NotificationDao) *NotificationRepository {
+	return &NotificationRepository{
+		dao: dao,
+	}
+}
+
+func (repo *NotificationRepository) InsertNotifications(ctx context.Context, ns []domain.Notification) error {
+	return repo.dao.Insert(ctx, ns)
+}
+
+func (repo *NotificationRepository) FindNotifications(ctx context.Context, uid uint64, unreadOnly bool, offset, limit int) ([]domain.Notification, error) {
+	return repo.dao.FindByUser(ctx, uid, unreadOnly, offset, limit)
+}
+
+func (repo *NotificationRepository) CountUnread(ctx context.Context, uid uint64) (int64, error) {
+	return repo.dao.CountUnread(ctx, uid)
+}
+
+func (repo *NotificationRepository) MarkRead(ctx context.Context, uid uint64, ids []uint64) error {
+	return repo.dao.MarkRead(ctx, uid, ids)
+}
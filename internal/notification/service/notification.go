@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"mall/internal/notification/domain"
+	"mall/internal/notification/repository"
+)
+
+type NotificationService struct {
+	repo *repository.NotificationRepository
+}
+
+func NewNotificationService(repo *repository.NotificationRepository) *NotificationService {
+	return &NotificationService{
+		repo: repo,
+	}
+}
+
+// Send 发送站内通知，供其他模块调用
+func (svc *NotificationService) Send(ctx context.Context, ns ...domain.Notification) error {
+	return svc.repo.InsertNotifications(ctx, ns)
+}
+
+func (svc *NotificationService) GetNotifications(ctx context.Context, uid uint64, unreadOnly bool, page, size int) ([]domain.Notification, error) {
+	return svc.repo.FindNotifications(ctx, uid, unreadOnly, (page-1)*size, size)
+}
+
+func (svc *NotificationService) CountUnread(ctx context.Context, uid uint64) (int64, error) {
+	return svc.repo.CountUnread(ctx, uid)
+}
+
+// MarkRead 标记已读，ids 为空时全部标记为已读
+func (svc *NotificationService) MarkRead(ctx context.Context, uid uint64, ids []uint64) error {
+	return svc.repo.MarkRead(ctx, uid, ids)
+}
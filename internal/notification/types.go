@@ -0,0 +1,5 @@
+package notification
+
+import "mall/internal/notification/web"
+
+type Handler = web.NotificationHandler
@@ -0,0 +1,96 @@
+package web
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth"
+	"mall/internal/notification/service"
+	"mall/pkg/ginx"
+)
+
+type NotificationHandler struct {
+	svc *service.NotificationService
+}
+
+func NewNotificationHandler(svc *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *NotificationHandler) RegisterRoute(r *gin.Engine) {
+	group := r.Group("api/notifications")
+	{
+		group.GET("", ctl.GetNotifications())         // 通知列表
+		group.GET("/unread-count", ctl.CountUnread()) // 未读数量
+		group.POST("/read", ctl.MarkRead())           // 标记已读
+	}
+}
+
+func (ctl *NotificationHandler) GetNotifications() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+		page, size := ginx.Pagination(c)
+		ns, err := ctl.svc.GetNotifications(c.Request.Context(), claim.Id, unreadOnly, page, size)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(ns)))
+	}
+}
+
+func (ctl *NotificationHandler) CountUnread() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		count, err := ctl.svc.CountUnread(c.Request.Context(), claim.Id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(map[string]any{
+			"count": count,
+		})))
+	}
+}
+
+func (ctl *NotificationHandler) MarkRead() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			Ids []uint64 `json:"ids"` // 为空时全部标记为已读
+		}
+		var req Req
+		// 没有请求体时全部标记为已读
+		if err := c.ShouldBind(&req); err != nil && !errors.Is(err, io.EOF) {
+			_ = c.AbortWithError(http.StatusBadRequest, err).SetType(gin.ErrorTypeBind)
+			return
+		}
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		if err := ctl.svc.MarkRead(c.Request.Context(), claim.Id, req.Ids); err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("mark read successfully")))
+	}
+}
@@ -0,0 +1,34 @@
+//go:build wireinject
+
+package notification
+
+import (
+	"github.com/google/wire"
+	"gorm.io/gorm"
+	"mall/internal/notification/repository"
+	"mall/internal/notification/repository/dao"
+	"mall/internal/notification/service"
+	"mall/internal/notification/web"
+)
+
+var notificationSet = wire.NewSet(
+	dao.NewNotificationDao,
+	repository.NewNotificationRepository,
+	service.NewNotificationService,
+)
+
+func InitNotificationHandler(db *gorm.DB) *web.NotificationHandler {
+	wire.Build(
+		notificationSet,
+		web.NewNotificationHandler,
+	)
+	return new(web.NotificationHandler)
+}
+
+// NewNotificationService 供其他模块发送通知
+func NewNotificationService(db *gorm.DB) *service.NotificationService {
+	wire.Build(
+		notificationSet,
+	)
+	return new(service.NotificationService)
+}
@@ -0,0 +1,50 @@
+package domain
+
+const (
+	StatusPending   = "pending"   // 待支付
+	StatusPaid      = "paid"      // 已支付
+	StatusCancelled = "cancelled" // 已取消
+)
+
+type Order struct {
+	Id          uint64      `json:"id"`
+	OrderNo     string      `json:"orderNo"`
+	UserId      uint64      `json:"-"`
+	Status      string      `json:"status"`
+	ItemCount   int         `json:"itemCount"` // 商品总件数
+	TotalAmount float64     `json:"totalAmount"`
+	Address     Address     `json:"address"`
+	Items       []OrderItem `json:"items"`
+	CreateAt    int64       `json:"createAt"`
+}
+
+// Address 下单时收货地址的快照，之后用户修改或删除地址不影响订单
+type Address struct {
+	AddressId uint64 `json:"addressId"`
+	Street    string `json:"street"`
+	City      string `json:"city"`
+	State     string `json:"state"`
+	ZipCode   string `json:"zipCode"`
+	Country   string `json:"country"`
+}
+
+// OrderItem 订单中的一行，商品名称、主图和单价为下单时的快照
+type OrderItem struct {
+	ProductId  uint64  `json:"productId"`
+	MerchantId uint64  `json:"merchantId"`
+	Name       string  `json:"name"`
+	ImageUrl   string  `json:"imageUrl"`
+	Price      float64 `json:"price"`
+	Quantity   int     `json:"quantity"`
+	Subtotal   float64 `json:"subtotal"`
+	Note       string  `json:"note,omitempty"`
+}
+
+// UnavailableLine 下单时不能购买的购物车商品，Status 取值同购物车行的状态
+type UnavailableLine struct {
+	ProductId uint64 `json:"productId"`
+	Quantity  int    `json:"quantity"`
+	Stock     int    `json:"stock"`
+	Available int    `json:"available"` // 库存扣除其他用户结算时的预占
+	Status    string `json:"status"`
+}
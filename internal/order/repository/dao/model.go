@@ -0,0 +1,35 @@
+package dao
+
+// Order 订单，收货地址为下单时的快照
+type Order struct {
+	Id          uint64  `gorm:"primaryKey,autoIncrement"`
+	OrderNo     string  `gorm:"type:varchar(32);not null;uniqueIndex:uk_order_no"`
+	UserId      uint64  `gorm:"not null;index:idx_user_id,priority:1;uniqueIndex:uk_user_submit_key,priority:1"`
+	SubmitKey   *string `gorm:"type:varchar(64);uniqueIndex:uk_user_submit_key,priority:2"` // 下单的幂等键，没有时为 NULL，不参与唯一约束
+	Status      string  `gorm:"type:varchar(16);not null"`
+	ItemCount   int     `gorm:"not null"`
+	TotalAmount float64 `gorm:"not null"`
+	AddressId   uint64  `gorm:"not null"`
+	Street      string  `gorm:"type:varchar(255)"`
+	City        string  `gorm:"type:varchar(100)"`
+	State       string  `gorm:"type:varchar(100)"`
+	ZipCode     string  `gorm:"type:varchar(20)"`
+	Country     string  `gorm:"type:varchar(100)"`
+	CreateAt    int64   `gorm:"index:idx_user_id,priority:2"`
+	UpdateAt    int64
+}
+
+// OrderItem 订单中的商品，名称、主图和单价为下单时的快照
+type OrderItem struct {
+	Id         uint64  `gorm:"primaryKey,autoIncrement"`
+	OrderId    uint64  `gorm:"not null;index"`
+	ProductId  uint64  `gorm:"not null;index"`
+	MerchantId uint64  `gorm:"not null"`
+	Name       string  `gorm:"type:varchar(255);not null"`
+	ImageUrl   string  `gorm:"type:varchar(255)"`
+	Price      float64 `gorm:"not null"`
+	Quantity   int     `gorm:"not null"`
+	Subtotal   float64 `gorm:"not null"`
+	Note       string  `gorm:"type:varchar(255)"`
+	CreateAt   int64
+}
@@ -0,0 +1,108 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mall/pkg/gormx"
+)
+
+var (
+	ErrOrderNotFound   = errors.New("order not found")
+	ErrDuplicateSubmit = errors.New("order has already been submitted")
+)
+
+type OrderDao struct {
+	db  *gorm.DB
+	uow *gormx.UnitOfWork
+}
+
+func NewOrderDao(db *gorm.DB) *OrderDao {
+	return &OrderDao{
+		db:  db,
+		uow: gormx.NewUnitOfWork(db),
+	}
+}
+
+// InsertOrder 在同一事务中写入订单和订单商品，返回订单 ID；
+// 同一用户的幂等键已经下过单时返回 ErrDuplicateSubmit
+func (dao *OrderDao) InsertOrder(ctx context.Context, order Order, items []OrderItem) (uint64, error) {
+	now := time.Now().UnixMilli()
+	order.CreateAt = now
+	order.UpdateAt = now
+
+	err := dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+		if err := db.Create(&order).Error; err != nil {
+			if order.SubmitKey != nil && gormx.IsUniqueConflict(err) {
+				return ErrDuplicateSubmit
+			}
+			return err
+		}
+
+		for i := range items {
+			items[i].OrderId = order.Id
+			items[i].CreateAt = now
+		}
+		return db.Create(&items).Error
+	})
+
+	return order.Id, err
+}
+
+// UpdateStatus 订单状态为 from 时改为 to，返回是否修改成功，加入 ctx 中的事务
+func (dao *OrderDao) UpdateStatus(ctx context.Context, id uint64, from, to string) (bool, error) {
+	res := gormx.DB(ctx, dao.db).Model(&Order{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]any{
+			"status":    to,
+			"update_at": time.Now().UnixMilli(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// FindOrders 用户的订单，按下单时间倒序
+func (dao *OrderDao) FindOrders(ctx context.Context, uid uint64, offset, limit int) ([]Order, error) {
+	var orders []Order
+	err := dao.db.WithContext(ctx).Where("user_id = ?", uid).
+		Order("create_at DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
+// FindOrder 查询用户自己的订单，不属于该用户时返回 ErrOrderNotFound
+func (dao *OrderDao) FindOrder(ctx context.Context, uid, id uint64) (Order, error) {
+	var order Order
+	err := dao.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, uid).First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Order{}, ErrOrderNotFound
+	}
+
+	return order, err
+}
+
+// FindBySubmitKey 按幂等键查询用户的订单
+func (dao *OrderDao) FindBySubmitKey(ctx context.Context, uid uint64, key string) (Order, error) {
+	var order Order
+	err := dao.db.WithContext(ctx).Where("user_id = ? AND submit_key = ?", uid, key).First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Order{}, ErrOrderNotFound
+	}
+
+	return order, err
+}
+
+// FindItems 多个订单的商品，按订单和写入顺序排列
+func (dao *OrderDao) FindItems(ctx context.Context, orderIds []uint64) ([]OrderItem, error) {
+	if len(orderIds) == 0 {
+		return []OrderItem{}, nil
+	}
+
+	var items []OrderItem
+	err := dao.db.WithContext(ctx).Where("order_id IN ?", orderIds).Order("order_id, id").Find(&items).Error
+	return items, err
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+
+	"mall/internal/order/domain"
+	"mall/internal/order/repository/dao"
+)
+
+var (
+	ErrOrderNotFound   = dao.ErrOrderNotFound
+	ErrDuplicateSubmit = dao.ErrDuplicateSubmit
+)
+
+type OrderRepository struct {
+	dao *dao.OrderDao
+}
+
+func NewOrderRepository(dao *dao.OrderDao) *OrderRepository {
+	return &OrderRepository{
+		dao: dao,
+	}
+}
+
+// CreateOrder 写入订单，submitKey 为空时不做幂等
+func (repo *OrderRepository) CreateOrder(ctx context.Context, order domain.Order, submitKey string) (uint64, error) {
+	items := make([]dao.OrderItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, dao.OrderItem{
+			ProductId:  item.ProductId,
+			MerchantId: item.MerchantId,
+			Name:       item.Name,
+			ImageUrl:   item.ImageUrl,
+			Price:      item.Price,
+			Quantity:   item.Quantity,
+			Subtotal:   item.Subtotal,
+			Note:       item.Note,
+		})
+	}
+
+	return repo.dao.InsertOrder(ctx, dao.Order{
+		OrderNo:     order.OrderNo,
+		UserId:      order.UserId,
+		SubmitKey:   submitKeyOf(submitKey),
+		Status:      order.Status,
+		ItemCount:   order.ItemCount,
+		TotalAmount: order.TotalAmount,
+		AddressId:   order.Address.AddressId,
+		Street:      order.Address.Street,
+		City:        order.Address.City,
+		State:       order.Address.State,
+		ZipCode:     order.Address.ZipCode,
+		Country:     order.Address.Country,
+	}, items)
+}
+
+func (repo *OrderRepository) UpdateStatus(ctx context.Context, id uint64, from, to string) (bool, error) {
+	return repo.dao.UpdateStatus(ctx, id, from, to)
+}
+
+// FindOrders 用户的订单列表，带订单商品
+func (repo *OrderRepository) FindOrders(ctx context.Context, uid uint64, offset, limit int) ([]domain.Order, error) {
+	orders, err := repo.dao.FindOrders(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.withItems(ctx, orders)
+}
+
+func (repo *OrderRepository) FindOrder(ctx context.Context, uid, id uint64) (domain.Order, error) {
+	order, err := repo.dao.FindOrder(ctx, uid, id)
+	if err != nil {
+		return domain.Order{}, err
+	}
+
+	res, err := repo.withItems(ctx, []dao.Order{order})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return res[0], nil
+}
+
+// FindBySubmitKey 用户用这个幂等键下的订单，带订单商品
+func (repo *OrderRepository) FindBySubmitKey(ctx context.Context, uid uint64, key string) (domain.Order, error) {
+	order, err := repo.dao.FindBySubmitKey(ctx, uid, key)
+	if err != nil {
+		return domain.Order{}, err
+	}
+
+	res, err := repo.withItems(ctx, []dao.Order{order})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return res[0], nil
+}
+
+// withItems 批量查询订单商品并组装
+func (repo *OrderRepository) withItems(ctx context.Context, orders []dao.Order) ([]domain.Order, error) {
+	ids := make([]uint64, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.Id)
+	}
+	items, err := repo.dao.FindItems(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsOf := make(map[uint64][]domain.OrderItem, len(orders))
+	for _, item := range items {
+		itemsOf[item.OrderId] = append(itemsOf[item.OrderId], domain.OrderItem{
+			ProductId:  item.ProductId,
+			MerchantId: item.MerchantId,
+			Name:       item.Name,
+			ImageUrl:   item.ImageUrl,
+			Price:      item.Price,
+			Quantity:   item.Quantity,
+			Subtotal:   item.Subtotal,
+			Note:       item.Note,
+		})
+	}
+
+	res := make([]domain.Order, 0, len(orders))
+	for _, order := range orders {
+		res = append(res, domain.Order{
+			Id:          order.Id,
+			OrderNo:     order.OrderNo,
+			UserId:      order.UserId,
+			Status:      order.Status,
+			ItemCount:   order.ItemCount,
+			TotalAmount: order.TotalAmount,
+			Address: domain.Address{
+				AddressId: order.AddressId,
+				Street:    order.Street,
+				City:      order.City,
+				State:     order.State,
+				ZipCode:   order.ZipCode,
+				Country:   order.Country,
+			},
+			Items:    itemsOf[order.Id],
+			CreateAt: order.CreateAt,
+		})
+	}
+	return res, nil
+}
+
+// submitKeyOf 空的幂等键存为 NULL，不占用唯一索引
+func submitKeyOf(key string) *string {
+	if key == "" {
+		return nil
+	}
+	return &key
+}
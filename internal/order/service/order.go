@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	cdomain "mall/internal/cart/domain"
+	crepo "mall/internal/cart/repository"
+	"mall/internal/order/domain"
+	"mall/internal/order/repository"
+	pdomain "mall/internal/product/domain"
+	prepo "mall/internal/product/repository"
+	pservice "mall/internal/product/service"
+	urepo "mall/internal/user/repository"
+	"mall/pkg/delay"
+	"mall/pkg/gormx"
+)
+
+var (
+	ErrOrderNotFound       = repository.ErrOrderNotFound
+	ErrDuplicateSubmit     = repository.ErrDuplicateSubmit
+	ErrNothingSelected     = errors.New("no products are selected")
+	ErrAddressNotFound     = errors.New("address not found")
+	ErrProductsUnavailable = errors.New("some products cannot be purchased")
+	ErrOrderNotPending     = errors.New("order is not pending payment")
+)
+
+// TaskExpireOrder 超时未支付自动取消订单的延时任务
+const TaskExpireOrder = "order:expire"
+
+// 下单后等待支付的时长
+const payTimeout = time.Minute * 30
+
+type OrderService struct {
+	repo        *repository.OrderRepository
+	cartRepo    crepo.CartRepository
+	holdRepo    *crepo.HoldRepository
+	userRepo    *urepo.UserRepository
+	productRepo *prepo.ProductRepository
+	events      *pservice.EventPublisher
+	uow         *gormx.UnitOfWork
+	sched       *delay.Scheduler
+}
+
+// NewOrderService 同时向 sched 注册超时取消订单的任务
+func NewOrderService(repo *repository.OrderRepository, cartRepo crepo.CartRepository, holdRepo *crepo.HoldRepository,
+	userRepo *urepo.UserRepository, productRepo *prepo.ProductRepository, events *pservice.EventPublisher,
+	uow *gormx.UnitOfWork, sched *delay.Scheduler) *OrderService {
+	svc := &OrderService{
+		repo:        repo,
+		cartRepo:    cartRepo,
+		holdRepo:    holdRepo,
+		userRepo:    userRepo,
+		productRepo: productRepo,
+		events:      events,
+		uow:         uow,
+		sched:       sched,
+	}
+	sched.Register(TaskExpireOrder, svc.expireOrder)
+	return svc
+}
+
+// expirePayload 超时取消任务的内容
+type expirePayload struct {
+	OrderId uint64 `json:"orderId"`
+	OrderNo string `json:"orderNo"`
+}
+
+// CreateOrder 用购物车中勾选的商品下单，addressId 为 0 时使用默认地址。
+// 单价取商品当前价格；可购买数量为库存扣除其他用户结算时的预占，
+// 任一商品不可购买时不下单，返回 ErrProductsUnavailable 和不可购买的商品。
+// submitKey 不为空时，同一用户重复提交相同的 key 只下一次单，返回首次创建的订单。
+// 扣减库存和写入订单在同一个事务中；超时取消任务不在事务中，提交前添加，事务回滚时取消。
+// 下单成功后从购物车移除已购买的商品并释放结算时的预占
+func (svc *OrderService) CreateOrder(ctx context.Context, uid, addressId uint64, submitKey string) (domain.Order, []domain.UnavailableLine, error) {
+	if submitKey != "" {
+		order, err := svc.repo.FindBySubmitKey(ctx, uid, submitKey)
+		if err == nil || !errors.Is(err, ErrOrderNotFound) {
+			return order, nil, err
+		}
+	}
+
+	cartItems, err := svc.cartRepo.GetCart(ctx, uid)
+	if err != nil {
+		return domain.Order{}, nil, err
+	}
+	selected := make([]cdomain.CartItem, 0, len(cartItems))
+	ids := make([]uint64, 0, len(cartItems))
+	for _, item := range cartItems {
+		if item.Selected {
+			selected = append(selected, item)
+			ids = append(ids, item.ProductID)
+		}
+	}
+	if len(selected) == 0 {
+		return domain.Order{}, nil, ErrNothingSelected
+	}
+
+	addr, err := svc.address(ctx, uid, addressId)
+	if err != nil {
+		return domain.Order{}, nil, err
+	}
+
+	summaries, err := svc.productRepo.FindProductSummaries(ctx, ids)
+	if err != nil {
+		return domain.Order{}, nil, err
+	}
+	summaryOf := make(map[uint64]pdomain.ProductSummary, len(summaries))
+	for _, s := range summaries {
+		summaryOf[s.Id] = s
+	}
+	// 结算时的预占只在 Redis 中，下单时要让出其他用户的预占，自己的预占可以用掉
+	reserved, err := svc.holdRepo.Reserved(ctx, ids)
+	if err != nil {
+		return domain.Order{}, nil, err
+	}
+	mine, _, err := svc.holdRepo.UserHolds(ctx, uid)
+	if err != nil {
+		return domain.Order{}, nil, err
+	}
+	availableOf := make(map[uint64]int, len(ids))
+	for i, id := range ids {
+		availableOf[id] = max(summaryOf[id].Stock-reserved[i]+mine[id], 0)
+	}
+
+	order := domain.Order{
+		OrderNo: orderNo(uid),
+		UserId:  uid,
+		Status:  domain.StatusPending,
+		Address: addr,
+		Items:   make([]domain.OrderItem, 0, len(selected)),
+	}
+	unavailable := make([]domain.UnavailableLine, 0)
+	for _, item := range selected {
+		s := summaryOf[item.ProductID]
+		available := availableOf[item.ProductID]
+		if status := lineStatus(item, s, available); status != cdomain.LineAvailable {
+			unavailable = append(unavailable, domain.UnavailableLine{
+				ProductId: item.ProductID,
+				Quantity:  item.Quantity,
+				Stock:     s.Stock,
+				Available: available,
+				Status:    status,
+			})
+			continue
+		}
+
+		subtotal := roundPrice(s.Price * float64(item.Quantity))
+		order.Items = append(order.Items, domain.OrderItem{
+			ProductId:  item.ProductID,
+			MerchantId: s.MerchantId,
+			Name:       s.Name,
+			ImageUrl:   s.ImageUrl,
+			Price:      s.Price,
+			Quantity:   item.Quantity,
+			Subtotal:   subtotal,
+			Note:       item.Note,
+		})
+		order.ItemCount += item.Quantity
+		order.TotalAmount += subtotal
+	}
+	if len(unavailable) > 0 {
+		return domain.Order{}, unavailable, ErrProductsUnavailable
+	}
+	order.TotalAmount = roundPrice(order.TotalAmount)
+
+	lines := make([]pdomain.StockLine, 0, len(order.Items))
+	for _, item := range order.Items {
+		lines = append(lines, pdomain.StockLine{ProductId: item.ProductId, Quantity: item.Quantity})
+	}
+	err = svc.uow.Do(ctx, func(ctx context.Context) error {
+		if err := svc.productRepo.DeductStock(ctx, order.OrderNo, lines); err != nil {
+			return err
+		}
+		order.Id, err = svc.repo.CreateOrder(ctx, order, submitKey)
+		if err != nil {
+			return err
+		}
+		// 任务先于事务提交写入，提交后再写入的话，写入失败时订单永远不会超时取消；
+		// 回滚时取消失败也没关系，任务执行时找不到待支付的订单，不做任何事
+		payload, err := json.Marshal(expirePayload{OrderId: order.Id, OrderNo: order.OrderNo})
+		if err != nil {
+			return err
+		}
+		taskId, err := svc.sched.Schedule(ctx, delay.Task{
+			Id:      expireTaskId(order.OrderNo),
+			Type:    TaskExpireOrder,
+			Payload: string(payload),
+			RunAt:   time.Now().Add(payTimeout),
+		})
+		if err != nil {
+			return err
+		}
+		gormx.AfterRollback(ctx, func() {
+			_ = svc.sched.Cancel(context.Background(), taskId)
+		})
+		return nil
+	})
+	var short *prepo.StockShortageError
+	if errors.As(err, &short) {
+		// 读取商品信息之后库存被其他订单买走
+		for _, item := range order.Items {
+			if item.ProductId == short.ProductId {
+				unavailable = append(unavailable, domain.UnavailableLine{
+					ProductId: item.ProductId,
+					Quantity:  item.Quantity,
+					Stock:     summaryOf[item.ProductId].Stock,
+					Status:    cdomain.LineInsufficient,
+				})
+			}
+		}
+		return domain.Order{}, unavailable, ErrProductsUnavailable
+	}
+	if errors.Is(err, ErrDuplicateSubmit) {
+		// 并发的重复提交已经下单成功，本次扣减的库存随事务回滚，返回已创建的订单
+		order, err := svc.repo.FindBySubmitKey(ctx, uid, submitKey)
+		return order, nil, err
+	}
+	if err != nil {
+		return domain.Order{}, nil, err
+	}
+	order.CreateAt = time.Now().UnixMilli()
+
+	// 订单已经写入，清理购物车和预占失败不影响下单结果，预占会自动过期
+	_, _ = svc.cartRepo.DeleteItems(ctx, uid, ids)
+	_, _ = svc.holdRepo.Release(ctx, uid, ids)
+
+	for _, item := range order.Items {
+		svc.events.Publish(ctx, pdomain.ProductEvent{
+			Type:       pdomain.EventPurchase,
+			ProductId:  item.ProductId,
+			CategoryId: summaryOf[item.ProductId].CategoryId,
+			Count:      item.Quantity,
+		})
+	}
+
+	return order, nil, nil
+}
+
+func (svc *OrderService) GetOrders(ctx context.Context, uid uint64, page, size int) ([]domain.Order, error) {
+	return svc.repo.FindOrders(ctx, uid, (page-1)*size, size)
+}
+
+func (svc *OrderService) GetOrder(ctx context.Context, uid uint64, orderId string) (domain.Order, error) {
+	id, err := strconv.ParseUint(orderId, 10, 64)
+	if err != nil {
+		return domain.Order{}, ErrOrderNotFound
+	}
+
+	return svc.repo.FindOrder(ctx, uid, id)
+}
+
+// PayOrder 支付订单，待支付的订单改为已支付并取消超时取消任务；重复支付已支付的订单不做任何事，
+// 已经超时取消的订单返回 ErrOrderNotPending
+func (svc *OrderService) PayOrder(ctx context.Context, uid uint64, orderId string) error {
+	order, err := svc.GetOrder(ctx, uid, orderId)
+	if err != nil {
+		return err
+	}
+	if order.Status == domain.StatusPaid {
+		return nil
+	}
+
+	ok, err := svc.repo.UpdateStatus(ctx, order.Id, domain.StatusPending, domain.StatusPaid)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// 读取订单之后被超时取消或者被并发的请求支付
+		order, err = svc.GetOrder(ctx, uid, orderId)
+		if err != nil || order.Status == domain.StatusPaid {
+			return err
+		}
+		return ErrOrderNotPending
+	}
+
+	// 取消失败时任务照常执行，订单已不是待支付状态，不做任何事
+	_ = svc.sched.Cancel(ctx, expireTaskId(order.OrderNo))
+	return nil
+}
+
+// expireOrder 订单超时未支付时取消订单并归还库存，重复执行时订单已不是待支付状态，不做任何事
+func (svc *OrderService) expireOrder(ctx context.Context, task delay.Task) error {
+	var payload expirePayload
+	if err := json.Unmarshal([]byte(task.Payload), &payload); err != nil {
+		return err
+	}
+
+	return svc.uow.Do(ctx, func(ctx context.Context) error {
+		ok, err := svc.repo.UpdateStatus(ctx, payload.OrderId, domain.StatusPending, domain.StatusCancelled)
+		if err != nil || !ok {
+			return err
+		}
+		return svc.productRepo.RestoreStock(ctx, payload.OrderNo)
+	})
+}
+
+// address 用户的收货地址快照，addressId 为 0 时取默认地址
+func (svc *OrderService) address(ctx context.Context, uid, addressId uint64) (domain.Address, error) {
+	addresses, err := svc.userRepo.FindAllAddrById(ctx, uid)
+	if err != nil {
+		return domain.Address{}, err
+	}
+
+	for _, addr := range addresses {
+		if addr.Id == addressId || (addressId == 0 && addr.IsDefault) {
+			return domain.Address{
+				AddressId: addr.Id,
+				Street:    addr.Street,
+				City:      addr.City,
+				State:     addr.State,
+				ZipCode:   addr.ZipCode,
+				Country:   addr.Country,
+			}, nil
+		}
+	}
+	return domain.Address{}, ErrAddressNotFound
+}
+
+// lineStatus 购物车商品当前能否购买，商品不存在时 s 为零值，按已删除处理；available 为扣除其他用户预占后的可购买数量
+func lineStatus(item cdomain.CartItem, s pdomain.ProductSummary, available int) string {
+	switch {
+	case s.Id == 0 || s.IsDeleted:
+		return cdomain.LineDeleted
+	case !s.IsActive:
+		return cdomain.LineOffList
+	case s.Stock <= 0:
+		return cdomain.LineOutOfStock
+	case available < item.Quantity:
+		return cdomain.LineInsufficient
+	default:
+		return cdomain.LineAvailable
+	}
+}
+
+// expireTaskId 订单超时取消任务的 Id，按订单号生成，支付时据此取消
+func expireTaskId(orderNo string) string {
+	return TaskExpireOrder + ":" + orderNo
+}
+
+// orderNo 下单时间加用户 ID 后六位和四位随机数，同一用户同一秒内重复的概率可以忽略
+func orderNo(uid uint64) string {
+	return fmt.Sprintf("%s%06d%04d", time.Now().Format("20060102150405"), uid%1000000, rand.IntN(10000))
+}
+
+// roundPrice 保留两位小数，避免浮点误差
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
@@ -0,0 +1,5 @@
+package order
+
+import "mall/internal/order/web"
+
+type Handler = web.OrderHandler // 暴露出去给 ioc 使用
@@ -0,0 +1,135 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth"
+	"mall/internal/order/service"
+	"mall/pkg/ginx"
+)
+
+type OrderHandler struct {
+	svc *service.OrderService
+}
+
+func NewOrderHandler(svc *service.OrderService) *OrderHandler {
+	return &OrderHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *OrderHandler) RegisterRoute(r *gin.Engine) {
+	group := r.Group("api/orders")
+	{
+		group.POST("/", ctl.CreateOrder())     // 用购物车中勾选的商品下单
+		group.GET("/", ctl.GetOrders())        // 我的订单
+		group.GET("/:id", ctl.GetOrder())      // 订单详情
+		group.POST("/:id/pay", ctl.PayOrder()) // 支付完成，尚未接入支付渠道，暂由客户端在支付成功后调用
+	}
+}
+
+func (ctl *OrderHandler) CreateOrder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			AddressId uint64 `json:"addressId"` // 为 0 时使用默认地址
+		}
+		var req Req
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("invalid request body")))
+			return
+		}
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		// 客户端重试时携带相同的幂等键，避免重复下单
+		submitKey := c.GetHeader("Idempotency-Key")
+		if len(submitKey) > 64 {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("idempotency key is too long")))
+			return
+		}
+
+		order, unavailable, err := ctl.svc.CreateOrder(c.Request.Context(), claim.Id, req.AddressId, submitKey)
+		if errors.Is(err, service.ErrProductsUnavailable) {
+			c.JSON(http.StatusConflict, ginx.GetResponse(ginx.WithStatus(http.StatusConflict), ginx.WithMsg(err.Error()), ginx.WithData(unavailable)))
+			return
+		}
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("create order successfully"), ginx.WithData(order)))
+	}
+}
+
+func (ctl *OrderHandler) GetOrders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		page, size := ginx.Pagination(c)
+		orders, err := ctl.svc.GetOrders(c.Request.Context(), claim.Id, page, size)
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(orders)))
+	}
+}
+
+func (ctl *OrderHandler) GetOrder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		order, err := ctl.svc.GetOrder(c.Request.Context(), claim.Id, c.Param("id"))
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(order)))
+	}
+}
+
+func (ctl *OrderHandler) PayOrder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.svc.PayOrder(c.Request.Context(), claim.Id, c.Param("id"))
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("pay order successfully")))
+	}
+}
+
+func writeErr(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrNothingSelected):
+		c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg(err.Error())))
+	case errors.Is(err, service.ErrAddressNotFound):
+		c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("address not found")))
+	case errors.Is(err, service.ErrOrderNotPending):
+		c.JSON(http.StatusConflict, ginx.GetResponse(ginx.WithStatus(http.StatusConflict), ginx.WithMsg(err.Error())))
+	case errors.Is(err, service.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("order not found")))
+	default:
+		c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+//go:build wireinject
+
+package order
+
+import (
+	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"mall/internal/cart"
+	"mall/internal/order/repository"
+	"mall/internal/order/repository/dao"
+	"mall/internal/order/service"
+	"mall/internal/order/web"
+	"mall/internal/product"
+	pservice "mall/internal/product/service"
+	"mall/internal/user"
+	"mall/pkg/delay"
+	"mall/pkg/gormx"
+	"mall/pkg/logger"
+)
+
+func InitOrderHandler(cartRepo cart.Repository, db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache,
+	hooks []pservice.EventHook, l logger.Logger, sched *delay.Scheduler) *web.OrderHandler {
+	wire.Build(
+		dao.NewOrderDao,
+		repository.NewOrderRepository,
+
+		cart.NewHoldRepository,
+		user.NewUserRepository,
+		product.NewProductRepository,
+		pservice.NewEventPublisher,
+		gormx.NewUnitOfWork,
+
+		service.NewOrderService,
+		web.NewOrderHandler,
+	)
+	return new(web.OrderHandler)
+}
@@ -2,11 +2,18 @@ package domain
 
 type Product struct {
 	Id          uint64
+	MerchantId  uint64
+	Sku         string
 	Name        string
 	Description string
 	Price       float64
 	Stock       int
 	CategoryId  uint64
+	Version     int64
+	ListAt      int64   // 定时上架时间
+	DelistAt    int64   // 定时下架时间
+	RatingAvg   float64 // 平均星级，保留一位小数
+	RatingCount int64   // 评价数量
 }
 
 type ProductAttribute struct {
@@ -27,6 +34,7 @@ type ProductImage struct {
 	ProductId uint64
 	ImageUrl  string
 	IsPrimary bool
+	Sort      int
 }
 
 type ProductDetail struct {
@@ -43,3 +51,199 @@ type ProductApproximate struct {
 	Price       float64
 	ImageUrl    string // 主要图片
 }
+
+// ProductUpdate 商品修改内容，nil 表示该字段不修改
+type ProductUpdate struct {
+	Id          uint64
+	OperatorId  uint64
+	Version     int64 // 客户端持有的版本号
+	Name        *string
+	Description *string
+	Price       *float64
+	Stock       *int
+	CategoryId  *uint64
+	Attributes  []ProductAttribute
+	Images      []string
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type ProductHistory struct {
+	Id         uint64        `json:"id"`
+	ProductId  uint64        `json:"productId"`
+	OperatorId uint64        `json:"operatorId"`
+	Version    int64         `json:"version"`
+	Changes    []FieldChange `json:"changes"`
+	CreateAt   int64         `json:"createAt"`
+}
+
+// DeletedProduct 回收站中的商品
+type DeletedProduct struct {
+	Id        uint64  `json:"id"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	DeletedAt int64   `json:"deletedAt"`
+	PurgeAt   int64   `json:"purgeAt"` // 超过该时间将被彻底删除
+}
+
+// UploadedImage 上传后的图片，Thumbnails 的 key 为缩略图最长边的像素数
+type UploadedImage struct {
+	Key         string         `json:"key"`
+	Url         string         `json:"url"`
+	Hash        string         `json:"hash"`
+	ContentType string         `json:"contentType"`
+	Size        int            `json:"size"`
+	Thumbnails  map[int]string `json:"thumbnails"`
+}
+
+const (
+	ImportStatusRunning  = "running"
+	ImportStatusFinished = "finished"
+	ImportStatusFailed   = "failed"
+)
+
+// ImportJob 批量导入任务的进度
+type ImportJob struct {
+	Id        string `json:"id"`
+	UserId    uint64 `json:"userId"`
+	Format    string `json:"format"`
+	DryRun    bool   `json:"dryRun"`
+	Status    string `json:"status"`
+	Total     int    `json:"total"`
+	Processed int    `json:"processed"`
+	Created   int    `json:"created"`
+	Updated   int    `json:"updated"`
+	Failed    int    `json:"failed"`
+	Error     string `json:"error,omitempty"` // 任务整体失败的原因
+	HasReport bool   `json:"hasReport"`       // 是否有错误报告，只能通过任务所属商家的接口下载
+	CreateAt  int64  `json:"createAt"`
+	FinishAt  int64  `json:"finishAt,omitempty"`
+}
+
+// ImportRow 导入文件中的一行商品
+type ImportRow struct {
+	Line        int
+	Sku         string
+	Name        string
+	Description string
+	Price       float64
+	Stock       int
+	CategoryId  uint64
+	Images      []string
+	Attributes  []ProductAttribute
+}
+
+type ImportError struct {
+	Line  int
+	Sku   string
+	Error string
+}
+
+const (
+	MerchantProductOnList     = "onlist"     // 已上架
+	MerchantProductOffList    = "offlist"    // 已下架
+	MerchantProductOutOfStock = "outofstock" // 无库存
+)
+
+// MerchantProduct 商家后台商品列表中的一项
+type MerchantProduct struct {
+	Id       uint64  `json:"id"`
+	Sku      string  `json:"sku"`
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Stock    int     `json:"stock"`
+	IsActive bool    `json:"isActive"`
+	ImageUrl string  `json:"imageUrl"` // 主图
+	ListAt   int64   `json:"listAt"`
+	DelistAt int64   `json:"delistAt"`
+	UpdateAt int64   `json:"updateAt"`
+}
+
+// MerchantProductCounts 商家各状态的商品数量，不含回收站
+type MerchantProductCounts struct {
+	Total      int64 `json:"total"`
+	OnList     int64 `json:"onList"`
+	OffList    int64 `json:"offList"`
+	OutOfStock int64 `json:"outOfStock"`
+}
+
+// ProductSummary 商品的简要信息，供购物车、收藏夹等批量展示使用，包含已下架和已删除的商品
+type ProductSummary struct {
+	Id         uint64  `json:"id"`
+	MerchantId uint64  `json:"-"`
+	CategoryId uint64  `json:"categoryId"`
+	Name       string  `json:"name"`
+	Price      float64 `json:"price"`
+	Stock      int     `json:"stock"`
+	ImageUrl   string  `json:"imageUrl"` // 主图
+	IsActive   bool    `json:"isActive"`
+	IsDeleted  bool    `json:"isDeleted"`
+}
+
+// StockLine 一次扣减或归还中一个商品的件数
+type StockLine struct {
+	ProductId uint64
+	Quantity  int
+}
+
+// ViewedProduct 浏览记录中的一项
+type ViewedProduct struct {
+	ProductId uint64  `json:"productId"`
+	ViewAt    int64   `json:"viewAt"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	ImageUrl  string  `json:"imageUrl"`
+	Available bool    `json:"available"` // 商品仍在上架中
+}
+
+const (
+	EventView      = "view"
+	EventAddToCart = "cart"
+	EventPurchase  = "purchase"
+	EventPurge     = "purge" // 商品被彻底删除，由清理回收站的任务发出
+)
+
+// ProductEvent 用户对商品的行为，由商品详情、购物车、下单等环节发出
+type ProductEvent struct {
+	Type       string
+	ProductId  uint64
+	CategoryId uint64
+	Count      int // 加购或购买的件数，浏览为 1
+}
+
+const (
+	AttributeEnum   = "enum"
+	AttributeNumber = "number"
+	AttributeText   = "text"
+)
+
+// AttributeTemplate 类目下的一项属性定义，该类目商品的属性名和取值按它约束
+type AttributeTemplate struct {
+	Id         uint64   `json:"id"`
+	CategoryId uint64   `json:"categoryId"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`              // enum、number 或 text
+	Options    []string `json:"options,omitempty"` // enum 的可选值
+	Unit       string   `json:"unit,omitempty"`    // number 的单位，如 kg、英寸
+	Required   bool     `json:"required"`
+	Filterable bool     `json:"filterable"` // 可以在搜索中按它筛选
+}
+
+// AttributeFilter 搜索时的属性筛选，Values 中任一个匹配即可，Min、Max 只用于 number
+type AttributeFilter struct {
+	Name   string
+	Values []string
+	Min    *float64
+	Max    *float64
+}
+
+// ProductSearch 商品搜索条件，按属性筛选时必须指定类目
+type ProductSearch struct {
+	Name       string
+	CategoryId uint64
+	Attributes []AttributeFilter
+}
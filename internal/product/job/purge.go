@@ -0,0 +1,53 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"mall/internal/product/domain"
+	"mall/internal/product/service"
+	"mall/pkg/logger"
+)
+
+// PurgeJob 定期清理回收站中超过保留期的商品，删除后发出事件，由购物车等模块清理各自的数据
+type PurgeJob struct {
+	svc      *service.ProductService
+	events   *service.EventPublisher
+	l        logger.Logger
+	interval time.Duration
+}
+
+func NewPurgeJob(svc *service.ProductService, events *service.EventPublisher, l logger.Logger) *PurgeJob {
+	return &PurgeJob{
+		svc:      svc,
+		events:   events,
+		l:        l,
+		interval: time.Hour,
+	}
+}
+
+func (j *PurgeJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// 彻底删除是幂等的，多个实例同时执行也没有问题
+			ids, err := j.svc.PurgeTrash(ctx)
+			// 出错前已经删除的商品也要通知
+			for _, id := range ids {
+				j.events.Publish(ctx, domain.ProductEvent{Type: domain.EventPurge, ProductId: id})
+			}
+			if err != nil {
+				j.l.Error("清理回收站失败", logger.Error(err))
+				continue
+			}
+			if len(ids) > 0 {
+				j.l.Info("清理回收站成功", logger.Field{Key: "count", Val: len(ids)})
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mall/internal/product/service"
+	"mall/pkg/logger"
+	"mall/pkg/redisx"
+)
+
+const (
+	scheduleLockKey = "product:schedule:lock"
+	// 锁的过期时间短于执行间隔，执行期间持续续期，实例宕机后锁很快释放
+	scheduleLockTTL = time.Second * 10
+)
+
+// ScheduleJob 定期执行到期的定时上下架
+// 上下架时间保存在数据库中，重启后会补上停机期间到期的任务；多实例通过 Redis 锁互斥
+type ScheduleJob struct {
+	svc      *service.ProductService
+	cmd      redis.Cmdable
+	l        logger.Logger
+	interval time.Duration
+}
+
+func NewScheduleJob(svc *service.ProductService, cmd redis.Cmdable, l logger.Logger) *ScheduleJob {
+	return &ScheduleJob{
+		svc:      svc,
+		cmd:      cmd,
+		l:        l,
+		interval: time.Second * 30,
+	}
+}
+
+func (j *ScheduleJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.run(ctx)
+		}
+	}
+}
+
+func (j *ScheduleJob) run(ctx context.Context) {
+	lock := redisx.NewLock(j.cmd, scheduleLockKey, scheduleLockTTL)
+	ok, err := lock.TryLock(ctx)
+	if err != nil {
+		j.l.Error("定时上下架:获取锁失败", logger.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := lock.Unlock(context.Background()); err != nil {
+			j.l.Warn("定时上下架:释放锁失败", logger.Error(err))
+		}
+	}()
+
+	// 续期失败时停止执行，避免和抢到锁的其他实例同时执行
+	ctx, stop := lock.KeepAlive(ctx)
+	defer stop()
+
+	n, err := j.svc.ApplyDueSchedules(ctx)
+	if err != nil {
+		j.l.Error("定时上下架:执行失败", logger.Error(err))
+		return
+	}
+	if n > 0 {
+		j.l.Info("定时上下架:执行成功", logger.Field{Key: "count", Val: n})
+	}
+}
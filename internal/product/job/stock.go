@@ -0,0 +1,67 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mall/internal/product/service"
+	"mall/pkg/logger"
+	"mall/pkg/redisx"
+)
+
+const flashStockLockKey = "product:stock:sync:lock"
+
+// FlashStockJob 定期用数据库库存校准秒杀商品在 Redis 中的预扣库存，清理过期的在途预扣；
+// 多实例通过 Redis 锁互斥
+type FlashStockJob struct {
+	svc      *service.ProductService
+	cmd      redis.Cmdable
+	l        logger.Logger
+	interval time.Duration
+}
+
+func NewFlashStockJob(svc *service.ProductService, cmd redis.Cmdable, l logger.Logger) *FlashStockJob {
+	return &FlashStockJob{
+		svc:      svc,
+		cmd:      cmd,
+		l:        l,
+		interval: time.Second * 10,
+	}
+}
+
+func (j *FlashStockJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.run(ctx)
+		}
+	}
+}
+
+func (j *FlashStockJob) run(ctx context.Context) {
+	lock := redisx.NewLock(j.cmd, flashStockLockKey, j.interval)
+	ok, err := lock.TryLock(ctx)
+	if err != nil {
+		j.l.Error("秒杀库存校准:获取锁失败", logger.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := lock.Unlock(context.Background()); err != nil {
+			j.l.Warn("秒杀库存校准:释放锁失败", logger.Error(err))
+		}
+	}()
+
+	if _, err := j.svc.SyncFlashStocks(ctx); err != nil {
+		j.l.Error("秒杀库存校准:执行失败", logger.Error(err))
+	}
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mall/internal/product/domain"
+)
+
+const (
+	// 每人最多保留的浏览记录数
+	maxViewHistory     = 50
+	viewHistoryExpired = time.Hour * 24 * 30
+)
+
+// ViewHistoryCache 浏览记录，每个访客一个 ZSET，member 为商品 id，score 为浏览时间
+// 重复浏览只更新时间，超出上限时淘汰最早的记录
+type ViewHistoryCache struct {
+	cmd redis.Cmdable
+}
+
+func NewViewHistoryCache(cmd redis.Cmdable) *ViewHistoryCache {
+	return &ViewHistoryCache{
+		cmd: cmd,
+	}
+}
+
+func (cache *ViewHistoryCache) Add(ctx context.Context, key string, productId uint64, at time.Time) error {
+	_, err := cache.cmd.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, redis.Z{Score: float64(at.UnixMilli()), Member: productId})
+		pipe.ZRemRangeByRank(ctx, key, 0, -maxViewHistory-1)
+		pipe.Expire(ctx, key, viewHistoryExpired)
+		return nil
+	})
+	return err
+}
+
+// Get 按浏览时间倒序返回
+func (cache *ViewHistoryCache) Get(ctx context.Context, key string) ([]domain.ViewedProduct, error) {
+	zs, err := cache.cmd.ZRevRangeWithScores(ctx, key, 0, maxViewHistory-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.ViewedProduct, 0, len(zs))
+	for _, z := range zs {
+		id, err := strconv.ParseUint(z.Member.(string), 10, 64)
+		if err != nil {
+			continue
+		}
+		res = append(res, domain.ViewedProduct{
+			ProductId: id,
+			ViewAt:    int64(z.Score),
+		})
+	}
+	return res, nil
+}
+
+func (cache *ViewHistoryCache) Del(ctx context.Context, key string) error {
+	return cache.cmd.Del(ctx, key).Err()
+}
+
+// Merge 把 from 的记录合并进 to 并删除 from，同一商品保留较晚的浏览时间
+func (cache *ViewHistoryCache) Merge(ctx context.Context, from, to string) error {
+	_, err := cache.cmd.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZUnionStore(ctx, to, &redis.ZStore{
+			Keys:      []string{to, from},
+			Aggregate: "MAX",
+		})
+		pipe.ZRemRangeByRank(ctx, to, 0, -maxViewHistory-1)
+		pipe.Expire(ctx, to, viewHistoryExpired)
+		pipe.Del(ctx, from)
+		return nil
+	})
+	return err
+}
+
+// Scan 遍历所有访客的浏览记录，每个访客回调一次，ids 按浏览时间倒序
+func (cache *ViewHistoryCache) Scan(ctx context.Context, fn func(ids []uint64) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := cache.cmd.Scan(ctx, cursor, "product:viewed:*", 500).Result()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			views, err := cache.Get(ctx, key)
+			if err != nil {
+				return err
+			}
+			if len(views) == 0 {
+				continue
+			}
+			ids := make([]uint64, 0, len(views))
+			for _, v := range views {
+				ids = append(ids, v.ProductId)
+			}
+			if err := fn(ids); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mall/internal/product/domain"
+)
+
+var ErrImportJobNotFound = errors.New("import job not found")
+
+// 导入任务和错误报告保留一天
+const importJobTTL = time.Hour * 24
+
+// ImportJobCache 保存导入任务的进度和错误报告，多实例下任意实例都能查询
+type ImportJobCache struct {
+	cmd redis.Cmdable
+}
+
+func NewImportJobCache(cmd redis.Cmdable) *ImportJobCache {
+	return &ImportJobCache{
+		cmd: cmd,
+	}
+}
+
+func (cache *ImportJobCache) Set(ctx context.Context, job domain.ImportJob) error {
+	val, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	return cache.cmd.Set(ctx, cache.key(job.Id), val, importJobTTL).Err()
+}
+
+func (cache *ImportJobCache) Get(ctx context.Context, id string) (domain.ImportJob, error) {
+	val, err := cache.cmd.Get(ctx, cache.key(id)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return domain.ImportJob{}, ErrImportJobNotFound
+		}
+		return domain.ImportJob{}, err
+	}
+
+	var job domain.ImportJob
+	err = json.Unmarshal([]byte(val), &job)
+	return job, err
+}
+
+func (cache *ImportJobCache) SetReport(ctx context.Context, id string, report []byte) error {
+	return cache.cmd.Set(ctx, cache.reportKey(id), report, importJobTTL).Err()
+}
+
+func (cache *ImportJobCache) GetReport(ctx context.Context, id string) ([]byte, error) {
+	val, err := cache.cmd.Get(ctx, cache.reportKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrImportJobNotFound
+	}
+
+	return val, err
+}
+
+func (cache *ImportJobCache) reportKey(id string) string {
+	return fmt.Sprintf("product:import:%s:report", id)
+}
+
+func (cache *ImportJobCache) key(id string) string {
+	return fmt.Sprintf("product:import:%s", id)
+}
@@ -0,0 +1,33 @@
+-- 秒杀商品在 Redis 中预扣库存，全部满足时才扣减
+-- KEYS 每个商品两个 key：库存 hash 和在途扣减的过期时间 zset
+-- ARGV: 业务单号，在途扣减的过期时间（毫秒），之后为每个商品的 id 和数量
+-- 返回 0 表示成功，否则返回库存不足的商品 id
+local biz = ARGV[1]
+local field = "b:" .. biz
+local n = #KEYS / 2
+
+-- 不在 Redis 中的商品不是秒杀商品，由数据库扣减兜底
+for i = 1, n do
+    local stockKey = KEYS[2 * i - 1]
+    if redis.call("exists", stockKey) == 1 then
+        if redis.call("hexists", stockKey, field) == 1 then
+            return 0
+        end
+        local stock = tonumber(redis.call("hget", stockKey, "stock") or "0")
+        if stock < tonumber(ARGV[2 * i + 2]) then
+            return ARGV[2 * i + 1]
+        end
+    end
+end
+
+for i = 1, n do
+    local stockKey, inflightKey = KEYS[2 * i - 1], KEYS[2 * i]
+    if redis.call("exists", stockKey) == 1 then
+        local q = tonumber(ARGV[2 * i + 2])
+        redis.call("hincrby", stockKey, "stock", -q)
+        redis.call("hincrby", stockKey, "inflight", q)
+        redis.call("hset", stockKey, field, q)
+        redis.call("zadd", inflightKey, ARGV[2], biz)
+    end
+end
+return 0
@@ -0,0 +1,20 @@
+-- 结束在途扣减：数据库扣减成功时确认，失败时把预扣的库存还回去
+-- KEYS 每个商品两个 key：库存 hash 和在途扣减的过期时间 zset
+-- ARGV: 业务单号，是否归还（1 归还）
+local biz = ARGV[1]
+local field = "b:" .. biz
+local giveBack = ARGV[2] == "1"
+
+for i = 1, #KEYS / 2 do
+    local stockKey, inflightKey = KEYS[2 * i - 1], KEYS[2 * i]
+    local q = redis.call("hget", stockKey, field)
+    if q then
+        redis.call("hdel", stockKey, field)
+        redis.call("hincrby", stockKey, "inflight", -tonumber(q))
+        if giveBack then
+            redis.call("hincrby", stockKey, "stock", tonumber(q))
+        end
+    end
+    redis.call("zrem", inflightKey, biz)
+end
+return 0
@@ -0,0 +1,23 @@
+-- 用数据库库存校准秒杀商品在 Redis 中的库存：Redis 库存 = 数据库库存 - 在途扣减
+-- KEYS[1] 库存 hash，KEYS[2] 在途扣减的过期时间 zset
+-- ARGV: 数据库库存，当前时间（毫秒），key 不存在时是否创建（1 创建）
+-- 返回校准后的库存，key 不存在且不创建时返回 -1
+if redis.call("exists", KEYS[1]) == 0 and ARGV[3] ~= "1" then
+    return -1
+end
+
+-- 过期的在途扣减视为请求已经结束：提交了的已经体现在数据库库存中，没提交的不再占用
+local expired = redis.call("zrangebyscore", KEYS[2], "-inf", ARGV[2])
+for _, biz in ipairs(expired) do
+    local q = redis.call("hget", KEYS[1], "b:" .. biz)
+    if q then
+        redis.call("hdel", KEYS[1], "b:" .. biz)
+        redis.call("hincrby", KEYS[1], "inflight", -tonumber(q))
+    end
+    redis.call("zrem", KEYS[2], biz)
+end
+
+local inflight = tonumber(redis.call("hget", KEYS[1], "inflight") or "0")
+local stock = math.max(tonumber(ARGV[1]) - inflight, 0)
+redis.call("hset", KEYS[1], "stock", stock, "inflight", inflight)
+return stock
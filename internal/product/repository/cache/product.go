@@ -2,56 +2,44 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 
 	"mall/internal/product/domain"
-)
-
-var (
-	ErrProductNotFound = errors.New("product not found")
+	"mall/internal/product/repository/dao"
+	"mall/pkg/cachex"
 )
 
 type ProductCache struct {
-	cmd redis.Cmdable
+	detail *cachex.Cache[domain.ProductDetail]
 }
 
 func NewProductCache(cmd redis.Cmdable) *ProductCache {
 	return &ProductCache{
-		cmd: cmd,
+		detail: cachex.New[domain.ProductDetail](cmd, "product:detail",
+			cachex.WithTTL(time.Minute*5),
+			cachex.WithJitter(0.2),
+			// 不存在和已下架的商品也缓存，避免请求穿透到数据库
+			cachex.WithEmpty(time.Minute, dao.ErrProductNotFound, dao.ErrProductNotOnList),
+			cachex.WithLocal(1000, time.Second*30),
+		),
 	}
 }
 
-func (cache *ProductCache) SetProduct(ctx context.Context, id uint64, productDetail domain.ProductDetail) error {
-	key := cache.key(id)
-
-	val, err := json.Marshal(productDetail)
-	if err != nil {
-		return err
-	}
-
-	return cache.cmd.Set(ctx, key, string(val), time.Minute*5).Err()
+// GetProduct 读取商品详情，缓存未命中时通过 load 回源
+func (cache *ProductCache) GetProduct(ctx context.Context, id uint64, load func(ctx context.Context) (domain.ProductDetail, error)) (domain.ProductDetail, error) {
+	return cache.detail.Get(ctx, cache.key(id), load)
 }
 
-func (cache *ProductCache) GetProduct(ctx context.Context, id uint64) (domain.ProductDetail, error) {
-	key := cache.key(id)
-
-	val, err := cache.cmd.Get(ctx, key).Result()
-	if err != nil {
-		if errors.Is(err, redis.Nil) { // redis返回的未命中错误
-			return domain.ProductDetail{}, ErrProductNotFound // 定义此错误
-		}
-		return domain.ProductDetail{}, err
+func (cache *ProductCache) DelProduct(ctx context.Context, ids ...uint64) error {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, cache.key(id))
 	}
 
-	var detail domain.ProductDetail
-	err = json.Unmarshal([]byte(val), &detail)
-
-	return detail, err
+	return cache.detail.Del(ctx, keys...)
 }
 
 func (cache *ProductCache) key(id uint64) string {
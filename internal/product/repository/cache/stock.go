@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mall/internal/product/domain"
+)
+
+//go:embed lua/stock_deduct.lua
+var luaStockDeduct string
+
+//go:embed lua/stock_settle.lua
+var luaStockSettle string
+
+//go:embed lua/stock_sync.lua
+var luaStockSync string
+
+const flashStockKey = "product:stock:flash"
+
+// StockCache 秒杀商品的库存预扣。开启秒杀的商品在 Redis 中保存一份库存，下单先在 Redis 中扣减，
+// 库存不足的请求不再访问数据库；每次预扣在数据库扣减结束前记为在途，定期用数据库库存校准
+type StockCache struct {
+	cmd redis.Cmdable
+}
+
+func NewStockCache(cmd redis.Cmdable) *StockCache {
+	return &StockCache{
+		cmd: cmd,
+	}
+}
+
+// Enable 开启秒杀，stock 为数据库中的库存
+func (cache *StockCache) Enable(ctx context.Context, productId uint64, stock int) error {
+	if err := cache.cmd.SAdd(ctx, flashStockKey, productId).Err(); err != nil {
+		return err
+	}
+
+	_, err := cache.sync(ctx, productId, stock, true)
+	return err
+}
+
+// Disable 关闭秒杀，之后只由数据库扣减
+func (cache *StockCache) Disable(ctx context.Context, productId uint64) error {
+	_, err := cache.cmd.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SRem(ctx, flashStockKey, productId)
+		pipe.Del(ctx, cache.stockKey(productId), cache.inflightKey(productId))
+		return nil
+	})
+	return err
+}
+
+// FlashProducts 开启了秒杀的商品
+func (cache *StockCache) FlashProducts(ctx context.Context) ([]uint64, error) {
+	members, err := cache.cmd.SMembers(ctx, flashStockKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(members))
+	for _, m := range members {
+		if id, err := strconv.ParseUint(m, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Deduct 预扣库存，全部满足时返回 0，否则一个都不扣并返回库存不足的商品；
+// 没有开启秒杀的商品不检查。同一业务单号在确认或归还前重复预扣只扣一次，ttl 后未结束的预扣由 Sync 清理
+func (cache *StockCache) Deduct(ctx context.Context, bizKey string, lines []domain.StockLine, ttl time.Duration) (uint64, error) {
+	keys := make([]string, 0, len(lines)*2)
+	args := make([]any, 0, len(lines)*2+2)
+	args = append(args, bizKey, time.Now().Add(ttl).UnixMilli())
+	for _, line := range lines {
+		keys = append(keys, cache.stockKey(line.ProductId), cache.inflightKey(line.ProductId))
+		args = append(args, line.ProductId, line.Quantity)
+	}
+
+	res, err := cache.cmd.Eval(ctx, luaStockDeduct, keys, args...).Result()
+	if err != nil {
+		return 0, err
+	}
+	short, _ := strconv.ParseUint(fmt.Sprint(res), 10, 64)
+	return short, nil
+}
+
+// Settle 结束预扣，giveBack 为 true 时把预扣的库存还回去
+func (cache *StockCache) Settle(ctx context.Context, bizKey string, productIds []uint64, giveBack bool) error {
+	keys := make([]string, 0, len(productIds)*2)
+	for _, id := range productIds {
+		keys = append(keys, cache.stockKey(id), cache.inflightKey(id))
+	}
+	flag := "0"
+	if giveBack {
+		flag = "1"
+	}
+
+	return cache.cmd.Eval(ctx, luaStockSettle, keys, bizKey, flag).Err()
+}
+
+// Sync 用数据库库存校准 Redis 中的库存，商品没有开启秒杀时不做任何事
+func (cache *StockCache) Sync(ctx context.Context, productId uint64, stock int) error {
+	_, err := cache.sync(ctx, productId, stock, false)
+	return err
+}
+
+func (cache *StockCache) sync(ctx context.Context, productId uint64, stock int, create bool) (int64, error) {
+	flag := "0"
+	if create {
+		flag = "1"
+	}
+
+	return cache.cmd.Eval(ctx, luaStockSync, []string{cache.stockKey(productId), cache.inflightKey(productId)},
+		stock, time.Now().UnixMilli(), flag).Int64()
+}
+
+func (cache *StockCache) stockKey(productId uint64) string {
+	return fmt.Sprintf("product:stock:%d", productId)
+}
+
+func (cache *StockCache) inflightKey(productId uint64) string {
+	return fmt.Sprintf("product:stock:inflight:%d", productId)
+}
@@ -0,0 +1,127 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mall/internal/product/domain"
+	"mall/pkg/gormx"
+)
+
+// ReplaceAttributeTemplates 整体替换类目的属性模板
+// 已有商品的属性不会随之校验，下次修改属性时才按新模板校验
+func (dao *ProductDao) ReplaceAttributeTemplates(ctx context.Context, categoryId uint64, templates []domain.AttributeTemplate) error {
+	return dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+
+		var category Category
+		err := db.Where("id = ?", categoryId).First(&category).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCategoryNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := db.Where("category_id = ?", categoryId).Delete(&AttributeTemplate{}).Error; err != nil {
+			return err
+		}
+		if len(templates) == 0 {
+			return nil
+		}
+
+		now := time.Now().UnixMilli()
+		rows := make([]AttributeTemplate, 0, len(templates))
+		for i, t := range templates {
+			var options string
+			if len(t.Options) > 0 {
+				bs, err := json.Marshal(t.Options)
+				if err != nil {
+					return err
+				}
+				options = string(bs)
+			}
+			rows = append(rows, AttributeTemplate{
+				CategoryId: categoryId,
+				Name:       t.Name,
+				Type:       t.Type,
+				Options:    options,
+				Unit:       t.Unit,
+				Required:   t.Required,
+				Filterable: t.Filterable,
+				Sort:       i,
+				CreateAt:   now,
+				UpdateAt:   now,
+			})
+		}
+		return db.Create(&rows).Error
+	})
+}
+
+func (dao *ProductDao) FindAttributeTemplates(ctx context.Context, categoryId uint64) ([]domain.AttributeTemplate, error) {
+	var rows []AttributeTemplate
+	err := dao.db.WithContext(ctx).Where("category_id = ?", categoryId).Order("sort, id").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.templateDaoToDomain(rows), nil
+}
+
+// FindAllAttributeTemplates 所有类目的属性模板，按类目分组
+func (dao *ProductDao) FindAllAttributeTemplates(ctx context.Context) (map[uint64][]domain.AttributeTemplate, error) {
+	var rows []AttributeTemplate
+	if err := dao.db.WithContext(ctx).Order("category_id, sort, id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64][]domain.AttributeTemplate)
+	for _, t := range dao.templateDaoToDomain(rows) {
+		res[t.CategoryId] = append(res[t.CategoryId], t)
+	}
+	return res, nil
+}
+
+// FindProductAttributes 商品当前的类目和属性，不要求商品已上架
+func (dao *ProductDao) FindProductAttributes(ctx context.Context, id uint64) (uint64, []domain.ProductAttribute, error) {
+	var pc ProductCategory
+	err := dao.db.WithContext(ctx).Where("product_id = ?", id).First(&pc).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil, ErrProductNotFound
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var attributes []ProductAttribute
+	if err := dao.db.WithContext(ctx).Where("product_id = ?", id).Order("id").Find(&attributes).Error; err != nil {
+		return 0, nil, err
+	}
+
+	return pc.CategoryID, dao.attributeDaoToDomain(attributes), nil
+}
+
+func (dao *ProductDao) templateDaoToDomain(rows []AttributeTemplate) []domain.AttributeTemplate {
+	res := make([]domain.AttributeTemplate, 0, len(rows))
+	for _, row := range rows {
+		var options []string
+		if row.Options != "" {
+			_ = json.Unmarshal([]byte(row.Options), &options)
+		}
+		res = append(res, domain.AttributeTemplate{
+			Id:         row.Id,
+			CategoryId: row.CategoryId,
+			Name:       row.Name,
+			Type:       row.Type,
+			Options:    options,
+			Unit:       row.Unit,
+			Required:   row.Required,
+			Filterable: row.Filterable,
+		})
+	}
+	return res
+}
@@ -0,0 +1,128 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"mall/internal/product/domain"
+)
+
+// FindProductBySku 按 SKU 查找商家未删除的商品，不要求上架
+func (dao *ProductDao) FindProductBySku(ctx context.Context, merchantId uint64, sku string) (domain.Product, error) {
+	var product Product
+	err := dao.db.WithContext(ctx).Where("merchant_id = ? AND sku = ? AND deleted_at = ?", merchantId, sku, 0).First(&product).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Product{}, ErrProductNotFound
+		}
+		return domain.Product{}, err
+	}
+
+	return dao.productDaoToDomain(product), nil
+}
+
+// FindProductsAfter 按 ID 顺序分批读取商家未删除的商品及其分类、属性、图片，用于导出
+func (dao *ProductDao) FindProductsAfter(ctx context.Context, merchantId, lastId uint64, limit int) ([]domain.ProductDetail, error) {
+	var products []Product
+	err := dao.db.WithContext(ctx).
+		Where("merchant_id = ? AND id > ? AND deleted_at = ?", merchantId, lastId, 0).
+		Order("id").
+		Limit(limit).
+		Find(&products).Error
+	if err != nil || len(products) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.Id)
+	}
+
+	var pcs []ProductCategory
+	if err := dao.db.WithContext(ctx).Where("product_id IN ?", ids).Find(&pcs).Error; err != nil {
+		return nil, err
+	}
+	var images []ProductImage
+	if err := dao.db.WithContext(ctx).Where("product_id IN ?", ids).Order("sort, id").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	var attributes []ProductAttribute
+	if err := dao.db.WithContext(ctx).Where("product_id IN ?", ids).Order("id").Find(&attributes).Error; err != nil {
+		return nil, err
+	}
+
+	categoryOf := make(map[uint64]uint64, len(pcs))
+	for _, pc := range pcs {
+		categoryOf[pc.ProductID] = pc.CategoryID
+	}
+	imagesOf := make(map[uint64][]ProductImage, len(products))
+	for _, img := range images {
+		imagesOf[img.ProductId] = append(imagesOf[img.ProductId], img)
+	}
+	attributesOf := make(map[uint64][]ProductAttribute, len(products))
+	for _, at := range attributes {
+		attributesOf[at.ProductId] = append(attributesOf[at.ProductId], at)
+	}
+
+	res := make([]domain.ProductDetail, 0, len(products))
+	for _, p := range products {
+		product := dao.productDaoToDomain(p)
+		product.CategoryId = categoryOf[p.Id]
+		res = append(res, domain.ProductDetail{
+			Product:    product,
+			Category:   domain.Category{ID: categoryOf[p.Id]},
+			Images:     dao.imageDaoToDomain(imagesOf[p.Id]),
+			Attributes: dao.attributeDaoToDomain(attributesOf[p.Id]),
+		})
+	}
+
+	return res, nil
+}
+
+// FindProductSummaries 批量读取商品简要信息，不存在的 id 会被忽略
+func (dao *ProductDao) FindProductSummaries(ctx context.Context, ids []uint64) ([]domain.ProductSummary, error) {
+	if len(ids) == 0 {
+		return []domain.ProductSummary{}, nil
+	}
+
+	var products []Product
+	if err := dao.db.WithContext(ctx).Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	var images []ProductImage
+	if err := dao.db.WithContext(ctx).Where("product_id IN ? AND is_primary = ?", ids, true).Find(&images).Error; err != nil {
+		return nil, err
+	}
+	var pcs []ProductCategory
+	if err := dao.db.WithContext(ctx).Where("product_id IN ?", ids).Find(&pcs).Error; err != nil {
+		return nil, err
+	}
+
+	imageOf := make(map[uint64]string, len(images))
+	for _, img := range images {
+		imageOf[img.ProductId] = img.ImageUrl
+	}
+	categoryOf := make(map[uint64]uint64, len(pcs))
+	for _, pc := range pcs {
+		categoryOf[pc.ProductID] = pc.CategoryID
+	}
+
+	res := make([]domain.ProductSummary, 0, len(products))
+	for _, p := range products {
+		res = append(res, domain.ProductSummary{
+			Id:         p.Id,
+			MerchantId: p.MerchantId,
+			CategoryId: categoryOf[p.Id],
+			Name:       p.Name,
+			Price:      p.Price,
+			Stock:      p.Stock,
+			ImageUrl:   imageOf[p.Id],
+			IsActive:   p.IsActive,
+			IsDeleted:  p.DeletedAt > 0,
+		})
+	}
+
+	return res, nil
+}
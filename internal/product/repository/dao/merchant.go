@@ -0,0 +1,90 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"mall/internal/product/domain"
+)
+
+// FindProductOwner 返回商品所属商家，包括回收站中的商品
+func (dao *ProductDao) FindProductOwner(ctx context.Context, id uint64) (uint64, error) {
+	var product Product
+	err := dao.db.WithContext(ctx).Select("merchant_id").Where("id = ?", id).First(&product).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrProductNotFound
+		}
+		return 0, err
+	}
+
+	return product.MerchantId, nil
+}
+
+// FindMerchantProducts 按状态分页查询商家未删除的商品，status 为空时返回全部
+func (dao *ProductDao) FindMerchantProducts(ctx context.Context, merchantId uint64, status string, offset, limit int) ([]domain.MerchantProduct, error) {
+	query := dao.db.WithContext(ctx).Where("merchant_id = ? AND deleted_at = ?", merchantId, 0)
+	switch status {
+	case domain.MerchantProductOnList:
+		query = query.Where("is_active = ?", true)
+	case domain.MerchantProductOffList:
+		query = query.Where("is_active = ?", false)
+	case domain.MerchantProductOutOfStock:
+		query = query.Where("stock <= ?", 0)
+	}
+
+	var products []Product
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return []domain.MerchantProduct{}, nil
+	}
+
+	ids := make([]uint64, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.Id)
+	}
+	var images []ProductImage
+	if err := dao.db.WithContext(ctx).Where("product_id IN ? AND is_primary = ?", ids, true).Find(&images).Error; err != nil {
+		return nil, err
+	}
+	imageOf := make(map[uint64]string, len(images))
+	for _, img := range images {
+		imageOf[img.ProductId] = img.ImageUrl
+	}
+
+	res := make([]domain.MerchantProduct, 0, len(products))
+	for _, p := range products {
+		res = append(res, domain.MerchantProduct{
+			Id:       p.Id,
+			Sku:      p.Sku.String,
+			Name:     p.Name,
+			Price:    p.Price,
+			Stock:    p.Stock,
+			IsActive: p.IsActive,
+			ImageUrl: imageOf[p.Id],
+			ListAt:   p.ListAt,
+			DelistAt: p.DelistAt,
+			UpdateAt: p.UpdateAt,
+		})
+	}
+
+	return res, nil
+}
+
+// CountMerchantProducts 统计商家各状态的商品数量
+func (dao *ProductDao) CountMerchantProducts(ctx context.Context, merchantId uint64) (domain.MerchantProductCounts, error) {
+	var counts domain.MerchantProductCounts
+	err := dao.db.WithContext(ctx).Model(&Product{}).
+		Select("COUNT(*) AS total, "+
+			"COALESCE(SUM(is_active = 1), 0) AS on_list, "+
+			"COALESCE(SUM(is_active = 0), 0) AS off_list, "+
+			"COALESCE(SUM(stock <= 0), 0) AS out_of_stock").
+		Where("merchant_id = ? AND deleted_at = ?", merchantId, 0).
+		Scan(&counts).Error
+
+	return counts, err
+}
@@ -1,25 +1,36 @@
 package dao
 
+import "database/sql"
+
 type Product struct {
-	Id          uint64 `gorm:"primaryKey,autoIncrement"`
-	Name        string `gorm:"not null"`
+	Id          uint64         `gorm:"primaryKey,autoIncrement"`
+	MerchantId  uint64         `gorm:"not null;default:0;uniqueIndex:uk_merchant_sku,priority:1"` // 所属商家，创建时取自登录用户
+	Sku         sql.NullString `gorm:"type:varchar(64);uniqueIndex:uk_merchant_sku,priority:2"`   // 商家 SKU 编码，同一商家内唯一，批量导入时按它更新
+	Name        string         `gorm:"not null"`
 	Description string
-	Price       float64            `gorm:"not null"`
-	Stock       int                `gorm:"not null"`
-	IsActive    bool               `gorm:"default:true,index"`
-	Quantity    int                `gorm:"not null"`
-	Attributes  []ProductAttribute `gorm:"type:json"` // 存储为 JSON 类型
+	Price       float64 `gorm:"not null"`
+	Stock       int     `gorm:"not null"`
+	IsActive    bool    `gorm:"default:true;index"`
+	Quantity    int     `gorm:"not null"`
+	Version     int64   `gorm:"not null;default:1"` // 乐观锁版本号
+	RatingSum   int64   `gorm:"not null;default:0"` // 已通过审核的评价星级之和
+	RatingCount int64   `gorm:"not null;default:0"` // 已通过审核的评价数量
 	CreateAt    int64
 	UpdateAt    int64
+	DeletedAt   int64 `gorm:"not null;default:0;index"` // 软删除时间，0 表示未删除
+	ListAt      int64 `gorm:"not null;default:0;index"` // 定时上架时间，0 表示未设置
+	DelistAt    int64 `gorm:"not null;default:0;index"` // 定时下架时间，0 表示未设置
 }
 
 type ProductImage struct {
 	Id        uint64 `gorm:"primaryKey,autoIncrement"`
-	ProductId uint64 `gorm:"not null"`      // 外键，关联到 Product 表
-	ImageUrl  string `gorm:"not null"`      // 图片的 URL
-	IsPrimary bool   `gorm:"default:false"` // 是否是主图
+	ProductId uint64 `gorm:"not null"`           // 外键，关联到 Product 表
+	ImageUrl  string `gorm:"not null"`           // 图片的 URL
+	IsPrimary bool   `gorm:"default:false"`      // 是否是主图
+	Sort      int    `gorm:"not null;default:0"` // 展示顺序，越小越靠前
 }
 
+// ProductAttribute 商品属性只保存在这张表中，详情展示和按属性筛选都以它为准
 type ProductAttribute struct {
 	Id        uint64 `gorm:"primaryKey,autoIncrement"`
 	ProductId uint64 // 外键
@@ -39,3 +50,47 @@ type ProductCategory struct {
 	ProductID  uint64 `gorm:"primaryKey,autoIncrement:false;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	CategoryID uint64 `gorm:"primaryKey,autoIncrement:false;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 }
+
+// AttributeTemplate 类目的属性模板，同一类目下属性名唯一
+type AttributeTemplate struct {
+	Id         uint64 `gorm:"primaryKey,autoIncrement"`
+	CategoryId uint64 `gorm:"not null;uniqueIndex:uk_category_name,priority:1"`
+	Name       string `gorm:"type:varchar(64);not null;uniqueIndex:uk_category_name,priority:2"`
+	Type       string `gorm:"type:varchar(16);not null"`
+	Options    string `gorm:"type:text"` // enum 的可选值，JSON 数组
+	Unit       string `gorm:"type:varchar(16)"`
+	Required   bool   `gorm:"not null;default:false"`
+	Filterable bool   `gorm:"not null;default:false"`
+	Sort       int    `gorm:"not null;default:0"` // 展示顺序，按提交时的顺序
+	CreateAt   int64
+	UpdateAt   int64
+}
+
+// ProductHistory 商品变更记录，只追加不修改
+type ProductHistory struct {
+	Id         uint64 `gorm:"primaryKey;autoIncrement"`
+	ProductId  uint64 `gorm:"not null;index"`
+	OperatorId uint64 `gorm:"not null"`  // 修改人
+	Version    int64  `gorm:"not null"`  // 修改后的版本号
+	Changes    string `gorm:"type:text"` // 变更字段，JSON 格式
+	CreateAt   int64
+}
+
+// IdempotencyKey 创建商品的幂等键，同一用户的同一个 key 只对应一个商品
+type IdempotencyKey struct {
+	Id        uint64 `gorm:"primaryKey;autoIncrement"`
+	UserId    uint64 `gorm:"not null;uniqueIndex:uk_user_biz_key"`
+	BizKey    string `gorm:"type:varchar(64);not null;uniqueIndex:uk_user_biz_key"`
+	ProductId uint64 `gorm:"not null"`
+	CreateAt  int64
+}
+
+// StockLog 按业务单号记录的库存扣减，同一单号只扣减一次、只归还一次
+type StockLog struct {
+	Id        uint64 `gorm:"primaryKey,autoIncrement"`
+	BizKey    string `gorm:"type:varchar(64);not null;uniqueIndex:uk_biz_product,priority:1"`
+	ProductId uint64 `gorm:"not null;uniqueIndex:uk_biz_product,priority:2"`
+	Quantity  int    `gorm:"not null"`
+	CreateAt  int64
+	RestoreAt int64 `gorm:"not null;default:0"` // 归还时间，0 表示未归还
+}
@@ -2,29 +2,46 @@ package dao
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"mall/internal/product/domain"
+	"mall/pkg/gormx"
 )
 
 var (
-	ErrCategoryNotFound      = errors.New("category does not exist")
-	ErrCategoryDuplicateName = errors.New("duplicate category")
-	ErrCategoriesNotFound    = errors.New("empty categories found")
-	ErrProductNotFound       = errors.New("product not found")
-	ErrProductNotOnList      = errors.New("product is not on list")
+	ErrCategoryNotFound       = errors.New("category does not exist")
+	ErrCategoryDuplicateName  = errors.New("duplicate category")
+	ErrCategoriesNotFound     = errors.New("empty categories found")
+	ErrProductNotFound        = errors.New("product not found")
+	ErrProductNotOnList       = errors.New("product is not on list")
+	ErrProductVersionConflict = errors.New("product version conflict")
+	ErrProductNotInTrash      = errors.New("product is not in trash")
+	ErrImageNotFound          = errors.New("image not found")
+	ErrImageMismatch          = errors.New("images do not match the product")
+	ErrProductDuplicateSku    = errors.New("duplicate product sku")
 )
 
+// 批量插入时每批的条数
+const batchSize = 100
+
 type ProductDao struct {
-	db *gorm.DB
+	db  *gorm.DB
+	uow *gormx.UnitOfWork
 }
 
 func NewProductDao(db *gorm.DB) *ProductDao {
 	return &ProductDao{
-		db: db,
+		db:  db,
+		uow: gormx.NewUnitOfWork(db),
 	}
 }
 
@@ -61,97 +78,192 @@ func (dao *ProductDao) AcquireAllCategory(ctx context.Context) ([]domain.Categor
 	return categories, nil
 }
 
-func (dao *ProductDao) InsertProductImage(ctx context.Context, productId uint64, imageUrl string, isPrimary bool) error {
-	// 插入商品图片
-	productImage := ProductImage{
-		ProductId: productId,
-		ImageUrl:  imageUrl,
-		IsPrimary: isPrimary,
+func (dao *ProductDao) InsertProductImages(ctx context.Context, productId uint64, imageUrls []string) error {
+	if len(imageUrls) == 0 {
+		return nil
 	}
 
-	if err := dao.db.WithContext(ctx).Create(&productImage).Error; err != nil {
-		return err
+	images := make([]ProductImage, 0, len(imageUrls))
+	for i, imageUrl := range imageUrls {
+		images = append(images, ProductImage{
+			ProductId: productId,
+			ImageUrl:  imageUrl,
+			IsPrimary: i == 0, // 默认第一张图片为主图，之后可单独设置
+			Sort:      i,
+		})
 	}
 
-	return nil
+	return gormx.DB(ctx, dao.db).CreateInBatches(images, batchSize).Error
 }
 
-func (dao *ProductDao) InsertProductAttribute(ctx context.Context, productId uint64, attribute domain.ProductAttribute) error {
-	// 在这里可以添加额外的逻辑，比如验证属性数据等
-	at := ProductAttribute{
-		ProductId: productId,
-		Name:      attribute.Name,
-		Value:     attribute.Value,
-	}
-	// 插入商品属性
-	if err := dao.db.WithContext(ctx).Create(&at).Error; err != nil {
-		return err
-	}
+// ReorderImages 按 imageIds 的顺序设置图片排序
+func (dao *ProductDao) ReorderImages(ctx context.Context, productId uint64, imageIds []uint64) error {
+	return dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
 
-	return nil
+		var ids []uint64
+		if err := db.Model(&ProductImage{}).Where("product_id = ?", productId).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if !sameIds(ids, imageIds) {
+			return ErrImageMismatch
+		}
+
+		for i, imageId := range imageIds {
+			err := db.Model(&ProductImage{}).
+				Where("id = ? AND product_id = ?", imageId, productId).
+				Update("sort", i).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
-func (dao *ProductDao) InsertProduct(ctx context.Context, pro domain.Product, attributes []domain.ProductAttribute, imageUrls []string) error {
-	// 检查分类是否存在
-	var category Category
-	if err := dao.db.WithContext(ctx).Where("id = ?", pro.CategoryId).First(&category).Error; err != nil {
-		return ErrCategoryNotFound
-	}
+// SetPrimaryImage 设置主图，同一商品只有一张主图
+func (dao *ProductDao) SetPrimaryImage(ctx context.Context, productId, imageId uint64) error {
+	return dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
 
-	// 插入商品
-	if err := dao.db.WithContext(ctx).Create(&pro).Error; err != nil {
-		return err
-	}
+		var img ProductImage
+		if err := db.Where("id = ? AND product_id = ?", imageId, productId).First(&img).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrImageNotFound
+			}
+			return err
+		}
 
-	// 插入关联记录
-	productCategory := ProductCategory{
-		ProductID:  pro.Id,         // 商品ID
-		CategoryID: pro.CategoryId, // 分类ID
-	}
+		err := db.Model(&ProductImage{}).
+			Where("product_id = ? AND id <> ?", productId, imageId).
+			Update("is_primary", false).Error
+		if err != nil {
+			return err
+		}
 
-	if err := dao.db.WithContext(ctx).Create(&productCategory).Error; err != nil {
-		return err
+		return db.Model(&ProductImage{}).Where("id = ?", imageId).Update("is_primary", true).Error
+	})
+}
+
+func (dao *ProductDao) InsertProductAttributes(ctx context.Context, productId uint64, attributes []domain.ProductAttribute) error {
+	if len(attributes) == 0 {
+		return nil
 	}
 
-	// 插入商品属性
+	ats := make([]ProductAttribute, 0, len(attributes))
 	for _, attr := range attributes {
-		if err := dao.InsertProductAttribute(ctx, pro.Id, attr); err != nil {
+		ats = append(ats, ProductAttribute{
+			ProductId: productId,
+			Name:      attr.Name,
+			Value:     attr.Value,
+		})
+	}
+
+	return gormx.DB(ctx, dao.db).CreateInBatches(ats, batchSize).Error
+}
+
+// InsertProduct 在一个事务中创建商品及其分类、属性、图片
+// idemKey 不为空时，同一用户重复提交相同的 key 只会创建一次，返回首次创建的商品 ID
+func (dao *ProductDao) InsertProduct(ctx context.Context, pro domain.Product, attributes []domain.ProductAttribute, imageUrls []string, uid uint64, idemKey string) (uint64, error) {
+	var productId uint64
+	err := dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+
+		if idemKey != "" {
+			var idem IdempotencyKey
+			err := db.Where("user_id = ? AND biz_key = ?", uid, idemKey).First(&idem).Error
+			if err == nil {
+				productId = idem.ProductId
+				return nil
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		// 检查分类是否存在
+		var category Category
+		if err := db.Where("id = ?", pro.CategoryId).First(&category).Error; err != nil {
+			return ErrCategoryNotFound
+		}
+
+		// 插入商品
+		product := dao.productDomainToDao(pro)
+		now := time.Now().UnixMilli()
+		product.CreateAt = now
+		product.UpdateAt = now
+		if err := db.Create(&product).Error; err != nil {
 			return err
 		}
-	}
+		productId = product.Id
 
-	// 插入商品图片
-	for _, imageUrl := range imageUrls {
-		isPrimary := imageUrl == imageUrls[0] // 第一张图片设置为主图
-		if err := dao.InsertProductImage(ctx, pro.Id, imageUrl, isPrimary); err != nil {
+		// 插入关联记录
+		if err := db.Create(&ProductCategory{ProductID: product.Id, CategoryID: pro.CategoryId}).Error; err != nil {
+			return err
+		}
+
+		if err := dao.InsertProductAttributes(ctx, product.Id, attributes); err != nil {
+			return err
+		}
+		if err := dao.InsertProductImages(ctx, product.Id, imageUrls); err != nil {
 			return err
 		}
+
+		if idemKey != "" {
+			return db.Create(&IdempotencyKey{
+				UserId:    uid,
+				BizKey:    idemKey,
+				ProductId: product.Id,
+				CreateAt:  now,
+			}).Error
+		}
+
+		return nil
+	})
+	if err != nil && gormx.IsUniqueConflict(err) {
+		if idemKey != "" {
+			// 并发的重复请求已经创建成功，返回已创建的商品
+			var idem IdempotencyKey
+			if e := dao.db.WithContext(ctx).Where("user_id = ? AND biz_key = ?", uid, idemKey).First(&idem).Error; e == nil {
+				return idem.ProductId, nil
+			}
+		}
+		// 回收站中的商品也占用 SKU
+		if pro.Sku != "" {
+			var n int64
+			e := dao.db.WithContext(ctx).Model(&Product{}).Where("merchant_id = ? AND sku = ?", pro.MerchantId, pro.Sku).Count(&n).Error
+			if e == nil && n > 0 {
+				return 0, ErrProductDuplicateSku
+			}
+		}
 	}
 
-	return nil
+	return productId, err
 }
 
-func (dao *ProductDao) UpdateProductSock(ctx context.Context, productId uint64, quantity int) error {
-	// 查找商品
-	var product Product
-	if err := dao.db.WithContext(ctx).Where("id = ?", productId).First(&product).Error; err != nil {
-		return ErrProductNotFound
+// AdjustRating 调整评分汇总，加入 ctx 中的事务
+func (dao *ProductDao) AdjustRating(ctx context.Context, id uint64, sumDelta, countDelta int64) error {
+	res := gormx.DB(ctx, dao.db).Model(&Product{}).Where("id = ?", id).Updates(map[string]any{
+		"rating_sum":   gorm.Expr("rating_sum + ?", sumDelta),
+		"rating_count": gorm.Expr("rating_count + ?", countDelta),
+	})
+	if res.Error != nil {
+		return res.Error
 	}
-
-	// 更新库存
-	product.Stock += quantity
-	if err := dao.db.WithContext(ctx).Save(&product).Error; err != nil {
-		return err
+	if res.RowsAffected == 0 {
+		return ErrProductNotFound
 	}
 
 	return nil
 }
 
-func (dao *ProductDao) SearchProducts(ctx context.Context, name string) ([]domain.ProductApproximate, error) {
+func (dao *ProductDao) SearchProducts(ctx context.Context, search domain.ProductSearch) ([]domain.ProductApproximate, error) {
 	var products []Product
 
-	var categoryId uint64
-	if name != "" {
+	name := search.Name
+	categoryId := search.CategoryId
+	if name != "" && categoryId == 0 {
 		var category Category
 		if err := dao.db.WithContext(ctx).Where("name = ?", name).First(&category).Error; err != nil {
 			return nil, err // 如果分类未找到，可以返回错误
@@ -160,13 +272,31 @@ func (dao *ProductDao) SearchProducts(ctx context.Context, name string) ([]domai
 	}
 
 	// 构建查询
-	query := dao.db.WithContext(ctx).Model(&Product{})
+	query := dao.db.WithContext(ctx).Model(&Product{}).Where("product.deleted_at = ?", 0)
 	if name != "" {
-		query = query.Where("name LIKE ? AND is_active = ?", "%"+name+"%", true)
+		query = query.Where("product.name LIKE ? AND product.is_active = ?", "%"+name+"%", true)
 	}
 	if categoryId > 0 {
-		query = query.Joins("JOIN product_category pc ON pc.product_id = products.id").
-			Where("pc.category_id = ? AND products.is_active = ?", categoryId, true)
+		query = query.Joins("JOIN product_category pc ON pc.product_id = product.id").
+			Where("pc.category_id = ? AND product.is_active = ?", categoryId, true)
+	}
+	// 每个属性条件都要满足，同一属性的多个取值满足其一即可
+	for _, f := range search.Attributes {
+		cond := "EXISTS (SELECT 1 FROM product_attribute pa WHERE pa.product_id = product.id AND pa.name = ?"
+		args := []any{f.Name}
+		if len(f.Values) > 0 {
+			cond += " AND pa.value IN ?"
+			args = append(args, f.Values)
+		}
+		if f.Min != nil {
+			cond += " AND CAST(pa.value AS DECIMAL(20, 6)) >= ?"
+			args = append(args, *f.Min)
+		}
+		if f.Max != nil {
+			cond += " AND CAST(pa.value AS DECIMAL(20, 6)) <= ?"
+			args = append(args, *f.Max)
+		}
+		query = query.Where(cond+")", args...)
 	}
 
 	if err := query.Find(&products).Error; err != nil {
@@ -195,12 +325,15 @@ func (dao *ProductDao) FindProductById(ctx context.Context, id uint64) (domain.P
 	}
 
 	var res ProductDetailResult
-	// 查找商品，包括是否上架
-	if err := dao.db.WithContext(ctx).Where("id = ? AND is_active = ?", id, true).First(&res.Product).Error; err != nil {
+	// 查找商品，已删除的商品视为不存在
+	if err := dao.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", id, 0).First(&res.Product).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return domain.ProductDetail{}, ErrProductNotOnList
+			return domain.ProductDetail{}, ErrProductNotFound
 		}
-		return domain.ProductDetail{}, ErrProductNotFound
+		return domain.ProductDetail{}, err
+	}
+	if !res.Product.IsActive {
+		return domain.ProductDetail{}, ErrProductNotOnList
 	}
 
 	// 查找分类
@@ -209,7 +342,7 @@ func (dao *ProductDao) FindProductById(ctx context.Context, id uint64) (domain.P
 	}
 
 	// 查找商品图片
-	if err := dao.db.WithContext(ctx).Where("product_id = ?", id).Find(&res.Images).Error; err != nil {
+	if err := dao.db.WithContext(ctx).Where("product_id = ?", id).Order("sort, id").Find(&res.Images).Error; err != nil {
 		return domain.ProductDetail{}, err
 	}
 
@@ -226,35 +359,406 @@ func (dao *ProductDao) FindProductById(ctx context.Context, id uint64) (domain.P
 	}, nil
 }
 
-func (dao *ProductDao) DeleteProductById(ctx context.Context, id uint64) error {
-	err := dao.db.WithContext(ctx).Where("id = ?", id).Delete(&Product{}).Error
-	return err
+func (dao *ProductDao) UpdateProduct(ctx context.Context, upd domain.ProductUpdate) (int64, error) {
+	var version int64
+	err := dao.uow.Do(ctx, func(ctx context.Context) error {
+		tx := gormx.DB(ctx, dao.db)
+
+		var old Product
+		if err := tx.Where("id = ? AND deleted_at = ?", upd.Id, 0).First(&old).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+		if old.Version != upd.Version {
+			return ErrProductVersionConflict
+		}
+
+		var changes []domain.FieldChange
+		updates := map[string]any{}
+		if upd.Name != nil && *upd.Name != old.Name {
+			changes = append(changes, domain.FieldChange{Field: "name", Old: old.Name, New: *upd.Name})
+			updates["name"] = *upd.Name
+		}
+		if upd.Description != nil && *upd.Description != old.Description {
+			changes = append(changes, domain.FieldChange{Field: "description", Old: old.Description, New: *upd.Description})
+			updates["description"] = *upd.Description
+		}
+		if upd.Price != nil && *upd.Price != old.Price {
+			changes = append(changes, domain.FieldChange{Field: "price", Old: formatPrice(old.Price), New: formatPrice(*upd.Price)})
+			updates["price"] = *upd.Price
+		}
+		if upd.Stock != nil && *upd.Stock != old.Stock {
+			changes = append(changes, domain.FieldChange{Field: "stock", Old: strconv.Itoa(old.Stock), New: strconv.Itoa(*upd.Stock)})
+			updates["stock"] = *upd.Stock
+		}
+
+		if upd.CategoryId != nil {
+			change, err := dao.updateProductCategory(ctx, upd.Id, *upd.CategoryId)
+			if err != nil {
+				return err
+			}
+			if change != nil {
+				changes = append(changes, *change)
+			}
+		}
+		if upd.Attributes != nil {
+			change, err := dao.replaceProductAttributes(ctx, upd.Id, upd.Attributes)
+			if err != nil {
+				return err
+			}
+			if change != nil {
+				changes = append(changes, *change)
+			}
+		}
+		if upd.Images != nil {
+			change, err := dao.replaceProductImages(ctx, upd.Id, upd.Images)
+			if err != nil {
+				return err
+			}
+			if change != nil {
+				changes = append(changes, *change)
+			}
+		}
+
+		// 没有任何变化，不递增版本号
+		if len(changes) == 0 {
+			version = old.Version
+			return nil
+		}
+
+		now := time.Now().UnixMilli()
+		updates["version"] = gorm.Expr("version + 1")
+		updates["update_at"] = now
+		res := tx.Model(&Product{}).Where("id = ? AND version = ?", upd.Id, upd.Version).Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// 读取之后被其他请求修改
+			return ErrProductVersionConflict
+		}
+		version = upd.Version + 1
+
+		val, err := json.Marshal(changes)
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&ProductHistory{
+			ProductId:  upd.Id,
+			OperatorId: upd.OperatorId,
+			Version:    version,
+			Changes:    string(val),
+			CreateAt:   now,
+		}).Error
+	})
+
+	return version, err
 }
 
-func (dao *ProductDao) ProductOn(ctx context.Context, id uint64) error {
-	err := dao.db.WithContext(ctx).Where("id = ?", id).Update("is_active", true).Error
+func (dao *ProductDao) updateProductCategory(ctx context.Context, productId uint64, categoryId uint64) (*domain.FieldChange, error) {
+	tx := gormx.DB(ctx, dao.db)
+
+	var category Category
+	if err := tx.Where("id = ?", categoryId).First(&category).Error; err != nil {
+		return nil, ErrCategoryNotFound
+	}
+
+	var pc ProductCategory
+	err := tx.Where("product_id = ?", productId).First(&pc).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if pc.CategoryID == categoryId {
+		return nil, nil
+	}
+
+	if err := tx.Where("product_id = ?", productId).Delete(&ProductCategory{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&ProductCategory{ProductID: productId, CategoryID: categoryId}).Error; err != nil {
+		return nil, err
+	}
+
+	return &domain.FieldChange{
+		Field: "category",
+		Old:   strconv.FormatUint(pc.CategoryID, 10),
+		New:   strconv.FormatUint(categoryId, 10),
+	}, nil
+}
+
+func (dao *ProductDao) replaceProductAttributes(ctx context.Context, productId uint64, attributes []domain.ProductAttribute) (*domain.FieldChange, error) {
+	tx := gormx.DB(ctx, dao.db)
+
+	var olds []ProductAttribute
+	if err := tx.Where("product_id = ?", productId).Order("id").Find(&olds).Error; err != nil {
+		return nil, err
+	}
+
+	oldPairs := make([]string, 0, len(olds))
+	for _, at := range olds {
+		oldPairs = append(oldPairs, at.Name+"="+at.Value)
+	}
+	newPairs := make([]string, 0, len(attributes))
+	for _, at := range attributes {
+		newPairs = append(newPairs, at.Name+"="+at.Value)
+	}
+	if strings.Join(oldPairs, ",") == strings.Join(newPairs, ",") {
+		return nil, nil
+	}
+
+	if err := tx.Where("product_id = ?", productId).Delete(&ProductAttribute{}).Error; err != nil {
+		return nil, err
+	}
+	if err := dao.InsertProductAttributes(ctx, productId, attributes); err != nil {
+		return nil, err
+	}
+
+	return &domain.FieldChange{
+		Field: "attributes",
+		Old:   strings.Join(oldPairs, ","),
+		New:   strings.Join(newPairs, ","),
+	}, nil
+}
+
+func (dao *ProductDao) replaceProductImages(ctx context.Context, productId uint64, imageUrls []string) (*domain.FieldChange, error) {
+	tx := gormx.DB(ctx, dao.db)
+
+	var olds []ProductImage
+	if err := tx.Where("product_id = ?", productId).Order("sort, id").Find(&olds).Error; err != nil {
+		return nil, err
+	}
+
+	oldUrls := make([]string, 0, len(olds))
+	for _, img := range olds {
+		oldUrls = append(oldUrls, img.ImageUrl)
+	}
+	if strings.Join(oldUrls, ",") == strings.Join(imageUrls, ",") {
+		return nil, nil
+	}
+
+	if err := tx.Where("product_id = ?", productId).Delete(&ProductImage{}).Error; err != nil {
+		return nil, err
+	}
+	if err := dao.InsertProductImages(ctx, productId, imageUrls); err != nil {
+		return nil, err
+	}
+
+	return &domain.FieldChange{
+		Field: "images",
+		Old:   strings.Join(oldUrls, ","),
+		New:   strings.Join(imageUrls, ","),
+	}, nil
+}
+
+func (dao *ProductDao) FindProductHistory(ctx context.Context, productId uint64) ([]domain.ProductHistory, error) {
+	var histories []ProductHistory
+	err := dao.db.WithContext(ctx).Where("product_id = ?", productId).Order("version DESC").Find(&histories).Error
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrProductNotFound
+		return nil, err
+	}
+
+	res := make([]domain.ProductHistory, 0, len(histories))
+	for _, h := range histories {
+		var changes []domain.FieldChange
+		if err := json.Unmarshal([]byte(h.Changes), &changes); err != nil {
+			return nil, err
 		}
-		return err
+		res = append(res, domain.ProductHistory{
+			Id:         h.Id,
+			ProductId:  h.ProductId,
+			OperatorId: h.OperatorId,
+			Version:    h.Version,
+			Changes:    changes,
+			CreateAt:   h.CreateAt,
+		})
+	}
+
+	return res, nil
+}
+
+// DeleteProductById 软删除商品，同时下架，商品进入回收站
+func (dao *ProductDao) DeleteProductById(ctx context.Context, id uint64) error {
+	now := time.Now().UnixMilli()
+	res := dao.db.WithContext(ctx).Model(&Product{}).
+		Where("id = ? AND deleted_at = ?", id, 0).
+		Updates(map[string]any{
+			"deleted_at": now,
+			"is_active":  false,
+			"update_at":  now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrProductNotFound
 	}
 
 	return nil
 }
 
-func (dao *ProductDao) ProductRemove(ctx context.Context, id uint64) error {
-	err := dao.db.WithContext(ctx).Where("id = ?", id).Update("is_active", false).Error
+// RestoreProduct 从回收站恢复商品，恢复后保持下架状态
+func (dao *ProductDao) RestoreProduct(ctx context.Context, id uint64) error {
+	res := dao.db.WithContext(ctx).Model(&Product{}).
+		Where("id = ? AND deleted_at > ?", id, 0).
+		Updates(map[string]any{
+			"deleted_at": 0,
+			"update_at":  time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrProductNotInTrash
+	}
+
+	return nil
+}
+
+func (dao *ProductDao) FindDeletedProducts(ctx context.Context, merchantId uint64, offset, limit int) ([]domain.DeletedProduct, error) {
+	var products []Product
+	err := dao.db.WithContext(ctx).
+		Where("merchant_id = ? AND deleted_at > ?", merchantId, 0).
+		Order("deleted_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&products).Error
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrProductNotFound
+		return nil, err
+	}
+
+	res := make([]domain.DeletedProduct, 0, len(products))
+	for _, p := range products {
+		res = append(res, domain.DeletedProduct{
+			Id:        p.Id,
+			Name:      p.Name,
+			Price:     p.Price,
+			DeletedAt: p.DeletedAt,
+		})
+	}
+
+	return res, nil
+}
+
+// PurgeDeletedProducts 彻底删除 before 之前进入回收站的商品及其关联数据，返回本次删除的商品 ID。
+// 在事务中锁住要删除的商品，同时进行的恢复会等待删除完成，不会恢复一个关联数据已被删除的商品
+func (dao *ProductDao) PurgeDeletedProducts(ctx context.Context, before int64, limit int) ([]uint64, error) {
+	var ids []uint64
+	err := dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+
+		err := db.Model(&Product{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at > ? AND deleted_at < ?", 0, before).
+			Limit(limit).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
 		}
-		return err
+
+		related := []any{&ProductImage{}, &ProductAttribute{}, &ProductCategory{}, &ProductHistory{}, &IdempotencyKey{}}
+		for _, model := range related {
+			if err := db.Where("product_id IN ?", ids).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		return db.Where("id IN ?", ids).Delete(&Product{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// ProductOn 立即上架商品，同时取消定时上架
+func (dao *ProductDao) ProductOn(ctx context.Context, id uint64) error {
+	return dao.updateProduct(ctx, id, map[string]any{
+		"is_active": true,
+		"list_at":   0,
+		"update_at": time.Now().UnixMilli(),
+	})
+}
+
+// ProductRemove 立即下架商品，同时取消定时下架
+func (dao *ProductDao) ProductRemove(ctx context.Context, id uint64) error {
+	return dao.updateProduct(ctx, id, map[string]any{
+		"is_active": false,
+		"delist_at": 0,
+		"update_at": time.Now().UnixMilli(),
+	})
+}
+
+// ScheduleProduct 设置定时上下架时间，0 表示取消
+func (dao *ProductDao) ScheduleProduct(ctx context.Context, id uint64, listAt, delistAt int64) error {
+	return dao.updateProduct(ctx, id, map[string]any{
+		"list_at":   listAt,
+		"delist_at": delistAt,
+		"update_at": time.Now().UnixMilli(),
+	})
+}
+
+func (dao *ProductDao) updateProduct(ctx context.Context, id uint64, updates map[string]any) error {
+	res := dao.db.WithContext(ctx).Model(&Product{}).
+		Where("id = ? AND deleted_at = ?", id, 0).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrProductNotFound
 	}
 
 	return nil
 }
 
+// ApplyDueListings 上架到期的定时上架商品，返回被上架的商品 ID
+func (dao *ProductDao) ApplyDueListings(ctx context.Context, now int64, limit int) ([]uint64, error) {
+	return dao.applyDue(ctx, "list_at", now, limit, map[string]any{
+		"is_active": true,
+		"list_at":   0,
+		"update_at": now,
+	})
+}
+
+// ApplyDueDelistings 下架到期的定时下架商品，返回被下架的商品 ID
+func (dao *ProductDao) ApplyDueDelistings(ctx context.Context, now int64, limit int) ([]uint64, error) {
+	return dao.applyDue(ctx, "delist_at", now, limit, map[string]any{
+		"is_active": false,
+		"delist_at": 0,
+		"update_at": now,
+	})
+}
+
+func (dao *ProductDao) applyDue(ctx context.Context, column string, now int64, limit int, updates map[string]any) ([]uint64, error) {
+	cond := column + " > 0 AND " + column + " <= ? AND deleted_at = 0"
+
+	// 锁住到期的商品再更新，同时进行的修改、删除会等待，返回的 ID 都确实被更新了
+	var ids []uint64
+	err := dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+
+		err := db.Model(&Product{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(cond, now).
+			Order(column).
+			Limit(limit).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		return db.Model(&Product{}).Where("id IN ?", ids).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
 //func (dao *ProductDao) FindProductById(ctx context.Context, id uint64) (domain.ProductDetail, error) {
 //	type ProductDetailResult struct {
 //		Product  Product
@@ -306,16 +810,43 @@ func (dao *ProductDao) categoryDaoToDomain(category Category) domain.Category {
 	}
 }
 
+func (dao *ProductDao) productDomainToDao(product domain.Product) Product {
+	return Product{
+		MerchantId:  product.MerchantId,
+		Sku:         sql.NullString{String: product.Sku, Valid: product.Sku != ""},
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		IsActive:    true,
+		Version:     1,
+	}
+}
+
 func (dao *ProductDao) productDaoToDomain(product Product) domain.Product {
 	return domain.Product{
 		Id:          product.Id,
+		MerchantId:  product.MerchantId,
+		Sku:         product.Sku.String,
 		Name:        product.Name,
 		Description: product.Description,
 		Price:       product.Price,
 		Stock:       product.Stock,
+		Version:     product.Version,
+		ListAt:      product.ListAt,
+		DelistAt:    product.DelistAt,
+		RatingAvg:   ratingAvg(product.RatingSum, product.RatingCount),
+		RatingCount: product.RatingCount,
 	}
 }
 
+func ratingAvg(sum, count int64) float64 {
+	if count == 0 {
+		return 0
+	}
+	return math.Round(float64(sum)/float64(count)*10) / 10
+}
+
 func (dao *ProductDao) imageDaoToDomain(images []ProductImage) []domain.ProductImage {
 	var domainImages []domain.ProductImage
 	for _, img := range images {
@@ -324,6 +855,7 @@ func (dao *ProductDao) imageDaoToDomain(images []ProductImage) []domain.ProductI
 			ProductId: img.ProductId,
 			ImageUrl:  img.ImageUrl,
 			IsPrimary: img.IsPrimary,
+			Sort:      img.Sort,
 		})
 	}
 	return domainImages
@@ -342,3 +874,27 @@ func (dao *ProductDao) attributeDaoToDomain(attributes []ProductAttribute) []dom
 
 	return ats
 }
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
+
+// sameIds 判断两个 ID 列表是否包含相同的元素（不考虑顺序，不允许重复）
+func sameIds(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := make(map[uint64]struct{}, len(a))
+	for _, id := range a {
+		set[id] = struct{}{}
+	}
+	for _, id := range b {
+		if _, ok := set[id]; !ok {
+			return false
+		}
+		delete(set, id)
+	}
+
+	return len(set) == 0
+}
@@ -0,0 +1,199 @@
+package dao
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mall/internal/product/domain"
+	"mall/pkg/gormx"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrStockBizKeyReused = errors.New("business key has already deducted different stock")
+)
+
+// StockShortageError 扣减时库存不足的商品，errors.Is 判断为 ErrInsufficientStock
+type StockShortageError struct {
+	ProductId uint64
+}
+
+func (e *StockShortageError) Error() string {
+	return fmt.Sprintf("product %d does not have enough stock", e.ProductId)
+}
+
+func (e *StockShortageError) Is(target error) bool {
+	return target == ErrInsufficientStock
+}
+
+// UpdateProductStock 原子地调整库存，quantity 为负数时扣减，库存不足时不扣减并返回 StockShortageError，
+// 加入 ctx 中的事务
+func (dao *ProductDao) UpdateProductStock(ctx context.Context, productId uint64, quantity int) error {
+	if quantity == 0 {
+		return nil
+	}
+
+	return dao.updateStock(gormx.DB(ctx, dao.db), productId, quantity)
+}
+
+// DeductStock 在一个事务中扣减多个商品的库存，任一商品库存不足时全部不扣减。
+// 按商品 ID 升序加锁，避免并发扣减相同商品时死锁；同一业务单号重复扣减相同的商品和数量时直接返回成功，
+// 商品或数量不同时返回 ErrStockBizKeyReused
+func (dao *ProductDao) DeductStock(ctx context.Context, bizKey string, lines []domain.StockLine) error {
+	lines = mergeStockLines(lines)
+	if len(lines) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	logs := make([]StockLog, 0, len(lines))
+	for _, line := range lines {
+		logs = append(logs, StockLog{
+			BizKey:    bizKey,
+			ProductId: line.ProductId,
+			Quantity:  line.Quantity,
+			CreateAt:  now,
+		})
+	}
+
+	return dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+		err := db.Create(&logs).Error
+		if gormx.IsUniqueConflict(err) {
+			return dao.checkDeducted(db, bizKey, lines)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, line := range lines {
+			if err := dao.updateStock(db, line.ProductId, -line.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// checkDeducted 业务单号已经扣减过时，检查扣减记录和这次要扣减的商品、数量是否一致，lines 按商品 ID 升序
+func (dao *ProductDao) checkDeducted(db *gorm.DB, bizKey string, lines []domain.StockLine) error {
+	var logs []StockLog
+	if err := db.Where("biz_key = ?", bizKey).Order("product_id").Find(&logs).Error; err != nil {
+		return err
+	}
+
+	if len(logs) != len(lines) {
+		return ErrStockBizKeyReused
+	}
+	for i, lg := range logs {
+		if lg.ProductId != lines[i].ProductId || lg.Quantity != lines[i].Quantity {
+			return ErrStockBizKeyReused
+		}
+	}
+	return nil
+}
+
+// RestoreStock 归还业务单号扣减的库存，返回本次归还的商品；已经归还过或没有扣减过时返回空
+func (dao *ProductDao) RestoreStock(ctx context.Context, bizKey string) ([]domain.StockLine, error) {
+	var restored []domain.StockLine
+	err := dao.uow.Do(ctx, func(ctx context.Context) error {
+		restored = restored[:0]
+		db := gormx.DB(ctx, dao.db)
+		// 锁住扣减记录，并发归还同一单号时后到的读不到未归还的记录
+		var logs []StockLog
+		err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("biz_key = ? AND restore_at = 0", bizKey).
+			Order("product_id").
+			Find(&logs).Error
+		if err != nil || len(logs) == 0 {
+			return err
+		}
+
+		ids := make([]uint64, 0, len(logs))
+		for _, lg := range logs {
+			ids = append(ids, lg.Id)
+		}
+		err = db.Model(&StockLog{}).Where("id IN ?", ids).Update("restore_at", time.Now().UnixMilli()).Error
+		if err != nil {
+			return err
+		}
+
+		for _, lg := range logs {
+			if err := dao.updateStock(db, lg.ProductId, lg.Quantity); err != nil {
+				return err
+			}
+			restored = append(restored, domain.StockLine{ProductId: lg.ProductId, Quantity: lg.Quantity})
+		}
+		return nil
+	})
+
+	return restored, err
+}
+
+// FindStocks 批量查询商品当前库存，不存在的商品不在结果中
+func (dao *ProductDao) FindStocks(ctx context.Context, ids []uint64) (map[uint64]int, error) {
+	stocks := make(map[uint64]int, len(ids))
+	if len(ids) == 0 {
+		return stocks, nil
+	}
+
+	var products []Product
+	err := dao.db.WithContext(ctx).Select("id", "stock").Where("id IN ?", ids).Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range products {
+		stocks[p.Id] = p.Stock
+	}
+	return stocks, nil
+}
+
+// updateStock 扣减时把库存条件放进 UPDATE，由数据库的行锁保证不会扣成负数
+func (dao *ProductDao) updateStock(db *gorm.DB, productId uint64, quantity int) error {
+	query := db.Model(&Product{}).Where("id = ?", productId)
+	if quantity < 0 {
+		query = query.Where("stock >= ?", -quantity)
+	}
+	res := query.Update("stock", gorm.Expr("stock + ?", quantity))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(&Product{}).Where("id = ?", productId).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrProductNotFound
+	}
+	return &StockShortageError{ProductId: productId}
+}
+
+// mergeStockLines 合并同一商品的多行并按商品 ID 升序排列，忽略数量不为正的行
+func mergeStockLines(lines []domain.StockLine) []domain.StockLine {
+	quantityOf := make(map[uint64]int, len(lines))
+	for _, line := range lines {
+		if line.Quantity > 0 {
+			quantityOf[line.ProductId] += line.Quantity
+		}
+	}
+
+	res := make([]domain.StockLine, 0, len(quantityOf))
+	for id, quantity := range quantityOf {
+		res = append(res, domain.StockLine{ProductId: id, Quantity: quantity})
+	}
+	slices.SortFunc(res, func(a, b domain.StockLine) int {
+		return cmp.Compare(a.ProductId, b.ProductId)
+	})
+	return res
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mall/internal/product/domain"
+	"mall/internal/product/repository/cache"
+)
+
+type ViewHistoryRepository struct {
+	cache *cache.ViewHistoryCache
+}
+
+func NewViewHistoryRepository(cache *cache.ViewHistoryCache) *ViewHistoryRepository {
+	return &ViewHistoryRepository{
+		cache: cache,
+	}
+}
+
+// Viewer 浏览者，登录用户按 UserId 记录，匿名访客按 DeviceId 记录
+type Viewer struct {
+	UserId   uint64
+	DeviceId string
+}
+
+func (repo *ViewHistoryRepository) AddView(ctx context.Context, viewer Viewer, productId uint64) error {
+	return repo.cache.Add(ctx, repo.key(viewer), productId, time.Now())
+}
+
+func (repo *ViewHistoryRepository) FindViews(ctx context.Context, viewer Viewer) ([]domain.ViewedProduct, error) {
+	return repo.cache.Get(ctx, repo.key(viewer))
+}
+
+func (repo *ViewHistoryRepository) ClearViews(ctx context.Context, viewer Viewer) error {
+	return repo.cache.Del(ctx, repo.key(viewer))
+}
+
+// MergeDeviceViews 把匿名设备的浏览记录合并到用户名下
+func (repo *ViewHistoryRepository) MergeDeviceViews(ctx context.Context, deviceId string, uid uint64) error {
+	return repo.cache.Merge(ctx, repo.key(Viewer{DeviceId: deviceId}), repo.key(Viewer{UserId: uid}))
+}
+
+// ScanViews 遍历所有访客的浏览记录，供离线任务统计使用
+func (repo *ViewHistoryRepository) ScanViews(ctx context.Context, fn func(productIds []uint64) error) error {
+	return repo.cache.Scan(ctx, fn)
+}
+
+func (repo *ViewHistoryRepository) key(viewer Viewer) string {
+	if viewer.UserId > 0 {
+		return fmt.Sprintf("product:viewed:user:%d", viewer.UserId)
+	}
+	return fmt.Sprintf("product:viewed:device:%s", viewer.DeviceId)
+}
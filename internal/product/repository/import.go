@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"mall/internal/product/domain"
+	"mall/internal/product/repository/cache"
+)
+
+type ImportJobRepository struct {
+	cache *cache.ImportJobCache
+}
+
+func NewImportJobRepository(cache *cache.ImportJobCache) *ImportJobRepository {
+	return &ImportJobRepository{
+		cache: cache,
+	}
+}
+
+func (repo *ImportJobRepository) Save(ctx context.Context, job domain.ImportJob) error {
+	return repo.cache.Set(ctx, job)
+}
+
+func (repo *ImportJobRepository) Find(ctx context.Context, id string) (domain.ImportJob, error) {
+	return repo.cache.Get(ctx, id)
+}
+
+// SaveReport 保存导入任务的错误报告，不放在公开的对象存储中
+func (repo *ImportJobRepository) SaveReport(ctx context.Context, id string, report []byte) error {
+	return repo.cache.SetReport(ctx, id, report)
+}
+
+func (repo *ImportJobRepository) FindReport(ctx context.Context, id string) ([]byte, error) {
+	return repo.cache.GetReport(ctx, id)
+}
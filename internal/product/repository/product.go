@@ -2,29 +2,48 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
+
 	"mall/internal/product/domain"
 	"mall/internal/product/repository/cache"
 	"mall/internal/product/repository/dao"
+	"mall/pkg/gormx"
 )
 
 var (
-	ErrCategoryDuplicateName = dao.ErrCategoryDuplicateName
-	ErrCategoriesNotFound    = dao.ErrCategoriesNotFound
-	ErrCategoryNotFound      = dao.ErrCategoryNotFound
-	ErrProductNotFound       = dao.ErrProductNotFound
-	ErrProductNotOnList      = dao.ErrProductNotOnList
+	ErrCategoryDuplicateName  = dao.ErrCategoryDuplicateName
+	ErrCategoriesNotFound     = dao.ErrCategoriesNotFound
+	ErrCategoryNotFound       = dao.ErrCategoryNotFound
+	ErrProductNotFound        = dao.ErrProductNotFound
+	ErrProductNotOnList       = dao.ErrProductNotOnList
+	ErrProductVersionConflict = dao.ErrProductVersionConflict
+	ErrProductNotInTrash      = dao.ErrProductNotInTrash
+	ErrImageNotFound          = dao.ErrImageNotFound
+	ErrImageMismatch          = dao.ErrImageMismatch
+	ErrProductDuplicateSku    = dao.ErrProductDuplicateSku
+	ErrImportJobNotFound      = cache.ErrImportJobNotFound
+	ErrInsufficientStock      = dao.ErrInsufficientStock
+	ErrStockBizKeyReused      = dao.ErrStockBizKeyReused
 )
 
+type StockShortageError = dao.StockShortageError
+
+// 秒杀商品预扣后等待数据库扣减结果的最长时间，超过后由校准任务清理
+const flashInflightTTL = time.Minute
+
 type ProductRepository struct {
 	dao   *dao.ProductDao
 	cache *cache.ProductCache
+	stock *cache.StockCache
 }
 
-func NewProductRepository(dao *dao.ProductDao, cache *cache.ProductCache) *ProductRepository {
+func NewProductRepository(dao *dao.ProductDao, cache *cache.ProductCache, stock *cache.StockCache) *ProductRepository {
 	return &ProductRepository{
 		dao:   dao,
 		cache: cache,
+		stock: stock,
 	}
 }
 
@@ -36,42 +55,317 @@ func (repo *ProductRepository) AcquireAllCategory(ctx context.Context) ([]domain
 	return repo.dao.AcquireAllCategory(ctx)
 }
 
-func (repo *ProductRepository) InsertProduct(ctx context.Context, pro domain.Product, attributes []domain.ProductAttribute, imageUrls []string) error {
-	return repo.dao.InsertProduct(ctx, pro, attributes, imageUrls)
+func (repo *ProductRepository) InsertProduct(ctx context.Context, pro domain.Product, attributes []domain.ProductAttribute, imageUrls []string, uid uint64, idemKey string) (uint64, error) {
+	id, err := repo.dao.InsertProduct(ctx, pro, attributes, imageUrls, uid, idemKey)
+	if err != nil {
+		return 0, err
+	}
+
+	// 新 id 之前可能被当作不存在缓存过
+	repo.invalidate(ctx, id)
+	return id, nil
 }
 
-func (repo *ProductRepository) SearchProducts(ctx context.Context, name string) ([]domain.ProductApproximate, error) {
-	return repo.dao.SearchProducts(ctx, name)
+func (repo *ProductRepository) SearchProducts(ctx context.Context, search domain.ProductSearch) ([]domain.ProductApproximate, error) {
+	return repo.dao.SearchProducts(ctx, search)
 }
 
 func (repo *ProductRepository) FindProductById(ctx context.Context, id uint64) (domain.ProductDetail, error) {
-	detail, err := repo.cache.GetProduct(ctx, id)
-	if err == nil {
-		return detail, nil
+	return repo.cache.GetProduct(ctx, id, func(ctx context.Context) (domain.ProductDetail, error) {
+		return repo.dao.FindProductById(ctx, id)
+	})
+}
+
+func (repo *ProductRepository) UpdateProduct(ctx context.Context, upd domain.ProductUpdate) (int64, error) {
+	version, err := repo.dao.UpdateProduct(ctx, upd)
+	if err != nil {
+		return 0, err
 	}
-	detail, err = repo.dao.FindProductById(ctx, id)
+
+	// 数据已变更，删除缓存
+	repo.invalidate(ctx, upd.Id)
+
+	return version, nil
+}
+
+func (repo *ProductRepository) FindProductHistory(ctx context.Context, productId uint64) ([]domain.ProductHistory, error) {
+	return repo.dao.FindProductHistory(ctx, productId)
+}
+
+func (repo *ProductRepository) DeleteProductById(ctx context.Context, id uint64) error {
+	err := repo.dao.DeleteProductById(ctx, id)
 	if err != nil {
-		return domain.ProductDetail{}, err
+		return err
 	}
 
-	// 尝试更新缓存
-	err = repo.cache.SetProduct(ctx, id, detail)
+	repo.invalidate(ctx, id)
+	return nil
+}
+
+func (repo *ProductRepository) RestoreProduct(ctx context.Context, id uint64) error {
+	err := repo.dao.RestoreProduct(ctx, id)
 	if err != nil {
-		log.Printf("缓存更新失败 %v", err.Error())
-		// 可选择记录错误或其他处理
+		return err
 	}
 
-	return detail, nil
+	repo.invalidate(ctx, id)
+	return nil
 }
 
-func (repo *ProductRepository) DeleteProductById(ctx context.Context, id uint64) error {
-	return repo.dao.DeleteProductById(ctx, id)
+func (repo *ProductRepository) FindDeletedProducts(ctx context.Context, merchantId uint64, offset, limit int) ([]domain.DeletedProduct, error) {
+	return repo.dao.FindDeletedProducts(ctx, merchantId, offset, limit)
+}
+
+func (repo *ProductRepository) PurgeDeletedProducts(ctx context.Context, before time.Time, limit int) ([]uint64, error) {
+	return repo.dao.PurgeDeletedProducts(ctx, before.UnixMilli(), limit)
 }
 
 func (repo *ProductRepository) ProductOn(ctx context.Context, id uint64) error {
-	return repo.dao.ProductOn(ctx, id)
+	err := repo.dao.ProductOn(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	repo.invalidate(ctx, id)
+	return nil
 }
 
 func (repo *ProductRepository) ProductRemove(ctx context.Context, id uint64) error {
-	return repo.dao.ProductRemove(ctx, id)
+	err := repo.dao.ProductRemove(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	repo.invalidate(ctx, id)
+	return nil
+}
+
+func (repo *ProductRepository) ScheduleProduct(ctx context.Context, id uint64, listAt, delistAt int64) error {
+	err := repo.dao.ScheduleProduct(ctx, id, listAt, delistAt)
+	if err != nil {
+		return err
+	}
+
+	repo.invalidate(ctx, id)
+	return nil
+}
+
+// UpdateProductStock 调整库存，quantity 为负数时扣减，库存不足时返回 StockShortageError
+func (repo *ProductRepository) UpdateProductStock(ctx context.Context, id uint64, quantity int) error {
+	err := repo.dao.UpdateProductStock(ctx, id, quantity)
+	if err != nil {
+		return err
+	}
+
+	repo.invalidate(ctx, id)
+	repo.syncFlashStock(ctx, id)
+	return nil
+}
+
+// DeductStock 按业务单号扣减多个商品的库存，加入 ctx 中的事务。
+// 秒杀商品先在 Redis 中预扣，预扣不足的请求不访问数据库；数据库扣减失败或外层事务回滚时归还预扣
+func (repo *ProductRepository) DeductStock(ctx context.Context, bizKey string, lines []domain.StockLine) error {
+	ids := make([]uint64, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.ProductId)
+	}
+
+	short, err := repo.stock.Deduct(ctx, bizKey, lines, flashInflightTTL)
+	if err != nil {
+		// Redis 不可用时只用数据库扣减，数据库的条件更新保证不会超卖
+		log.Printf("秒杀库存预扣失败 %v", err.Error())
+	}
+	if short != 0 {
+		return &StockShortageError{ProductId: short}
+	}
+
+	if err := repo.dao.DeductStock(ctx, bizKey, lines); err != nil {
+		repo.settleFlashStock(context.Background(), bizKey, ids, true)
+		return err
+	}
+	gormx.AfterCommit(ctx, func() {
+		repo.settleFlashStock(context.Background(), bizKey, ids, false)
+	})
+	gormx.AfterRollback(ctx, func() {
+		repo.settleFlashStock(context.Background(), bizKey, ids, true)
+	})
+	repo.invalidate(ctx, ids...)
+	return nil
+}
+
+// RestoreStock 归还业务单号扣减的库存，重复归还不会多加
+func (repo *ProductRepository) RestoreStock(ctx context.Context, bizKey string) error {
+	lines, err := repo.dao.RestoreStock(ctx, bizKey)
+	if err != nil || len(lines) == 0 {
+		return err
+	}
+
+	ids := make([]uint64, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.ProductId)
+	}
+	repo.invalidate(ctx, ids...)
+	repo.syncFlashStock(ctx, ids...)
+	return nil
+}
+
+// SetFlashStock 开启或关闭商品的秒杀库存预扣
+func (repo *ProductRepository) SetFlashStock(ctx context.Context, id uint64, enabled bool) error {
+	if !enabled {
+		return repo.stock.Disable(ctx, id)
+	}
+
+	stocks, err := repo.dao.FindStocks(ctx, []uint64{id})
+	if err != nil {
+		return err
+	}
+	stock, ok := stocks[id]
+	if !ok {
+		return ErrProductNotFound
+	}
+	return repo.stock.Enable(ctx, id, stock)
+}
+
+// SyncFlashStocks 用数据库库存校准所有秒杀商品在 Redis 中的库存，返回校准的商品数
+func (repo *ProductRepository) SyncFlashStocks(ctx context.Context) (int, error) {
+	ids, err := repo.stock.FlashProducts(ctx)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	stocks, err := repo.dao.FindStocks(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, id := range ids {
+		stock, ok := stocks[id]
+		if !ok {
+			// 商品已被彻底删除
+			errs = append(errs, repo.stock.Disable(ctx, id))
+			continue
+		}
+		errs = append(errs, repo.stock.Sync(ctx, id, stock))
+	}
+	return len(ids), errors.Join(errs...)
+}
+
+// syncFlashStock 事务提交后校准秒杀商品的库存，失败时等校准任务
+func (repo *ProductRepository) syncFlashStock(ctx context.Context, ids ...uint64) {
+	gormx.AfterCommit(ctx, func() {
+		stocks, err := repo.dao.FindStocks(ctx, ids)
+		if err != nil {
+			log.Printf("秒杀库存校准失败 %v", err.Error())
+			return
+		}
+		for id, stock := range stocks {
+			if err := repo.stock.Sync(ctx, id, stock); err != nil {
+				log.Printf("秒杀库存校准失败 %v", err.Error())
+			}
+		}
+	})
+}
+
+func (repo *ProductRepository) settleFlashStock(ctx context.Context, bizKey string, ids []uint64, giveBack bool) {
+	if err := repo.stock.Settle(ctx, bizKey, ids, giveBack); err != nil {
+		log.Printf("秒杀库存预扣结束失败 %v", err.Error())
+	}
+}
+
+// ApplyDueSchedules 执行到期的定时上下架，返回状态发生变化的商品数量
+func (repo *ProductRepository) ApplyDueSchedules(ctx context.Context, now time.Time, limit int) (int, error) {
+	listed, err := repo.dao.ApplyDueListings(ctx, now.UnixMilli(), limit)
+	if err != nil {
+		return 0, err
+	}
+	delisted, err := repo.dao.ApplyDueDelistings(ctx, now.UnixMilli(), limit)
+	if err != nil {
+		return len(listed), err
+	}
+
+	repo.invalidate(ctx, append(listed, delisted...)...)
+
+	return len(listed) + len(delisted), nil
+}
+
+func (repo *ProductRepository) ReorderImages(ctx context.Context, productId uint64, imageIds []uint64) error {
+	err := repo.dao.ReorderImages(ctx, productId, imageIds)
+	if err != nil {
+		return err
+	}
+
+	repo.invalidate(ctx, productId)
+	return nil
+}
+
+func (repo *ProductRepository) SetPrimaryImage(ctx context.Context, productId, imageId uint64) error {
+	err := repo.dao.SetPrimaryImage(ctx, productId, imageId)
+	if err != nil {
+		return err
+	}
+
+	repo.invalidate(ctx, productId)
+	return nil
+}
+
+func (repo *ProductRepository) FindProductBySku(ctx context.Context, merchantId uint64, sku string) (domain.Product, error) {
+	return repo.dao.FindProductBySku(ctx, merchantId, sku)
+}
+
+func (repo *ProductRepository) FindProductsAfter(ctx context.Context, merchantId, lastId uint64, limit int) ([]domain.ProductDetail, error) {
+	return repo.dao.FindProductsAfter(ctx, merchantId, lastId, limit)
+}
+
+// AdjustRating 调整商品的评分汇总，可以在外层事务中调用
+func (repo *ProductRepository) AdjustRating(ctx context.Context, productId uint64, sumDelta, countDelta int64) error {
+	err := repo.dao.AdjustRating(ctx, productId, sumDelta, countDelta)
+	if err != nil {
+		return err
+	}
+
+	repo.invalidate(ctx, productId)
+	return nil
+}
+
+func (repo *ProductRepository) FindProductSummaries(ctx context.Context, ids []uint64) ([]domain.ProductSummary, error) {
+	return repo.dao.FindProductSummaries(ctx, ids)
+}
+
+func (repo *ProductRepository) FindProductOwner(ctx context.Context, id uint64) (uint64, error) {
+	return repo.dao.FindProductOwner(ctx, id)
+}
+
+func (repo *ProductRepository) FindMerchantProducts(ctx context.Context, merchantId uint64, status string, offset, limit int) ([]domain.MerchantProduct, error) {
+	return repo.dao.FindMerchantProducts(ctx, merchantId, status, offset, limit)
+}
+
+func (repo *ProductRepository) CountMerchantProducts(ctx context.Context, merchantId uint64) (domain.MerchantProductCounts, error) {
+	return repo.dao.CountMerchantProducts(ctx, merchantId)
+}
+
+func (repo *ProductRepository) ReplaceAttributeTemplates(ctx context.Context, categoryId uint64, templates []domain.AttributeTemplate) error {
+	return repo.dao.ReplaceAttributeTemplates(ctx, categoryId, templates)
+}
+
+func (repo *ProductRepository) FindAttributeTemplates(ctx context.Context, categoryId uint64) ([]domain.AttributeTemplate, error) {
+	return repo.dao.FindAttributeTemplates(ctx, categoryId)
+}
+
+func (repo *ProductRepository) FindAllAttributeTemplates(ctx context.Context) (map[uint64][]domain.AttributeTemplate, error) {
+	return repo.dao.FindAllAttributeTemplates(ctx)
+}
+
+func (repo *ProductRepository) FindProductAttributes(ctx context.Context, id uint64) (uint64, []domain.ProductAttribute, error) {
+	return repo.dao.FindProductAttributes(ctx, id)
+}
+
+// invalidate 商品的每次写操作之后调用，删除缓存并通知所有实例
+// 在外层事务中调用时，等事务提交后再删除，避免删除后又读到旧数据写回缓存
+func (repo *ProductRepository) invalidate(ctx context.Context, ids ...uint64) {
+	gormx.AfterCommit(ctx, func() {
+		err := repo.cache.DelProduct(ctx, ids...)
+		if err != nil {
+			log.Printf("缓存删除失败 %v", err.Error())
+		}
+	})
 }
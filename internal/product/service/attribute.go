@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"mall/internal/product/domain"
+)
+
+var (
+	ErrInvalidAttributeTemplate = errors.New("invalid attribute template")
+	ErrInvalidAttribute         = errors.New("invalid attribute")
+	ErrInvalidAttributeFilter   = errors.New("invalid attribute filter")
+)
+
+const (
+	maxTemplateAttributes = 50
+	maxAttributeName      = 64
+	maxAttributeValue     = 255
+	maxAttributeUnit      = 16
+)
+
+// SetAttributeTemplates 整体替换类目的属性模板，templates 为空时清空模板，该类目的商品属性不再受约束
+func (svc *ProductService) SetAttributeTemplates(ctx context.Context, categoryId string, templates []domain.AttributeTemplate) error {
+	id, err := strconv.Atoi(categoryId)
+	if err != nil {
+		return err
+	}
+	if err := validateTemplates(templates); err != nil {
+		return err
+	}
+
+	return svc.repo.ReplaceAttributeTemplates(ctx, uint64(id), templates)
+}
+
+func (svc *ProductService) GetAttributeTemplates(ctx context.Context, categoryId string) ([]domain.AttributeTemplate, error) {
+	id, err := strconv.Atoi(categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc.repo.FindAttributeTemplates(ctx, uint64(id))
+}
+
+// checkAttributes 按类目的属性模板校验商品属性，返回规范化后的属性
+func (svc *ProductService) checkAttributes(ctx context.Context, categoryId uint64, attributes []domain.ProductAttribute) ([]domain.ProductAttribute, error) {
+	templates, err := svc.repo.FindAttributeTemplates(ctx, categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	return normalizeAttributes(templates, attributes)
+}
+
+// checkFilters 属性筛选只能用于指定类目下可筛选的属性，属性名按模板规范化
+func (svc *ProductService) checkFilters(ctx context.Context, search domain.ProductSearch) (domain.ProductSearch, error) {
+	if len(search.Attributes) == 0 {
+		return search, nil
+	}
+	if search.CategoryId == 0 {
+		return search, fmt.Errorf("%w: category is required", ErrInvalidAttributeFilter)
+	}
+	templates, err := svc.repo.FindAttributeTemplates(ctx, search.CategoryId)
+	if err != nil {
+		return search, err
+	}
+
+	filters := make([]domain.AttributeFilter, 0, len(search.Attributes))
+	for _, f := range search.Attributes {
+		t, ok := findTemplate(templates, f.Name)
+		if !ok || !t.Filterable {
+			return search, fmt.Errorf("%w: %s is not filterable", ErrInvalidAttributeFilter, f.Name)
+		}
+		f.Name = t.Name
+		if t.Type != domain.AttributeNumber && (f.Min != nil || f.Max != nil) {
+			return search, fmt.Errorf("%w: %s does not support range", ErrInvalidAttributeFilter, f.Name)
+		}
+		for i, v := range f.Values {
+			value, err := normalizeValue(t, v)
+			if err != nil {
+				return search, fmt.Errorf("%w: %s %v", ErrInvalidAttributeFilter, f.Name, err)
+			}
+			f.Values[i] = value
+		}
+		filters = append(filters, f)
+	}
+	search.Attributes = filters
+
+	return search, nil
+}
+
+// normalizeAttributes 类目没有模板时属性不受约束；有模板时属性名必须在模板中，
+// 大小写不同的属性名统一为模板中的写法，必填属性不能缺少
+func normalizeAttributes(templates []domain.AttributeTemplate, attributes []domain.ProductAttribute) ([]domain.ProductAttribute, error) {
+	if len(templates) == 0 {
+		return attributes, nil
+	}
+
+	res := make([]domain.ProductAttribute, 0, len(attributes))
+	seen := make(map[string]struct{}, len(attributes))
+	for _, attr := range attributes {
+		t, ok := findTemplate(templates, attr.Name)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown attribute %s", ErrInvalidAttribute, attr.Name)
+		}
+		if _, ok := seen[t.Name]; ok {
+			return nil, fmt.Errorf("%w: duplicate attribute %s", ErrInvalidAttribute, t.Name)
+		}
+		seen[t.Name] = struct{}{}
+
+		value, err := normalizeValue(t, attr.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidAttribute, t.Name, err)
+		}
+		attr.Name = t.Name
+		attr.Value = value
+		res = append(res, attr)
+	}
+
+	for _, t := range templates {
+		if _, ok := seen[t.Name]; t.Required && !ok {
+			return nil, fmt.Errorf("%w: %s is required", ErrInvalidAttribute, t.Name)
+		}
+	}
+
+	return res, nil
+}
+
+// normalizeValue 校验取值并转换为存储格式，number 去掉单位后统一格式，便于筛选时比较
+func normalizeValue(t domain.AttributeTemplate, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", errors.New("must not be empty")
+	}
+
+	switch t.Type {
+	case domain.AttributeEnum:
+		for _, opt := range t.Options {
+			if opt == value {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("must be one of %s", strings.Join(t.Options, ", "))
+	case domain.AttributeNumber:
+		if t.Unit != "" {
+			value = strings.TrimSpace(strings.TrimSuffix(value, t.Unit))
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", errors.New("must be a number")
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	default:
+		if utf8.RuneCountInString(value) > maxAttributeValue {
+			return "", fmt.Errorf("must be at most %d characters", maxAttributeValue)
+		}
+		return value, nil
+	}
+}
+
+func findTemplate(templates []domain.AttributeTemplate, name string) (domain.AttributeTemplate, bool) {
+	name = strings.TrimSpace(name)
+	for _, t := range templates {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return domain.AttributeTemplate{}, false
+}
+
+func validateTemplates(templates []domain.AttributeTemplate) error {
+	if len(templates) > maxTemplateAttributes {
+		return fmt.Errorf("%w: at most %d attributes", ErrInvalidAttributeTemplate, maxTemplateAttributes)
+	}
+
+	names := make(map[string]struct{}, len(templates))
+	for i := range templates {
+		t := &templates[i]
+		t.Name = strings.TrimSpace(t.Name)
+		n := utf8.RuneCountInString(t.Name)
+		if n == 0 || n > maxAttributeName {
+			return fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidAttributeTemplate, maxAttributeName)
+		}
+		key := strings.ToLower(t.Name)
+		if _, ok := names[key]; ok {
+			return fmt.Errorf("%w: duplicate name %s", ErrInvalidAttributeTemplate, t.Name)
+		}
+		names[key] = struct{}{}
+
+		switch t.Type {
+		case domain.AttributeEnum:
+			if len(t.Options) == 0 {
+				return fmt.Errorf("%w: %s needs options", ErrInvalidAttributeTemplate, t.Name)
+			}
+			opts := make(map[string]struct{}, len(t.Options))
+			for j, opt := range t.Options {
+				opt = strings.TrimSpace(opt)
+				if opt == "" || utf8.RuneCountInString(opt) > maxAttributeValue {
+					return fmt.Errorf("%w: %s has an invalid option", ErrInvalidAttributeTemplate, t.Name)
+				}
+				if _, ok := opts[opt]; ok {
+					return fmt.Errorf("%w: %s has duplicate option %s", ErrInvalidAttributeTemplate, t.Name, opt)
+				}
+				opts[opt] = struct{}{}
+				t.Options[j] = opt
+			}
+			t.Unit = ""
+		case domain.AttributeNumber:
+			t.Unit = strings.TrimSpace(t.Unit)
+			if utf8.RuneCountInString(t.Unit) > maxAttributeUnit {
+				return fmt.Errorf("%w: %s unit is too long", ErrInvalidAttributeTemplate, t.Name)
+			}
+			t.Options = nil
+		case domain.AttributeText:
+			t.Options = nil
+			t.Unit = ""
+		default:
+			return fmt.Errorf("%w: %s type must be enum, number or text", ErrInvalidAttributeTemplate, t.Name)
+		}
+	}
+
+	return nil
+}
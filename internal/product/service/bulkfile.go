@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"mall/internal/product/domain"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported file format")
+
+// CSV 的列，images 用 | 分隔，attributes 为 name=value 并用 | 分隔
+var csvHeader = []string{"sku", "name", "description", "price", "stock", "categoryId", "images", "attributes"}
+
+// jsonlProduct JSON Lines 中每行的结构，导入和导出共用
+type jsonlProduct struct {
+	Sku         string           `json:"sku"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Price       float64          `json:"price"`
+	Stock       int              `json:"stock"`
+	CategoryId  uint64           `json:"categoryId"`
+	Images      []string         `json:"images"`
+	Attributes  []jsonlAttribute `json:"attributes"`
+}
+
+type jsonlAttribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// parseImportFile 解析导入文件，格式错误的行放入 errs，不影响其他行
+func parseImportFile(format string, data []byte) (rows []domain.ImportRow, errs []domain.ImportError, err error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(data)
+	case FormatJSONL:
+		return parseJSONL(data)
+	default:
+		return nil, nil, ErrUnsupportedFormat
+	}
+}
+
+func parseCSV(data []byte) ([]domain.ImportRow, []domain.ImportError, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read csv header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	for _, required := range []string{"sku", "name", "price", "categoryId"} {
+		if _, ok := cols[required]; !ok {
+			return nil, nil, fmt.Errorf("csv header is missing column %q", required)
+		}
+	}
+
+	var rows []domain.ImportRow
+	var errs []domain.ImportError
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := r.FieldPos(0)
+		if err != nil {
+			errs = append(errs, domain.ImportError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		get := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := domain.ImportRow{
+			Line:        line,
+			Sku:         get("sku"),
+			Name:        get("name"),
+			Description: get("description"),
+		}
+		if row.Price, err = strconv.ParseFloat(get("price"), 64); err != nil {
+			errs = append(errs, domain.ImportError{Line: line, Sku: row.Sku, Error: "invalid price"})
+			continue
+		}
+		if stock := get("stock"); stock != "" {
+			if row.Stock, err = strconv.Atoi(stock); err != nil {
+				errs = append(errs, domain.ImportError{Line: line, Sku: row.Sku, Error: "invalid stock"})
+				continue
+			}
+		}
+		if row.CategoryId, err = strconv.ParseUint(get("categoryId"), 10, 64); err != nil {
+			errs = append(errs, domain.ImportError{Line: line, Sku: row.Sku, Error: "invalid categoryId"})
+			continue
+		}
+		row.Images = splitList(get("images"))
+		if row.Attributes, err = parseAttributePairs(get("attributes")); err != nil {
+			errs = append(errs, domain.ImportError{Line: line, Sku: row.Sku, Error: err.Error()})
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, errs, nil
+}
+
+// parseAttributePairs 解析 name=value 形式的属性列表
+func parseAttributePairs(s string) ([]domain.ProductAttribute, error) {
+	var attributes []domain.ProductAttribute
+	for _, pair := range splitList(s) {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid attribute %q", pair)
+		}
+		attributes = append(attributes, domain.ProductAttribute{Name: name, Value: value})
+	}
+
+	return attributes, nil
+}
+
+func parseJSONL(data []byte) ([]domain.ImportRow, []domain.ImportError, error) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var rows []domain.ImportRow
+	var errs []domain.ImportError
+	line := 0
+	for sc.Scan() {
+		line++
+		text := bytes.TrimSpace(sc.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var p jsonlProduct
+		if err := json.Unmarshal(text, &p); err != nil {
+			errs = append(errs, domain.ImportError{Line: line, Error: "invalid json"})
+			continue
+		}
+
+		row := domain.ImportRow{
+			Line:        line,
+			Sku:         strings.TrimSpace(p.Sku),
+			Name:        strings.TrimSpace(p.Name),
+			Description: p.Description,
+			Price:       p.Price,
+			Stock:       p.Stock,
+			CategoryId:  p.CategoryId,
+			Images:      p.Images,
+		}
+		for _, at := range p.Attributes {
+			row.Attributes = append(row.Attributes, domain.ProductAttribute{Name: at.Name, Value: at.Value})
+		}
+		rows = append(rows, row)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return rows, errs, nil
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	var res []string
+	for _, item := range strings.Split(s, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// exportWriter 将商品按导入时相同的格式写出
+type exportWriter interface {
+	Write(detail domain.ProductDetail) error
+	Flush() error
+}
+
+func newExportWriter(format string, w io.Writer) (exportWriter, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &csvExportWriter{w: cw}, nil
+	case FormatJSONL:
+		return &jsonlExportWriter{enc: json.NewEncoder(w)}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvExportWriter) Write(detail domain.ProductDetail) error {
+	images := make([]string, 0, len(detail.Images))
+	for _, img := range detail.Images {
+		images = append(images, img.ImageUrl)
+	}
+	attributes := make([]string, 0, len(detail.Attributes))
+	for _, at := range detail.Attributes {
+		attributes = append(attributes, at.Name+"="+at.Value)
+	}
+
+	p := detail.Product
+	return w.w.Write([]string{
+		p.Sku,
+		p.Name,
+		p.Description,
+		strconv.FormatFloat(p.Price, 'f', -1, 64),
+		strconv.Itoa(p.Stock),
+		strconv.FormatUint(p.CategoryId, 10),
+		strings.Join(images, "|"),
+		strings.Join(attributes, "|"),
+	})
+}
+
+func (w *csvExportWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type jsonlExportWriter struct {
+	enc *json.Encoder
+}
+
+func (w *jsonlExportWriter) Write(detail domain.ProductDetail) error {
+	p := jsonlProduct{
+		Sku:         detail.Product.Sku,
+		Name:        detail.Product.Name,
+		Description: detail.Product.Description,
+		Price:       detail.Product.Price,
+		Stock:       detail.Product.Stock,
+		CategoryId:  detail.Product.CategoryId,
+		Images:      make([]string, 0, len(detail.Images)),
+	}
+	for _, img := range detail.Images {
+		p.Images = append(p.Images, img.ImageUrl)
+	}
+	for _, at := range detail.Attributes {
+		p.Attributes = append(p.Attributes, jsonlAttribute{Name: at.Name, Value: at.Value})
+	}
+
+	return w.enc.Encode(p)
+}
+
+func (w *jsonlExportWriter) Flush() error {
+	return nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// DeviceIdHeader 匿名访客携带设备 id 的请求头
+const DeviceIdHeader = "X-Device-Id"
+
+const (
+	deviceIdBytes  = 12
+	deviceSigBytes = 16
+)
+
+// DeviceIds 签发和校验匿名设备 id。设备 id 由服务端随机生成并签名，
+// 客户端无法伪造他人的设备 id 来读取别人的浏览记录或游客购物车
+type DeviceIds struct {
+	secretKey []byte
+}
+
+func NewDeviceIds(secretKey []byte) *DeviceIds {
+	return &DeviceIds{
+		secretKey: secretKey,
+	}
+}
+
+// Issue 签发新的设备 id，格式为 随机部分.签名
+func (d *DeviceIds) Issue() (string, error) {
+	var buf [deviceIdBytes]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", err
+	}
+	id := base64.RawURLEncoding.EncodeToString(buf[:])
+
+	return id + "." + d.sign(id), nil
+}
+
+// Verify 校验签名，返回随机部分作为设备标识
+func (d *DeviceIds) Verify(signed string) (string, bool) {
+	id, sig, ok := strings.Cut(signed, ".")
+	if !ok || id == "" || !hmac.Equal([]byte(sig), []byte(d.sign(id))) {
+		return "", false
+	}
+
+	return id, true
+}
+
+func (d *DeviceIds) sign(id string) string {
+	h := hmac.New(sha256.New, d.secretKey)
+	h.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:deviceSigBytes])
+}
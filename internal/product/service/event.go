@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"mall/internal/product/domain"
+	"mall/pkg/logger"
+)
+
+// EventHook 商品事件的订阅方，如热度排行、购物车清理，只处理自己关心的事件类型
+type EventHook interface {
+	OnProductEvent(ctx context.Context, evt domain.ProductEvent) error
+}
+
+// EventPublisher 把商品事件同步分发给所有订阅方
+// 订阅方失败只记录日志，不影响发出事件的业务
+type EventPublisher struct {
+	hooks []EventHook
+	l     logger.Logger
+}
+
+func NewEventPublisher(hooks []EventHook, l logger.Logger) *EventPublisher {
+	return &EventPublisher{
+		hooks: hooks,
+		l:     l,
+	}
+}
+
+func (p *EventPublisher) Publish(ctx context.Context, evt domain.ProductEvent) {
+	for _, hook := range p.hooks {
+		if err := hook.OnProductEvent(ctx, evt); err != nil {
+			p.l.Error("商品事件处理失败", logger.String("type", evt.Type),
+				logger.Field{Key: "productId", Val: evt.ProductId}, logger.Error(err))
+		}
+	}
+}
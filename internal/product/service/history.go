@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"mall/internal/product/domain"
+	"mall/internal/product/repository"
+)
+
+var ErrInvalidViewer = errors.New("login or provide a valid device id")
+
+// Viewer 浏览者，匿名访客的 DeviceId 为服务端签发的设备 id
+type Viewer = repository.Viewer
+
+type ViewHistoryService struct {
+	repo        *repository.ViewHistoryRepository
+	productRepo *repository.ProductRepository
+	devices     *DeviceIds
+}
+
+func NewViewHistoryService(repo *repository.ViewHistoryRepository, productRepo *repository.ProductRepository,
+	devices *DeviceIds) *ViewHistoryService {
+	return &ViewHistoryService{
+		repo:        repo,
+		productRepo: productRepo,
+		devices:     devices,
+	}
+}
+
+// NewDevice 为匿名访客签发设备 id
+func (svc *ViewHistoryService) NewDevice() (string, error) {
+	return svc.devices.Issue()
+}
+
+// RecordView 记录一次商品详情浏览，既没有登录也没有设备 id 时不记录
+func (svc *ViewHistoryService) RecordView(ctx context.Context, viewer Viewer, productId uint64) error {
+	viewer, ok := svc.resolve(viewer)
+	if !ok {
+		return nil
+	}
+
+	return svc.repo.AddView(ctx, viewer, productId)
+}
+
+// GetHistory 最近浏览的商品，按浏览时间倒序，已删除的商品不返回
+func (svc *ViewHistoryService) GetHistory(ctx context.Context, viewer Viewer) ([]domain.ViewedProduct, error) {
+	viewer, ok := svc.resolve(viewer)
+	if !ok {
+		return nil, ErrInvalidViewer
+	}
+
+	views, err := svc.repo.FindViews(ctx, viewer)
+	if err != nil || len(views) == 0 {
+		return []domain.ViewedProduct{}, err
+	}
+
+	ids := make([]uint64, 0, len(views))
+	for _, v := range views {
+		ids = append(ids, v.ProductId)
+	}
+	summaries, err := svc.productRepo.FindProductSummaries(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	summaryOf := make(map[uint64]domain.ProductSummary, len(summaries))
+	for _, s := range summaries {
+		summaryOf[s.Id] = s
+	}
+
+	res := make([]domain.ViewedProduct, 0, len(views))
+	for _, v := range views {
+		s, ok := summaryOf[v.ProductId]
+		if !ok || s.IsDeleted {
+			continue
+		}
+		v.Name = s.Name
+		v.Price = s.Price
+		v.ImageUrl = s.ImageUrl
+		v.Available = s.IsActive
+		res = append(res, v)
+	}
+
+	return res, nil
+}
+
+func (svc *ViewHistoryService) ClearHistory(ctx context.Context, viewer Viewer) error {
+	viewer, ok := svc.resolve(viewer)
+	if !ok {
+		return ErrInvalidViewer
+	}
+
+	return svc.repo.ClearViews(ctx, viewer)
+}
+
+func (svc *ViewHistoryService) Name() string {
+	return "history"
+}
+
+// AfterLogin 登录后把该设备匿名浏览的记录合并到用户名下，没有需要告诉客户端的结果
+func (svc *ViewHistoryService) AfterLogin(ctx context.Context, uid uint64, deviceId string) (any, error) {
+	id, ok := svc.devices.Verify(deviceId)
+	if !ok {
+		return nil, nil
+	}
+
+	return nil, svc.repo.MergeDeviceViews(ctx, id, uid)
+}
+
+// resolve 登录用户直接使用用户 id，匿名访客校验设备 id 的签名后换成设备标识
+func (svc *ViewHistoryService) resolve(viewer Viewer) (Viewer, bool) {
+	if viewer.UserId > 0 {
+		return viewer, true
+	}
+	deviceId, ok := svc.devices.Verify(viewer.DeviceId)
+	if !ok {
+		return Viewer{}, false
+	}
+
+	return Viewer{DeviceId: deviceId}, true
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strconv"
+
+	"mall/internal/product/domain"
+	"mall/internal/product/repository"
+	"mall/pkg/imagex"
+	"mall/pkg/storage"
+)
+
+var (
+	ErrImageTooLarge       = errors.New("image is too large")
+	ErrImageTypeNotAllowed = errors.New("image type is not allowed")
+	ErrImageNotFound       = repository.ErrImageNotFound
+	ErrImageMismatch       = repository.ErrImageMismatch
+)
+
+const (
+	// MaxImageSize 单张图片最大 5MB
+	MaxImageSize = 5 << 20
+	// 解码后的像素数上限，压缩率很高的小文件也可能解码出占用大量内存的图片
+	maxImagePixels = 40_000_000
+	thumbQuality   = 85
+)
+
+// 缩略图尺寸，为最长边的像素数
+var thumbSizes = []int{128, 256, 512}
+
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+type ImageService struct {
+	blob storage.Blob
+	repo *repository.ProductRepository
+}
+
+func NewImageService(blob storage.Blob, repo *repository.ProductRepository) *ImageService {
+	return &ImageService{
+		blob: blob,
+		repo: repo,
+	}
+}
+
+// Upload 校验并保存图片，同时生成多种尺寸的缩略图
+// 按内容哈希生成 key，相同的图片只会存储一份
+func (svc *ImageService) Upload(ctx context.Context, data []byte) (domain.UploadedImage, error) {
+	if len(data) > MaxImageSize {
+		return domain.UploadedImage{}, ErrImageTooLarge
+	}
+
+	// 根据内容判断类型，不信任客户端上传的 Content-Type
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedImageTypes[contentType]
+	if !ok {
+		return domain.UploadedImage{}, ErrImageTypeNotAllowed
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	key := svc.key(hash, ext)
+
+	res := domain.UploadedImage{
+		Key:         key,
+		Url:         svc.blob.URL(key),
+		Hash:        hash,
+		ContentType: contentType,
+		Size:        len(data),
+		Thumbnails:  make(map[int]string, len(thumbSizes)),
+	}
+	for _, size := range thumbSizes {
+		res.Thumbnails[size] = svc.blob.URL(svc.thumbKey(hash, size))
+	}
+
+	// 原图最后写入，原图存在说明缩略图已经生成过
+	exists, err := svc.blob.Exists(ctx, key)
+	if err != nil {
+		return domain.UploadedImage{}, err
+	}
+	if exists {
+		return res, nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return domain.UploadedImage{}, ErrImageTypeNotAllowed
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxImagePixels {
+		return domain.UploadedImage{}, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return domain.UploadedImage{}, ErrImageTypeNotAllowed
+	}
+	for _, size := range thumbSizes {
+		thumb, err := imagex.EncodeJPEG(imagex.Thumbnail(img, size), thumbQuality)
+		if err != nil {
+			return domain.UploadedImage{}, err
+		}
+		err = svc.blob.Put(ctx, svc.thumbKey(hash, size), bytes.NewReader(thumb), int64(len(thumb)), "image/jpeg")
+		if err != nil {
+			return domain.UploadedImage{}, err
+		}
+	}
+
+	err = svc.blob.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+	if err != nil {
+		return domain.UploadedImage{}, err
+	}
+
+	return res, nil
+}
+
+// ReorderImages 按 imageIds 的顺序重新排列商品图片，imageIds 必须包含商品的全部图片
+func (svc *ImageService) ReorderImages(ctx context.Context, merchantId uint64, productId string, imageIds []uint64) error {
+	id, err := strconv.Atoi(productId)
+	if err != nil {
+		return err
+	}
+	if err := checkOwner(ctx, svc.repo, merchantId, uint64(id)); err != nil {
+		return err
+	}
+
+	return svc.repo.ReorderImages(ctx, uint64(id), imageIds)
+}
+
+// SetPrimaryImage 设置商品主图
+func (svc *ImageService) SetPrimaryImage(ctx context.Context, merchantId uint64, productId, imageId string) error {
+	pid, err := strconv.Atoi(productId)
+	if err != nil {
+		return err
+	}
+	iid, err := strconv.Atoi(imageId)
+	if err != nil {
+		return err
+	}
+	if err := checkOwner(ctx, svc.repo, merchantId, uint64(pid)); err != nil {
+		return err
+	}
+
+	return svc.repo.SetPrimaryImage(ctx, uint64(pid), uint64(iid))
+}
+
+func (svc *ImageService) key(hash, ext string) string {
+	return fmt.Sprintf("images/%s/%s%s", hash[:2], hash, ext)
+}
+
+func (svc *ImageService) thumbKey(hash string, size int) string {
+	return fmt.Sprintf("images/%s/%s_%d.jpg", hash[:2], hash, size)
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"mall/internal/product/domain"
+	"mall/internal/product/repository"
+	"mall/pkg/taskx"
+)
+
+var (
+	ErrImportJobNotFound   = repository.ErrImportJobNotFound
+	ErrImportTooManyRows   = errors.New("too many rows in import file")
+	ErrImportReportMissing = errors.New("import job has no error report")
+	ErrImportUnavailable   = errors.New("server is shutting down, try again later")
+)
+
+const (
+	// MaxImportFileSize 导入文件最大 20MB
+	MaxImportFileSize = 20 << 20
+	maxImportRows     = 10000
+	importTimeout     = time.Minute * 30
+	// 每处理多少行保存一次进度
+	importProgressStep = 50
+	exportBatchSize    = 200
+)
+
+type ImportService struct {
+	repo    *repository.ProductRepository
+	jobRepo *repository.ImportJobRepository
+	tasks   *taskx.Group
+}
+
+func NewImportService(repo *repository.ProductRepository, jobRepo *repository.ImportJobRepository, tasks *taskx.Group) *ImportService {
+	return &ImportService{
+		repo:    repo,
+		jobRepo: jobRepo,
+		tasks:   tasks,
+	}
+}
+
+// StartImport 解析文件并创建导入任务，实际导入在后台执行，通过 GetImportJob 查询进度
+// dryRun 为 true 时只校验，不写入数据
+func (svc *ImportService) StartImport(ctx context.Context, uid uint64, format string, dryRun bool, data []byte) (domain.ImportJob, error) {
+	rows, parseErrs, err := parseImportFile(format, data)
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+	if len(rows)+len(parseErrs) > maxImportRows {
+		return domain.ImportJob{}, ErrImportTooManyRows
+	}
+
+	job := domain.ImportJob{
+		Id:       uuid.New().String(),
+		UserId:   uid,
+		Format:   format,
+		DryRun:   dryRun,
+		Status:   domain.ImportStatusRunning,
+		Total:    len(rows) + len(parseErrs),
+		Failed:   len(parseErrs),
+		CreateAt: time.Now().UnixMilli(),
+	}
+	job.Processed = job.Failed
+	if err := svc.jobRepo.Save(ctx, job); err != nil {
+		return domain.ImportJob{}, err
+	}
+
+	// 请求结束后 ctx 会被取消，导入在 tasks 中执行，服务退出时等待导入结束
+	started := svc.tasks.Go(func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, importTimeout)
+		defer cancel()
+		svc.runImport(ctx, job, rows, parseErrs)
+	})
+	if !started {
+		svc.failJob(ctx, job, ErrImportUnavailable)
+		return domain.ImportJob{}, ErrImportUnavailable
+	}
+
+	return job, nil
+}
+
+func (svc *ImportService) GetImportJob(ctx context.Context, uid uint64, jobId string) (domain.ImportJob, error) {
+	job, err := svc.jobRepo.Find(ctx, jobId)
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+	// 只能查看自己的任务
+	if job.UserId != uid {
+		return domain.ImportJob{}, ErrImportJobNotFound
+	}
+
+	return job, nil
+}
+
+// GetImportReport 返回导入任务的错误报告（CSV）
+func (svc *ImportService) GetImportReport(ctx context.Context, uid uint64, jobId string) ([]byte, error) {
+	job, err := svc.GetImportJob(ctx, uid, jobId)
+	if err != nil {
+		return nil, err
+	}
+	if !job.HasReport {
+		return nil, ErrImportReportMissing
+	}
+
+	return svc.jobRepo.FindReport(ctx, job.Id)
+}
+
+func (svc *ImportService) runImport(ctx context.Context, job domain.ImportJob, rows []domain.ImportRow, errs []domain.ImportError) {
+	categories, err := svc.repo.AcquireAllCategory(ctx)
+	if err != nil && !errors.Is(err, repository.ErrCategoriesNotFound) {
+		svc.failJob(ctx, job, err)
+		return
+	}
+	categoryIds := make(map[uint64]struct{}, len(categories))
+	for _, cg := range categories {
+		categoryIds[cg.ID] = struct{}{}
+	}
+	templates, err := svc.repo.FindAllAttributeTemplates(ctx)
+	if err != nil {
+		svc.failJob(ctx, job, err)
+		return
+	}
+
+	for i, row := range rows {
+		// 超时或服务退出时停止，已导入的行保留
+		if err := ctx.Err(); err != nil {
+			svc.failJob(context.WithoutCancel(ctx), job, err)
+			return
+		}
+
+		created, err := svc.importRow(ctx, job, row, categoryIds, templates)
+		switch {
+		case err != nil:
+			job.Failed++
+			errs = append(errs, domain.ImportError{Line: row.Line, Sku: row.Sku, Error: err.Error()})
+		case created:
+			job.Created++
+		default:
+			job.Updated++
+		}
+		job.Processed++
+
+		if (i+1)%importProgressStep == 0 {
+			_ = svc.jobRepo.Save(ctx, job)
+		}
+	}
+
+	if len(errs) > 0 {
+		if err := svc.saveReport(ctx, job.Id, errs); err != nil {
+			svc.failJob(ctx, job, err)
+			return
+		}
+		job.HasReport = true
+	}
+
+	job.Status = domain.ImportStatusFinished
+	job.FinishAt = time.Now().UnixMilli()
+	_ = svc.jobRepo.Save(ctx, job)
+}
+
+// importRow 校验并按 SKU 新建或更新一行商品，返回是否为新建
+func (svc *ImportService) importRow(ctx context.Context, job domain.ImportJob, row domain.ImportRow, categoryIds map[uint64]struct{},
+	templates map[uint64][]domain.AttributeTemplate) (bool, error) {
+	if err := validateImportRow(row, categoryIds); err != nil {
+		return false, err
+	}
+	attributes, err := normalizeAttributes(templates[row.CategoryId], row.Attributes)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := svc.repo.FindProductBySku(ctx, job.UserId, row.Sku)
+	if err != nil && !errors.Is(err, repository.ErrProductNotFound) {
+		return false, err
+	}
+	created := errors.Is(err, repository.ErrProductNotFound)
+	if job.DryRun {
+		return created, nil
+	}
+
+	if attributes == nil {
+		attributes = []domain.ProductAttribute{}
+	}
+	images := row.Images
+	if images == nil {
+		images = []string{}
+	}
+
+	if created {
+		_, err = svc.repo.InsertProduct(ctx, domain.Product{
+			MerchantId:  job.UserId,
+			Sku:         row.Sku,
+			Name:        row.Name,
+			Description: row.Description,
+			Price:       row.Price,
+			Stock:       row.Stock,
+			CategoryId:  row.CategoryId,
+		}, attributes, images, job.UserId, "")
+		return true, err
+	}
+
+	_, err = svc.repo.UpdateProduct(ctx, domain.ProductUpdate{
+		Id:          existing.Id,
+		OperatorId:  job.UserId,
+		Version:     existing.Version,
+		Name:        &row.Name,
+		Description: &row.Description,
+		Price:       &row.Price,
+		Stock:       &row.Stock,
+		CategoryId:  &row.CategoryId,
+		Attributes:  attributes,
+		Images:      images,
+	})
+	return false, err
+}
+
+func validateImportRow(row domain.ImportRow, categoryIds map[uint64]struct{}) error {
+	switch {
+	case row.Sku == "" || len(row.Sku) > 64:
+		return errors.New("sku is required and must be at most 64 characters")
+	case row.Name == "":
+		return errors.New("name is required")
+	case row.Price <= 0:
+		return errors.New("price must be positive")
+	case row.Stock < 0:
+		return errors.New("stock must not be negative")
+	}
+	if _, ok := categoryIds[row.CategoryId]; !ok {
+		return fmt.Errorf("category %d does not exist", row.CategoryId)
+	}
+	for _, img := range row.Images {
+		u, err := url.ParseRequestURI(img)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid image url %q", img)
+		}
+	}
+
+	return nil
+}
+
+func (svc *ImportService) saveReport(ctx context.Context, jobId string, errs []domain.ImportError) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"line", "sku", "error"})
+	for _, e := range errs {
+		_ = w.Write([]string{strconv.Itoa(e.Line), e.Sku, e.Error})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	return svc.jobRepo.SaveReport(ctx, jobId, buf.Bytes())
+}
+
+func (svc *ImportService) failJob(ctx context.Context, job domain.ImportJob, err error) {
+	job.Status = domain.ImportStatusFailed
+	job.Error = err.Error()
+	job.FinishAt = time.Now().UnixMilli()
+	_ = svc.jobRepo.Save(ctx, job)
+}
+
+// Export 将商家所有未删除的商品按 format 写入 w，格式与导入文件相同
+func (svc *ImportService) Export(ctx context.Context, merchantId uint64, format string, w io.Writer) error {
+	ew, err := newExportWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	var lastId uint64
+	for {
+		products, err := svc.repo.FindProductsAfter(ctx, merchantId, lastId, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, p := range products {
+			if err := ew.Write(p); err != nil {
+				return err
+			}
+		}
+		if len(products) < exportBatchSize {
+			return ew.Flush()
+		}
+		lastId = products[len(products)-1].Product.Id
+	}
+}
@@ -2,17 +2,31 @@ package service
 
 import (
 	"context"
+	"errors"
 	"mall/internal/product/domain"
 	"mall/internal/product/repository"
 	"strconv"
+	"time"
 )
 
 var (
-	ErrCategoryDuplicateName = repository.ErrCategoryDuplicateName
-	ErrCategoriesNotFound    = repository.ErrCategoriesNotFound
-	ErrCategoryNotFound      = repository.ErrCategoryNotFound
-	ErrProductNotFound       = repository.ErrProductNotFound
-	ErrProductNotOnList      = repository.ErrProductNotOnList
+	ErrCategoryDuplicateName  = repository.ErrCategoryDuplicateName
+	ErrCategoriesNotFound     = repository.ErrCategoriesNotFound
+	ErrCategoryNotFound       = repository.ErrCategoryNotFound
+	ErrProductNotFound        = repository.ErrProductNotFound
+	ErrProductNotOnList       = repository.ErrProductNotOnList
+	ErrProductVersionConflict = repository.ErrProductVersionConflict
+	ErrProductNotInTrash      = repository.ErrProductNotInTrash
+	ErrInvalidSchedule        = errors.New("invalid list or delist time")
+	ErrProductNotOwned        = errors.New("product does not belong to the merchant")
+)
+
+const (
+	// 回收站中的商品保留 30 天后彻底删除
+	trashRetention = time.Hour * 24 * 30
+	purgeBatchSize = 100
+	// 每次最多处理的定时上下架数量
+	scheduleBatchSize = 100
 )
 
 type ProductService struct {
@@ -33,8 +47,15 @@ func (svc *ProductService) GetCategories(ctx context.Context) ([]domain.Category
 	return svc.repo.AcquireAllCategory(ctx)
 }
 
-func (svc *ProductService) AddProduct(ctx context.Context, product domain.Product, attributes []domain.ProductAttribute, images []string) error {
-	return svc.repo.InsertProduct(ctx, product, attributes, images)
+// AddProduct 创建商品，商品归属于创建它的商家，属性按类目模板校验
+func (svc *ProductService) AddProduct(ctx context.Context, product domain.Product, attributes []domain.ProductAttribute, images []string, uid uint64, idemKey string) (uint64, error) {
+	attributes, err := svc.checkAttributes(ctx, product.CategoryId, attributes)
+	if err != nil {
+		return 0, err
+	}
+
+	product.MerchantId = uid
+	return svc.repo.InsertProduct(ctx, product, attributes, images, uid, idemKey)
 }
 
 func (svc *ProductService) GetProductDetail(ctx context.Context, productId string) (domain.ProductDetail, error) {
@@ -46,33 +67,205 @@ func (svc *ProductService) GetProductDetail(ctx context.Context, productId strin
 	return svc.repo.FindProductById(ctx, uint64(id))
 }
 
-func (svc *ProductService) SearchProducts(ctx context.Context, name string) ([]domain.ProductApproximate, error) {
-	return svc.repo.SearchProducts(ctx, name)
+func (svc *ProductService) UpdateProduct(ctx context.Context, productId string, upd domain.ProductUpdate) (int64, error) {
+	id, err := strconv.Atoi(productId)
+	if err != nil {
+		return 0, err
+	}
+	upd.Id = uint64(id)
+	if err := checkOwner(ctx, svc.repo, upd.OperatorId, upd.Id); err != nil {
+		return 0, err
+	}
+
+	// 修改属性或类目时，按修改后的类目模板校验修改后的属性
+	if upd.Attributes != nil || upd.CategoryId != nil {
+		categoryId, attributes, err := svc.repo.FindProductAttributes(ctx, upd.Id)
+		if err != nil {
+			return 0, err
+		}
+		if upd.CategoryId != nil {
+			categoryId = *upd.CategoryId
+		}
+		if upd.Attributes != nil {
+			attributes = upd.Attributes
+		}
+		attributes, err = svc.checkAttributes(ctx, categoryId, attributes)
+		if err != nil {
+			return 0, err
+		}
+		if upd.Attributes != nil {
+			upd.Attributes = attributes
+		}
+	}
+
+	return svc.repo.UpdateProduct(ctx, upd)
+}
+
+func (svc *ProductService) GetProductHistory(ctx context.Context, merchantId uint64, productId string) ([]domain.ProductHistory, error) {
+	id, err := strconv.Atoi(productId)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOwner(ctx, svc.repo, merchantId, uint64(id)); err != nil {
+		return nil, err
+	}
+
+	return svc.repo.FindProductHistory(ctx, uint64(id))
+}
+
+func (svc *ProductService) SearchProducts(ctx context.Context, search domain.ProductSearch) ([]domain.ProductApproximate, error) {
+	search, err := svc.checkFilters(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc.repo.SearchProducts(ctx, search)
 }
 
-func (svc *ProductService) DeleteProduct(ctx context.Context, id string) error {
+func (svc *ProductService) DeleteProduct(ctx context.Context, merchantId uint64, id string) error {
 	productId, err := strconv.Atoi(id)
 	if err != nil {
 		return err
 	}
+	if err := checkOwner(ctx, svc.repo, merchantId, uint64(productId)); err != nil {
+		return err
+	}
 
 	return svc.repo.DeleteProductById(ctx, uint64(productId))
 }
 
-func (svc *ProductService) ProductOnList(ctx context.Context, productId string) error {
+func (svc *ProductService) RestoreProduct(ctx context.Context, merchantId uint64, id string) error {
+	productId, err := strconv.Atoi(id)
+	if err != nil {
+		return err
+	}
+	if err := checkOwner(ctx, svc.repo, merchantId, uint64(productId)); err != nil {
+		return err
+	}
+
+	return svc.repo.RestoreProduct(ctx, uint64(productId))
+}
+
+func (svc *ProductService) GetTrash(ctx context.Context, merchantId uint64, page, size int) ([]domain.DeletedProduct, error) {
+	products, err := svc.repo.FindDeletedProducts(ctx, merchantId, (page-1)*size, size)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range products {
+		products[i].PurgeAt = products[i].DeletedAt + trashRetention.Milliseconds()
+	}
+
+	return products, nil
+}
+
+// PurgeTrash 彻底删除超过保留期的商品，返回删除的商品 ID
+func (svc *ProductService) PurgeTrash(ctx context.Context) ([]uint64, error) {
+	before := time.Now().Add(-trashRetention)
+
+	var purged []uint64
+	for {
+		ids, err := svc.repo.PurgeDeletedProducts(ctx, before, purgeBatchSize)
+		purged = append(purged, ids...)
+		if err != nil || len(ids) < purgeBatchSize {
+			return purged, err
+		}
+	}
+}
+
+func (svc *ProductService) ProductOnList(ctx context.Context, merchantId uint64, productId string) error {
 	id, err := strconv.Atoi(productId)
 	if err != nil {
 		return err
 	}
+	if err := checkOwner(ctx, svc.repo, merchantId, uint64(id)); err != nil {
+		return err
+	}
 
 	return svc.repo.ProductOn(ctx, uint64(id))
 }
 
-func (svc *ProductService) ProductRemoveList(ctx context.Context, productId string) error {
+func (svc *ProductService) ProductRemoveList(ctx context.Context, merchantId uint64, productId string) error {
 	id, err := strconv.Atoi(productId)
 	if err != nil {
 		return err
 	}
+	if err := checkOwner(ctx, svc.repo, merchantId, uint64(id)); err != nil {
+		return err
+	}
 
 	return svc.repo.ProductRemove(ctx, uint64(id))
 }
+
+// ScheduleProduct 设置定时上下架，时间为毫秒时间戳，0 表示取消
+func (svc *ProductService) ScheduleProduct(ctx context.Context, merchantId uint64, productId string, listAt, delistAt int64) error {
+	id, err := strconv.Atoi(productId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+	if listAt < 0 || delistAt < 0 || (listAt > 0 && listAt <= now) || (delistAt > 0 && delistAt <= now) {
+		return ErrInvalidSchedule
+	}
+	if listAt > 0 && delistAt > 0 && delistAt <= listAt {
+		return ErrInvalidSchedule
+	}
+	if err := checkOwner(ctx, svc.repo, merchantId, uint64(id)); err != nil {
+		return err
+	}
+
+	return svc.repo.ScheduleProduct(ctx, uint64(id), listAt, delistAt)
+}
+
+// SetFlashStock 商家开启或关闭商品的秒杀库存预扣
+func (svc *ProductService) SetFlashStock(ctx context.Context, merchantId uint64, productId string, enabled bool) error {
+	id, err := strconv.Atoi(productId)
+	if err != nil {
+		return err
+	}
+	if err := checkOwner(ctx, svc.repo, merchantId, uint64(id)); err != nil {
+		return err
+	}
+
+	return svc.repo.SetFlashStock(ctx, uint64(id), enabled)
+}
+
+// SyncFlashStocks 用数据库库存校准秒杀商品的预扣库存，返回校准的商品数
+func (svc *ProductService) SyncFlashStocks(ctx context.Context) (int, error) {
+	return svc.repo.SyncFlashStocks(ctx)
+}
+
+// ApplyDueSchedules 执行所有到期的定时上下架，返回处理的数量
+func (svc *ProductService) ApplyDueSchedules(ctx context.Context) (int, error) {
+	var total int
+	for {
+		n, err := svc.repo.ApplyDueSchedules(ctx, time.Now(), scheduleBatchSize)
+		total += n
+		if err != nil || n == 0 {
+			return total, err
+		}
+	}
+}
+
+// GetMerchantProducts 商家后台的商品列表，status 见 domain.MerchantProductOnList 等，为空时返回全部
+func (svc *ProductService) GetMerchantProducts(ctx context.Context, merchantId uint64, status string, page, size int) ([]domain.MerchantProduct, error) {
+	return svc.repo.FindMerchantProducts(ctx, merchantId, status, (page-1)*size, size)
+}
+
+func (svc *ProductService) GetMerchantProductCounts(ctx context.Context, merchantId uint64) (domain.MerchantProductCounts, error) {
+	return svc.repo.CountMerchantProducts(ctx, merchantId)
+}
+
+// checkOwner 校验商品属于该商家，所有修改商品的操作之前调用
+func checkOwner(ctx context.Context, repo *repository.ProductRepository, merchantId, productId uint64) error {
+	owner, err := repo.FindProductOwner(ctx, productId)
+	if err != nil {
+		return err
+	}
+	if owner != merchantId {
+		return ErrProductNotOwned
+	}
+
+	return nil
+}
@@ -1,5 +1,28 @@
 package product
 
-import "mall/internal/product/web"
+import (
+	"mall/internal/product/job"
+	"mall/internal/product/repository/cache"
+	"mall/internal/product/service"
+	"mall/internal/product/web"
+)
 
 type Handler = web.ProductHandler // 暴露出去给 ioc 使用
+
+type PurgeJob = job.PurgeJob
+
+type ScheduleJob = job.ScheduleJob
+
+type FlashStockJob = job.FlashStockJob
+
+type ViewHistoryService = service.ViewHistoryService
+
+type EventHook = service.EventHook
+
+// ProductCache 由 ioc 创建一个，各模块构造商品仓储时共用
+type ProductCache = cache.ProductCache
+
+// DeviceIds 由 ioc 按配置的密钥创建，签发和校验匿名设备 id
+type DeviceIds = service.DeviceIds
+
+var NewDeviceIds = service.NewDeviceIds
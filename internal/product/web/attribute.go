@@ -0,0 +1,134 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth"
+	"mall/internal/product/domain"
+	"mall/internal/product/service"
+	"mall/pkg/ginx"
+)
+
+func (ctl *ProductHandler) GetAttributeTemplates() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		templates, err := ctl.svc.GetAttributeTemplates(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(templates)))
+	}
+}
+
+// SetAttributeTemplates 整体替换类目的属性模板，数组顺序即展示顺序
+func (ctl *ProductHandler) SetAttributeTemplates() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Attribute struct {
+			Name       string   `json:"name"`
+			Type       string   `json:"type"`
+			Options    []string `json:"options"`
+			Unit       string   `json:"unit"`
+			Required   bool     `json:"required"`
+			Filterable bool     `json:"filterable"`
+		}
+		type Req struct {
+			Attributes []Attribute `json:"attributes"`
+		}
+		var req Req
+		if err := c.Bind(&req); err != nil {
+			return
+		}
+
+		if _, ok := auth.AdminClaim(c); !ok {
+			return
+		}
+
+		templates := make([]domain.AttributeTemplate, 0, len(req.Attributes))
+		for _, attr := range req.Attributes {
+			templates = append(templates, domain.AttributeTemplate{
+				Name:       attr.Name,
+				Type:       attr.Type,
+				Options:    attr.Options,
+				Unit:       attr.Unit,
+				Required:   attr.Required,
+				Filterable: attr.Filterable,
+			})
+		}
+
+		err := ctl.svc.SetAttributeTemplates(c.Request.Context(), c.Param("id"), templates)
+		switch {
+		case errors.Is(err, service.ErrCategoryNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("category not found")))
+			return
+		case errors.Is(err, service.ErrInvalidAttributeTemplate):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg(err.Error())))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("attribute templates saved")))
+	}
+}
+
+// parseSearch 解析搜索参数，属性筛选的写法为
+// attr[颜色]=红色,蓝色 取值任一匹配，attr_min[屏幕尺寸]=13&attr_max[屏幕尺寸]=16 数值范围
+func parseSearch(c *gin.Context) (domain.ProductSearch, error) {
+	search := domain.ProductSearch{
+		Name: c.Query("name"),
+	}
+	if s := c.Query("categoryId"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return search, errors.New("invalid category id")
+		}
+		search.CategoryId = id
+	}
+
+	values := c.QueryMap("attr")
+	mins := c.QueryMap("attr_min")
+	maxes := c.QueryMap("attr_max")
+	names := make(map[string]struct{}, len(values)+len(mins)+len(maxes))
+	for _, m := range []map[string]string{values, mins, maxes} {
+		for name := range m {
+			names[name] = struct{}{}
+		}
+	}
+
+	for name := range names {
+		f := domain.AttributeFilter{Name: name}
+		if v, ok := values[name]; ok {
+			f.Values = strings.Split(v, ",")
+		}
+		if v, ok := mins[name]; ok {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return search, fmt.Errorf("range of %s must be a number", name)
+			}
+			f.Min = &n
+		}
+		if v, ok := maxes[name]; ok {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return search, fmt.Errorf("range of %s must be a number", name)
+			}
+			f.Max = &n
+		}
+		search.Attributes = append(search.Attributes, f)
+	}
+	// 保证生成的 SQL 稳定
+	sort.Slice(search.Attributes, func(i, j int) bool {
+		return search.Attributes[i].Name < search.Attributes[j].Name
+	})
+
+	return search, nil
+}
@@ -0,0 +1,140 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth"
+	"mall/internal/product/service"
+	"mall/pkg/ginx"
+)
+
+func (ctl *ProductHandler) ImportProducts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		format := c.DefaultQuery("format", service.FormatCSV)
+		dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("file is required")))
+			return
+		}
+		if file.Size > service.MaxImportFileSize {
+			c.JSON(http.StatusRequestEntityTooLarge, ginx.GetResponse(ginx.WithStatus(http.StatusRequestEntityTooLarge), ginx.WithMsg("file is too large")))
+			return
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+		defer f.Close()
+
+		data, err := io.ReadAll(io.LimitReader(f, service.MaxImportFileSize))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		job, err := ctl.importSvc.StartImport(c.Request.Context(), claim.Id, format, dryRun, data)
+		switch {
+		case errors.Is(err, service.ErrUnsupportedFormat):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("format must be csv or jsonl")))
+			return
+		case errors.Is(err, service.ErrImportUnavailable):
+			c.JSON(http.StatusServiceUnavailable, ginx.GetResponse(ginx.WithStatus(http.StatusServiceUnavailable), ginx.WithMsg(err.Error())))
+			return
+		case errors.Is(err, service.ErrImportTooManyRows):
+			c.JSON(http.StatusRequestEntityTooLarge, ginx.GetResponse(ginx.WithStatus(http.StatusRequestEntityTooLarge), ginx.WithMsg("too many rows")))
+			return
+		case err != nil:
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg(err.Error())))
+			return
+		}
+
+		c.JSON(http.StatusAccepted, ginx.GetResponse(ginx.WithStatus(http.StatusAccepted), ginx.WithMsg("import started"), ginx.WithData(job)))
+	}
+}
+
+func (ctl *ProductHandler) GetImportJob() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		job, err := ctl.importSvc.GetImportJob(c.Request.Context(), claim.Id, c.Param("jobId"))
+		switch {
+		case errors.Is(err, service.ErrImportJobNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("import job not found")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("get import job successfully"), ginx.WithData(job)))
+	}
+}
+
+func (ctl *ProductHandler) GetImportReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		jobId := c.Param("jobId")
+		report, err := ctl.importSvc.GetImportReport(c.Request.Context(), claim.Id, jobId)
+		switch {
+		case errors.Is(err, service.ErrImportJobNotFound), errors.Is(err, service.ErrImportReportMissing):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("report not found")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="import-%s-errors.csv"`, jobId))
+		c.Data(http.StatusOK, "text/csv", report)
+	}
+}
+
+func (ctl *ProductHandler) ExportProducts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		format := c.DefaultQuery("format", service.FormatCSV)
+		contentType := "text/csv"
+		switch format {
+		case service.FormatCSV:
+		case service.FormatJSONL:
+			contentType = "application/x-ndjson"
+		default:
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("format must be csv or jsonl")))
+			return
+		}
+
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="products.%s"`, format))
+		c.Status(http.StatusOK)
+		// 已经开始写响应体，出错时只能中断连接
+		if err := ctl.importSvc.Export(c.Request.Context(), claim.Id, format, c.Writer); err != nil {
+			_ = c.Error(err)
+			c.Abort()
+		}
+	}
+}
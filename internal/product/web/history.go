@@ -0,0 +1,66 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth"
+	"mall/internal/product/service"
+	"mall/pkg/ginx"
+)
+
+func (ctl *ProductHandler) GetViewHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		views, err := ctl.historySvc.GetHistory(c.Request.Context(), viewer(c))
+		switch {
+		case errors.Is(err, service.ErrInvalidViewer):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("login or provide a valid X-Device-Id header")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(views)))
+	}
+}
+
+func (ctl *ProductHandler) ClearViewHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := ctl.historySvc.ClearHistory(c.Request.Context(), viewer(c))
+		switch {
+		case errors.Is(err, service.ErrInvalidViewer):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("login or provide a valid X-Device-Id header")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("view history cleared")))
+	}
+}
+
+func (ctl *ProductHandler) NewDevice() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deviceId, err := ctl.historySvc.NewDevice()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(map[string]any{
+			"deviceId": deviceId,
+		})))
+	}
+}
+
+// viewer 登录用户取 claims 中的 id，匿名访客取 X-Device-Id 头，签名由 service 校验
+func viewer(c *gin.Context) service.Viewer {
+	if claim, ok := auth.Claim(c); ok {
+		return service.Viewer{UserId: claim.Id}
+	}
+	return service.Viewer{DeviceId: c.GetHeader(service.DeviceIdHeader)}
+}
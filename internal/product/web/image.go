@@ -0,0 +1,120 @@
+package web
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth"
+	"mall/internal/product/service"
+	"mall/pkg/ginx"
+)
+
+func (ctl *ProductHandler) UploadImage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := auth.MerchantClaim(c); !ok {
+			return
+		}
+
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("file is required")))
+			return
+		}
+		if file.Size > service.MaxImageSize {
+			c.JSON(http.StatusRequestEntityTooLarge, ginx.GetResponse(ginx.WithStatus(http.StatusRequestEntityTooLarge), ginx.WithMsg("image is too large")))
+			return
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+		defer f.Close()
+
+		data, err := io.ReadAll(io.LimitReader(f, service.MaxImageSize+1))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		img, err := ctl.imageSvc.Upload(c.Request.Context(), data)
+		switch {
+		case errors.Is(err, service.ErrImageTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, ginx.GetResponse(ginx.WithStatus(http.StatusRequestEntityTooLarge), ginx.WithMsg("image is too large")))
+			return
+		case errors.Is(err, service.ErrImageTypeNotAllowed):
+			c.JSON(http.StatusUnsupportedMediaType, ginx.GetResponse(ginx.WithStatus(http.StatusUnsupportedMediaType), ginx.WithMsg("only jpeg, png and gif are allowed")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("upload image successfully"), ginx.WithData(img)))
+	}
+}
+
+func (ctl *ProductHandler) ReorderImages() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			ImageIds []uint64 `json:"imageIds"`
+		}
+		var req Req
+		if err := c.Bind(&req); err != nil {
+			return
+		}
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.imageSvc.ReorderImages(c.Request.Context(), claim.Id, c.Param("id"), req.ImageIds)
+		switch {
+		case errors.Is(err, service.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+			return
+		case errors.Is(err, service.ErrProductNotOwned):
+			c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("product does not belong to you")))
+			return
+		case errors.Is(err, service.ErrImageMismatch):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("imageIds must contain every image of the product exactly once")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("reorder images successfully")))
+	}
+}
+
+func (ctl *ProductHandler) SetPrimaryImage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.imageSvc.SetPrimaryImage(c.Request.Context(), claim.Id, c.Param("id"), c.Param("imageId"))
+		switch {
+		case errors.Is(err, service.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+			return
+		case errors.Is(err, service.ErrProductNotOwned):
+			c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("product does not belong to you")))
+			return
+		case errors.Is(err, service.ErrImageNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("image not found")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("set primary image successfully")))
+	}
+}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth"
+	"mall/internal/product/domain"
+	"mall/pkg/ginx"
+)
+
+func (ctl *ProductHandler) GetMerchantProducts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		status := c.Query("status")
+		switch status {
+		case "", domain.MerchantProductOnList, domain.MerchantProductOffList, domain.MerchantProductOutOfStock:
+		default:
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("status must be onlist, offlist or outofstock")))
+			return
+		}
+
+		page, size := ginx.Pagination(c)
+		products, err := ctl.svc.GetMerchantProducts(c.Request.Context(), claim.Id, status, page, size)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(products)))
+	}
+}
+
+func (ctl *ProductHandler) GetMerchantProductCounts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		counts, err := ctl.svc.GetMerchantProductCounts(c.Request.Context(), claim.Id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(counts)))
+	}
+}
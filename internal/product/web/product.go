@@ -3,18 +3,32 @@ package web
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"mall/internal/auth"
 	"mall/internal/product/domain"
 	"mall/internal/product/service"
+	"mall/pkg/ginx"
+	"mall/pkg/logger"
 	"net/http"
 )
 
 type ProductHandler struct {
-	svc *service.ProductService
+	svc        *service.ProductService
+	imageSvc   *service.ImageService
+	importSvc  *service.ImportService
+	historySvc *service.ViewHistoryService
+	events     *service.EventPublisher
+	l          logger.Logger
 }
 
-func NewProductHandler(svc *service.ProductService) *ProductHandler {
+func NewProductHandler(svc *service.ProductService, imageSvc *service.ImageService, importSvc *service.ImportService,
+	historySvc *service.ViewHistoryService, events *service.EventPublisher, l logger.Logger) *ProductHandler {
 	return &ProductHandler{
-		svc: svc,
+		svc:        svc,
+		imageSvc:   imageSvc,
+		importSvc:  importSvc,
+		historySvc: historySvc,
+		events:     events,
+		l:          l,
 	}
 }
 
@@ -23,17 +37,43 @@ func (ctl *ProductHandler) RegisterRoute(r *gin.Engine) {
 	{
 		categoryGroup.POST("/", ctl.AddCategory())
 		categoryGroup.GET("/", ctl.GetCategories())
+		categoryGroup.GET("/:id/attributes", ctl.GetAttributeTemplates()) // 类目属性模板
+		categoryGroup.PUT("/:id/attributes", ctl.SetAttributeTemplates()) // 设置类目属性模板，仅管理员
 	}
 
 	productGroup := r.Group("api/products")
 	{
-		productGroup.POST("/", ctl.AddProduct())                      // 添加商品
-		productGroup.DELETE("/:id", ctl.AddProduct())                 // 删除商品
-		productGroup.POST("/:id/onlist", ctl.ProductOnList())         // 上架商品
-		productGroup.POST("/:id/removelist", ctl.ProductRemoveList()) // 下架商品
-		productGroup.GET("/search", ctl.SearchProducts())             // 搜索商品
-		productGroup.GET("/:id", ctl.GetProductDetail())              // 获取商品详情
+		productGroup.POST("/", ctl.AddProduct())                                // 添加商品
+		productGroup.DELETE("/:id", ctl.DeleteProduct())                        // 删除商品
+		productGroup.PUT("/:id", ctl.UpdateProduct(true))                       // 整体修改商品
+		productGroup.PATCH("/:id", ctl.UpdateProduct(false))                    // 部分修改商品
+		productGroup.GET("/:id/history", ctl.GetProductHistory())               // 商品变更记录
+		productGroup.GET("/trash", ctl.GetTrash())                              // 回收站
+		productGroup.POST("/:id/restore", ctl.RestoreProduct())                 // 从回收站恢复
+		productGroup.POST("/import", ctl.ImportProducts())                      // 批量导入
+		productGroup.GET("/import/:jobId", ctl.GetImportJob())                  // 导入进度
+		productGroup.GET("/import/:jobId/report", ctl.GetImportReport())        // 导入错误报告
+		productGroup.GET("/export", ctl.ExportProducts())                       // 批量导出
+		productGroup.POST("/images", ctl.UploadImage())                         // 上传图片
+		productGroup.PUT("/:id/images/order", ctl.ReorderImages())              // 图片排序
+		productGroup.PUT("/:id/images/:imageId/primary", ctl.SetPrimaryImage()) // 设置主图
+		productGroup.POST("/:id/onlist", ctl.ProductOnList())                   // 上架商品
+		productGroup.POST("/:id/removelist", ctl.ProductRemoveList())           // 下架商品
+		productGroup.POST("/:id/schedule", ctl.ScheduleProduct())               // 定时上下架
+		productGroup.PUT("/:id/flash-stock", ctl.SetFlashStock())               // 开启或关闭秒杀库存预扣
+		productGroup.GET("/search", ctl.SearchProducts())                       // 搜索商品
+		productGroup.GET("/viewed", ctl.GetViewHistory())                       // 最近浏览
+		productGroup.DELETE("/viewed", ctl.ClearViewHistory())                  // 清空最近浏览
+		productGroup.GET("/:id", ctl.GetProductDetail())                        // 获取商品详情
 	}
+
+	merchantGroup := r.Group("api/merchant")
+	{
+		merchantGroup.GET("/products", ctl.GetMerchantProducts())             // 商家商品列表
+		merchantGroup.GET("/products/counts", ctl.GetMerchantProductCounts()) // 商家各状态商品数量
+	}
+
+	r.POST("api/devices", ctl.NewDevice()) // 为匿名访客签发设备 id，浏览记录和游客购物车共用
 }
 
 func (ctl *ProductHandler) AddCategory() gin.HandlerFunc {
@@ -51,14 +91,14 @@ func (ctl *ProductHandler) AddCategory() gin.HandlerFunc {
 		})
 		switch {
 		case errors.Is(err, service.ErrCategoryDuplicateName):
-			c.JSON(http.StatusConflict, GetResponse(WithStatus(http.StatusConflict), WithMsg("duplicate category name")))
+			c.JSON(http.StatusConflict, ginx.GetResponse(ginx.WithStatus(http.StatusConflict), ginx.WithMsg("duplicate category name")))
 			return
 		case err != nil:
-			c.JSON(http.StatusInternalServerError, GetResponse(WithStatus(http.StatusConflict), WithMsg("system error")))
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusConflict), ginx.WithMsg("system error")))
 			return
 		}
 
-		c.JSON(http.StatusOK, GetResponse(WithStatus(http.StatusOK), WithMsg("add category successfully")))
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("add category successfully")))
 	}
 }
 
@@ -67,14 +107,14 @@ func (ctl *ProductHandler) GetCategories() gin.HandlerFunc {
 		categories, err := ctl.svc.GetCategories(c.Request.Context())
 		switch {
 		case errors.Is(err, service.ErrCategoriesNotFound):
-			c.JSON(http.StatusNotFound, GetResponse(WithStatus(http.StatusNotFound), WithMsg("no categories be found")))
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("no categories be found")))
 			return
 		case err != nil:
-			c.JSON(http.StatusInternalServerError, GetResponse(WithStatus(http.StatusInternalServerError), WithMsg("system error")))
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
 			return
 		}
 
-		c.JSON(http.StatusOK, GetResponse(WithStatus(http.StatusOK), WithData(categories)))
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(categories)))
 	}
 }
 
@@ -107,23 +147,162 @@ func (ctl *ProductHandler) AddProduct() gin.HandlerFunc {
 			})
 		}
 
-		err := ctl.svc.AddProduct(c.Request.Context(), domain.Product{
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		// 客户端重试时携带相同的幂等键，避免重复创建
+		idemKey := c.GetHeader("Idempotency-Key")
+		if len(idemKey) > 64 {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("idempotency key is too long")))
+			return
+		}
+
+		id, err := ctl.svc.AddProduct(c.Request.Context(), domain.Product{
 			Name:        req.Name,
 			Description: req.Description,
 			Price:       req.Price,
 			Stock:       req.Stock,
 			CategoryId:  req.CategoryId,
-		}, attributes, req.Images)
+		}, attributes, req.Images, claim.Id, idemKey)
+		switch {
+		case errors.Is(err, service.ErrCategoryNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("category not found")))
+			return
+		case errors.Is(err, service.ErrInvalidAttribute):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg(err.Error())))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("add product successfully"), ginx.WithData(map[string]any{
+			"id": id,
+		})))
+	}
+}
+
+// UpdateProduct replace 为 true 时对应 PUT，未传的字段会被清空
+func (ctl *ProductHandler) UpdateProduct(replace bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Attribute struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		}
+
+		type Req struct {
+			Version     *int64      `json:"version"`
+			Name        *string     `json:"name"`
+			Description *string     `json:"description"`
+			Price       *float64    `json:"price"`
+			CategoryId  *uint64     `json:"categoryId"`
+			Images      []string    `json:"images"`
+			Attributes  []Attribute `json:"attributes"`
+		}
+		var req Req
+		if err := c.Bind(&req); err != nil {
+			return
+		}
+
+		if req.Version == nil {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("version is required")))
+			return
+		}
+		if replace && (req.Name == nil || req.Price == nil || req.CategoryId == nil) {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("name, price and categoryId are required")))
+			return
+		}
+		if req.Price != nil && *req.Price <= 0 {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("price must be positive")))
+			return
+		}
+
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		upd := domain.ProductUpdate{
+			OperatorId:  claim.Id,
+			Version:     *req.Version,
+			Name:        req.Name,
+			Description: req.Description,
+			Price:       req.Price,
+			CategoryId:  req.CategoryId,
+			Images:      req.Images,
+		}
+		if req.Attributes != nil {
+			upd.Attributes = make([]domain.ProductAttribute, 0, len(req.Attributes))
+			for _, attr := range req.Attributes {
+				upd.Attributes = append(upd.Attributes, domain.ProductAttribute{
+					Name:  attr.Name,
+					Value: attr.Value,
+				})
+			}
+		}
+		if replace {
+			if upd.Description == nil {
+				upd.Description = new(string)
+			}
+			if upd.Attributes == nil {
+				upd.Attributes = []domain.ProductAttribute{}
+			}
+			if upd.Images == nil {
+				upd.Images = []string{}
+			}
+		}
+
+		version, err := ctl.svc.UpdateProduct(c.Request.Context(), c.Param("id"), upd)
 		switch {
+		case errors.Is(err, service.ErrProductNotOwned):
+			c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("product does not belong to you")))
+			return
+		case errors.Is(err, service.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+			return
 		case errors.Is(err, service.ErrCategoryNotFound):
-			c.JSON(http.StatusNotFound, GetResponse(WithStatus(http.StatusNotFound), WithMsg("category not found")))
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("category not found")))
+			return
+		case errors.Is(err, service.ErrProductVersionConflict):
+			c.JSON(http.StatusConflict, ginx.GetResponse(ginx.WithStatus(http.StatusConflict), ginx.WithMsg("product has been modified, please refresh")))
+			return
+		case errors.Is(err, service.ErrInvalidAttribute):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg(err.Error())))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("update product successfully"), ginx.WithData(map[string]any{
+			"version": version,
+		})))
+	}
+}
+
+func (ctl *ProductHandler) GetProductHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		histories, err := ctl.svc.GetProductHistory(c.Request.Context(), claim.Id, c.Param("id"))
+		switch {
+		case errors.Is(err, service.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+			return
+		case errors.Is(err, service.ErrProductNotOwned):
+			c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("product does not belong to you")))
 			return
 		case err != nil:
-			c.JSON(http.StatusInternalServerError, GetResponse(WithStatus(http.StatusInternalServerError), WithMsg("system error")))
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
 			return
 		}
 
-		c.JSON(http.StatusOK, GetResponse(WithStatus(http.StatusOK), WithMsg("add product successfully")))
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(histories)))
 	}
 }
 
@@ -134,83 +313,239 @@ func (ctl *ProductHandler) GetProductDetail() gin.HandlerFunc {
 		product, err := ctl.svc.GetProductDetail(c.Request.Context(), id)
 		switch {
 		case errors.Is(err, service.ErrProductNotFound):
-			c.JSON(http.StatusNotFound, GetResponse(WithStatus(http.StatusNotFound), WithMsg("product not found")))
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
 			return
 		case errors.Is(err, service.ErrCategoryNotFound):
-			c.JSON(http.StatusNotFound, GetResponse(WithStatus(http.StatusNotFound), WithMsg("category not found")))
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("category not found")))
 			return
 		case errors.Is(err, service.ErrProductNotOnList):
-			c.JSON(http.StatusNotFound, GetResponse(WithStatus(http.StatusNotFound), WithMsg("product is not on list")))
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product is not on list")))
 			return
 		case err != nil:
-			c.JSON(http.StatusInternalServerError, GetResponse(WithStatus(http.StatusInternalServerError), WithMsg("system error")))
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
 			return
 		}
 
-		c.JSON(http.StatusOK, GetResponse(WithStatus(http.StatusOK), WithData(product)))
+		// 浏览记录只是附带功能，写入失败不影响详情返回
+		if err := ctl.historySvc.RecordView(c.Request.Context(), viewer(c), product.Product.Id); err != nil {
+			ctl.l.Error("记录浏览历史失败", logger.Field{Key: "productId", Val: product.Product.Id}, logger.Error(err))
+		}
+		ctl.events.Publish(c.Request.Context(), domain.ProductEvent{
+			Type:       domain.EventView,
+			ProductId:  product.Product.Id,
+			CategoryId: product.Category.ID,
+			Count:      1,
+		})
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(product)))
 	}
 }
 
 func (ctl *ProductHandler) SearchProducts() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		name := c.Query("name")
-
-		products, err := ctl.svc.SearchProducts(c.Request.Context(), name)
+		search, err := parseSearch(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, GetResponse(WithStatus(http.StatusInternalServerError), WithMsg("system error")))
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg(err.Error())))
+			return
+		}
+
+		products, err := ctl.svc.SearchProducts(c.Request.Context(), search)
+		switch {
+		case errors.Is(err, service.ErrInvalidAttributeFilter):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg(err.Error())))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
 			return
 		}
 
-		c.JSON(http.StatusOK, GetResponse(WithStatus(http.StatusOK), WithData(products)))
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(products)))
 	}
 }
 
 func (ctl *ProductHandler) DeleteProduct() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
 		id := c.Param("id")
 
-		err := ctl.svc.DeleteProduct(c.Request.Context(), id)
+		err := ctl.svc.DeleteProduct(c.Request.Context(), claim.Id, id)
+		switch {
+		case errors.Is(err, service.ErrProductNotOwned):
+			c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("product does not belong to you")))
+			return
+		case errors.Is(err, service.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("delete product successfully")))
+	}
+}
+
+func (ctl *ProductHandler) GetTrash() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		page, size := ginx.Pagination(c)
+		products, err := ctl.svc.GetTrash(c.Request.Context(), claim.Id, page, size)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, GetResponse(WithStatus(http.StatusInternalServerError), WithMsg("system error")))
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(products)))
+	}
+}
+
+func (ctl *ProductHandler) RestoreProduct() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
 			return
 		}
+		id := c.Param("id")
 
-		c.JSON(http.StatusOK, GetResponse(WithStatus(http.StatusOK), WithMsg("delete product successfully")))
+		err := ctl.svc.RestoreProduct(c.Request.Context(), claim.Id, id)
+		switch {
+		case errors.Is(err, service.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+			return
+		case errors.Is(err, service.ErrProductNotOwned):
+			c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("product does not belong to you")))
+			return
+		case errors.Is(err, service.ErrProductNotInTrash):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product is not in trash")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("restore product successfully")))
 	}
 }
 
 func (ctl *ProductHandler) ProductOnList() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
 		id := c.Param("id")
 
-		err := ctl.svc.ProductOnList(c.Request.Context(), id)
+		err := ctl.svc.ProductOnList(c.Request.Context(), claim.Id, id)
 		switch {
+		case errors.Is(err, service.ErrProductNotOwned):
+			c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("product does not belong to you")))
+			return
 		case errors.Is(err, service.ErrProductNotFound):
-			c.JSON(http.StatusNotFound, GetResponse(WithStatus(http.StatusNotFound), WithMsg("product not found")))
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
 			return
 		case err != nil:
-			c.JSON(http.StatusInternalServerError, GetResponse(WithStatus(http.StatusInternalServerError), WithMsg("system error")))
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
 			return
 		}
 
-		c.JSON(http.StatusOK, GetResponse(WithStatus(http.StatusOK), WithMsg("product is on list")))
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("product is on list")))
 	}
 }
 
 func (ctl *ProductHandler) ProductRemoveList() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
 		id := c.Param("id")
 
-		err := ctl.svc.ProductOnList(c.Request.Context(), id)
+		err := ctl.svc.ProductRemoveList(c.Request.Context(), claim.Id, id)
 		switch {
+		case errors.Is(err, service.ErrProductNotOwned):
+			c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("product does not belong to you")))
+			return
+		case errors.Is(err, service.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("product is remove list")))
+	}
+}
+
+func (ctl *ProductHandler) ScheduleProduct() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			ListAt   int64 `json:"listAt"`   // 毫秒时间戳，0 表示取消
+			DelistAt int64 `json:"delistAt"` // 毫秒时间戳，0 表示取消
+		}
+		var req Req
+		if err := c.Bind(&req); err != nil {
+			return
+		}
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.svc.ScheduleProduct(c.Request.Context(), claim.Id, c.Param("id"), req.ListAt, req.DelistAt)
+		switch {
+		case errors.Is(err, service.ErrProductNotOwned):
+			c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("product does not belong to you")))
+			return
+		case errors.Is(err, service.ErrInvalidSchedule):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("invalid list or delist time")))
+			return
+		case errors.Is(err, service.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("schedule product successfully")))
+	}
+}
+
+func (ctl *ProductHandler) SetFlashStock() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			Enabled bool `json:"enabled"`
+		}
+		var req Req
+		if err := c.Bind(&req); err != nil {
+			return
+		}
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.svc.SetFlashStock(c.Request.Context(), claim.Id, c.Param("id"), req.Enabled)
+		switch {
+		case errors.Is(err, service.ErrProductNotOwned):
+			c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("product does not belong to you")))
+			return
 		case errors.Is(err, service.ErrProductNotFound):
-			c.JSON(http.StatusNotFound, GetResponse(WithStatus(http.StatusNotFound), WithMsg("product not found")))
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
 			return
 		case err != nil:
-			c.JSON(http.StatusInternalServerError, GetResponse(WithStatus(http.StatusInternalServerError), WithMsg("system error")))
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
 			return
 		}
 
-		c.JSON(http.StatusOK, GetResponse(WithStatus(http.StatusOK), WithMsg("product is remove list")))
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("set flash stock successfully")))
 	}
 }
@@ -6,36 +6,111 @@ import (
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"mall/internal/product/job"
 	"mall/internal/product/repository"
 	"mall/internal/product/repository/cache"
 	"mall/internal/product/repository/dao"
 	"mall/internal/product/service"
 	"mall/internal/product/web"
+	"mall/pkg/logger"
+	"mall/pkg/storage"
+	"mall/pkg/taskx"
 )
 
 var productSet = wire.NewSet(
 	dao.NewProductDao,
-	cache.NewProductCache,
+	cache.NewStockCache,
+	cache.NewImportJobCache,
+	cache.NewViewHistoryCache,
 
 	repository.NewProductRepository,
+	repository.NewImportJobRepository,
+	repository.NewViewHistoryRepository,
 
 	service.NewProductService,
+	service.NewImageService,
+	service.NewImportService,
+	service.NewViewHistoryService,
+	service.NewEventPublisher,
 
 	web.NewProductHandler,
 )
 
-func InitProductHandler(db *gorm.DB, cmd redis.Cmdable) *web.ProductHandler {
+func InitProductHandler(db *gorm.DB, cmd redis.Cmdable, pc *cache.ProductCache, blob storage.Blob, l logger.Logger,
+	hooks []service.EventHook, tasks *taskx.Group, devices *service.DeviceIds) *web.ProductHandler {
 	wire.Build(
 		productSet,
 	)
 	return new(web.ProductHandler)
 }
 
-func NewProductRepository(db *gorm.DB, cmd redis.Cmdable) *repository.ProductRepository {
+// InitProductCache 商品详情缓存带有本地缓存和失效订阅，整个服务共用一个
+func InitProductCache(cmd redis.Cmdable) *cache.ProductCache {
 	wire.Build(
-		dao.NewProductDao,
 		cache.NewProductCache,
+	)
+	return new(cache.ProductCache)
+}
+
+func NewProductRepository(db *gorm.DB, cmd redis.Cmdable, pc *cache.ProductCache) *repository.ProductRepository {
+	wire.Build(
+		dao.NewProductDao,
+		cache.NewStockCache,
 		repository.NewProductRepository,
 	)
 	return new(repository.ProductRepository)
 }
+
+func NewViewHistoryRepository(cmd redis.Cmdable) *repository.ViewHistoryRepository {
+	wire.Build(
+		cache.NewViewHistoryCache,
+		repository.NewViewHistoryRepository,
+	)
+	return new(repository.ViewHistoryRepository)
+}
+
+func InitViewHistoryService(db *gorm.DB, cmd redis.Cmdable, pc *cache.ProductCache, devices *service.DeviceIds) *service.ViewHistoryService {
+	wire.Build(
+		dao.NewProductDao,
+		cache.NewStockCache,
+		cache.NewViewHistoryCache,
+		repository.NewProductRepository,
+		repository.NewViewHistoryRepository,
+		service.NewViewHistoryService,
+	)
+	return new(service.ViewHistoryService)
+}
+
+func InitPurgeJob(db *gorm.DB, cmd redis.Cmdable, pc *cache.ProductCache, l logger.Logger, hooks []service.EventHook) *job.PurgeJob {
+	wire.Build(
+		dao.NewProductDao,
+		cache.NewStockCache,
+		repository.NewProductRepository,
+		service.NewProductService,
+		service.NewEventPublisher,
+		job.NewPurgeJob,
+	)
+	return new(job.PurgeJob)
+}
+
+func InitScheduleJob(db *gorm.DB, cmd redis.Cmdable, pc *cache.ProductCache, l logger.Logger) *job.ScheduleJob {
+	wire.Build(
+		dao.NewProductDao,
+		cache.NewStockCache,
+		repository.NewProductRepository,
+		service.NewProductService,
+		job.NewScheduleJob,
+	)
+	return new(job.ScheduleJob)
+}
+
+func InitFlashStockJob(db *gorm.DB, cmd redis.Cmdable, pc *cache.ProductCache, l logger.Logger) *job.FlashStockJob {
+	wire.Build(
+		dao.NewProductDao,
+		cache.NewStockCache,
+		repository.NewProductRepository,
+		service.NewProductService,
+		job.NewFlashStockJob,
+	)
+	return new(job.FlashStockJob)
+}
@@ -10,31 +10,94 @@ import (
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"mall/internal/product/job"
 	"mall/internal/product/repository"
 	"mall/internal/product/repository/cache"
 	"mall/internal/product/repository/dao"
 	"mall/internal/product/service"
 	"mall/internal/product/web"
+	"mall/pkg/logger"
+	"mall/pkg/storage"
+	"mall/pkg/taskx"
 )
 
 // Injectors from wire.go:
 
-func InitProductHandler(db *gorm.DB, cmd redis.Cmdable) *web.ProductHandler {
+func InitProductHandler(db *gorm.DB, cmd redis.Cmdable, pc *cache.ProductCache, blob storage.Blob, l logger.Logger,
+	hooks []service.EventHook, tasks *taskx.Group, devices *service.DeviceIds) *web.ProductHandler {
 	productDao := dao.NewProductDao(db)
-	productCache := cache.NewProductCache(cmd)
-	productRepository := repository.NewProductRepository(productDao, productCache)
+	stockCache := cache.NewStockCache(cmd)
+	productRepository := repository.NewProductRepository(productDao, pc, stockCache)
 	productService := service.NewProductService(productRepository)
-	productHandler := web.NewProductHandler(productService)
+	imageService := service.NewImageService(blob, productRepository)
+	importJobCache := cache.NewImportJobCache(cmd)
+	importJobRepository := repository.NewImportJobRepository(importJobCache)
+	importService := service.NewImportService(productRepository, importJobRepository, tasks)
+	viewHistoryCache := cache.NewViewHistoryCache(cmd)
+	viewHistoryRepository := repository.NewViewHistoryRepository(viewHistoryCache)
+	viewHistoryService := service.NewViewHistoryService(viewHistoryRepository, productRepository, devices)
+	eventPublisher := service.NewEventPublisher(hooks, l)
+	productHandler := web.NewProductHandler(productService, imageService, importService, viewHistoryService, eventPublisher, l)
 	return productHandler
 }
 
-func NewProductRepository(db *gorm.DB, cmd redis.Cmdable) *repository.ProductRepository {
-	productDao := dao.NewProductDao(db)
+// InitProductCache 商品详情缓存带有本地缓存和失效订阅，整个服务共用一个
+func InitProductCache(cmd redis.Cmdable) *cache.ProductCache {
 	productCache := cache.NewProductCache(cmd)
-	productRepository := repository.NewProductRepository(productDao, productCache)
+	return productCache
+}
+
+func NewProductRepository(db *gorm.DB, cmd redis.Cmdable, pc *cache.ProductCache) *repository.ProductRepository {
+	productDao := dao.NewProductDao(db)
+	stockCache := cache.NewStockCache(cmd)
+	productRepository := repository.NewProductRepository(productDao, pc, stockCache)
 	return productRepository
 }
 
+func NewViewHistoryRepository(cmd redis.Cmdable) *repository.ViewHistoryRepository {
+	viewHistoryCache := cache.NewViewHistoryCache(cmd)
+	viewHistoryRepository := repository.NewViewHistoryRepository(viewHistoryCache)
+	return viewHistoryRepository
+}
+
+func InitViewHistoryService(db *gorm.DB, cmd redis.Cmdable, pc *cache.ProductCache, devices *service.DeviceIds) *service.ViewHistoryService {
+	productDao := dao.NewProductDao(db)
+	stockCache := cache.NewStockCache(cmd)
+	viewHistoryCache := cache.NewViewHistoryCache(cmd)
+	viewHistoryRepository := repository.NewViewHistoryRepository(viewHistoryCache)
+	productRepository := repository.NewProductRepository(productDao, pc, stockCache)
+	viewHistoryService := service.NewViewHistoryService(viewHistoryRepository, productRepository, devices)
+	return viewHistoryService
+}
+
+func InitPurgeJob(db *gorm.DB, cmd redis.Cmdable, pc *cache.ProductCache, l logger.Logger, hooks []service.EventHook) *job.PurgeJob {
+	productDao := dao.NewProductDao(db)
+	stockCache := cache.NewStockCache(cmd)
+	productRepository := repository.NewProductRepository(productDao, pc, stockCache)
+	productService := service.NewProductService(productRepository)
+	eventPublisher := service.NewEventPublisher(hooks, l)
+	purgeJob := job.NewPurgeJob(productService, eventPublisher, l)
+	return purgeJob
+}
+
+func InitScheduleJob(db *gorm.DB, cmd redis.Cmdable, pc *cache.ProductCache, l logger.Logger) *job.ScheduleJob {
+	productDao := dao.NewProductDao(db)
+	stockCache := cache.NewStockCache(cmd)
+	productRepository := repository.NewProductRepository(productDao, pc, stockCache)
+	productService := service.NewProductService(productRepository)
+	scheduleJob := job.NewScheduleJob(productService, cmd, l)
+	return scheduleJob
+}
+
+func InitFlashStockJob(db *gorm.DB, cmd redis.Cmdable, pc *cache.ProductCache, l logger.Logger) *job.FlashStockJob {
+	productDao := dao.NewProductDao(db)
+	stockCache := cache.NewStockCache(cmd)
+	productRepository := repository.NewProductRepository(productDao, pc, stockCache)
+	productService := service.NewProductService(productRepository)
+	flashStockJob := job.NewFlashStockJob(productService, cmd, l)
+	return flashStockJob
+}
+
 // wire.go:
 
-var productSet = wire.NewSet(dao.NewProductDao, cache.NewProductCache, repository.NewProductRepository, service.NewProductService, web.NewProductHandler)
+var productSet = wire.NewSet(dao.NewProductDao, cache.NewStockCache, cache.NewImportJobCache, cache.NewViewHistoryCache, repository.NewProductRepository, repository.NewImportJobRepository, repository.NewViewHistoryRepository, service.NewProductService, service.NewImageService, service.NewImportService, service.NewViewHistoryService, service.NewEventPublisher, web.NewProductHandler)
@@ -0,0 +1,28 @@
+package domain
+
+// 投票对象
+const (
+	VoteQuestion = "question"
+	VoteAnswer   = "answer"
+)
+
+type Question struct {
+	Id          uint64   `json:"id"`
+	ProductId   uint64   `json:"productId"`
+	UserId      uint64   `json:"userId"`
+	Content     string   `json:"content"`
+	AnswerCount int64    `json:"answerCount"`
+	VoteCount   int64    `json:"voteCount"`
+	CreateAt    int64    `json:"createAt"`
+	TopAnswers  []Answer `json:"topAnswers"` // 商家回答优先，其次按票数
+}
+
+type Answer struct {
+	Id         uint64 `json:"id"`
+	QuestionId uint64 `json:"questionId"`
+	UserId     uint64 `json:"userId"`
+	IsMerchant bool   `json:"isMerchant"` // 是否为商品所属商家的回答
+	Content    string `json:"content"`
+	VoteCount  int64  `json:"voteCount"`
+	CreateAt   int64  `json:"createAt"`
+}
@@ -0,0 +1,32 @@
+package dao
+
+type Question struct {
+	Id          uint64 `gorm:"primaryKey,autoIncrement"`
+	ProductId   uint64 `gorm:"not null;index"`
+	UserId      uint64 `gorm:"not null"`
+	Content     string `gorm:"type:text;not null"`
+	AnswerCount int64  `gorm:"not null;default:0"`
+	VoteCount   int64  `gorm:"not null;default:0"`
+	CreateAt    int64
+	UpdateAt    int64
+}
+
+type Answer struct {
+	Id         uint64 `gorm:"primaryKey,autoIncrement"`
+	QuestionId uint64 `gorm:"not null;index"`
+	UserId     uint64 `gorm:"not null"`
+	IsMerchant bool   `gorm:"not null;default:false"`
+	Content    string `gorm:"type:text;not null"`
+	VoteCount  int64  `gorm:"not null;default:0"`
+	CreateAt   int64
+	UpdateAt   int64
+}
+
+// QaVote 对问题或回答的投票，每人每个对象一票
+type QaVote struct {
+	Id         uint64 `gorm:"primaryKey,autoIncrement"`
+	TargetType string `gorm:"type:varchar(16);not null;uniqueIndex:uk_target_user,priority:1"`
+	TargetId   uint64 `gorm:"not null;uniqueIndex:uk_target_user,priority:2"`
+	UserId     uint64 `gorm:"not null;uniqueIndex:uk_target_user,priority:3"`
+	CreateAt   int64
+}
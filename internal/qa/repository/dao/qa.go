@@ -0,0 +1,219 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mall/internal/qa/domain"
+	"mall/pkg/gormx"
+)
+
+var (
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrAnswerNotFound   = errors.New("answer not found")
+	ErrAlreadyVoted     = errors.New("already voted")
+)
+
+type QaDao struct {
+	db  *gorm.DB
+	uow *gormx.UnitOfWork
+}
+
+func NewQaDao(db *gorm.DB) *QaDao {
+	return &QaDao{
+		db:  db,
+		uow: gormx.NewUnitOfWork(db),
+	}
+}
+
+func (dao *QaDao) InsertQuestion(ctx context.Context, q domain.Question) (uint64, error) {
+	now := time.Now().UnixMilli()
+	question := Question{
+		ProductId: q.ProductId,
+		UserId:    q.UserId,
+		Content:   q.Content,
+		CreateAt:  now,
+		UpdateAt:  now,
+	}
+	err := dao.db.WithContext(ctx).Create(&question).Error
+
+	return question.Id, err
+}
+
+func (dao *QaDao) FindQuestionById(ctx context.Context, id uint64) (domain.Question, error) {
+	var question Question
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&question).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Question{}, ErrQuestionNotFound
+		}
+		return domain.Question{}, err
+	}
+
+	return dao.questionToDomain(question), nil
+}
+
+// InsertAnswer 添加回答并增加问题的回答数
+func (dao *QaDao) InsertAnswer(ctx context.Context, a domain.Answer) (domain.Answer, error) {
+	now := time.Now().UnixMilli()
+	answer := Answer{
+		QuestionId: a.QuestionId,
+		UserId:     a.UserId,
+		IsMerchant: a.IsMerchant,
+		Content:    a.Content,
+		CreateAt:   now,
+		UpdateAt:   now,
+	}
+
+	err := dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+
+		res := db.Model(&Question{}).Where("id = ?", a.QuestionId).Updates(map[string]any{
+			"answer_count": gorm.Expr("answer_count + 1"),
+			"update_at":    now,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrQuestionNotFound
+		}
+
+		return db.Create(&answer).Error
+	})
+	if err != nil {
+		return domain.Answer{}, err
+	}
+
+	return dao.answerToDomain(answer), nil
+}
+
+// InsertVote 记录投票并增加问题或回答的票数
+func (dao *QaDao) InsertVote(ctx context.Context, targetType string, targetId, userId uint64) error {
+	var (
+		model    any
+		notFound error
+	)
+	switch targetType {
+	case domain.VoteQuestion:
+		model, notFound = &Question{}, ErrQuestionNotFound
+	case domain.VoteAnswer:
+		model, notFound = &Answer{}, ErrAnswerNotFound
+	default:
+		return errors.New("unknown vote target")
+	}
+
+	return dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+
+		res := db.Model(model).Where("id = ?", targetId).Update("vote_count", gorm.Expr("vote_count + 1"))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return notFound
+		}
+
+		err := db.Create(&QaVote{
+			TargetType: targetType,
+			TargetId:   targetId,
+			UserId:     userId,
+			CreateAt:   time.Now().UnixMilli(),
+		}).Error
+		if gormx.IsUniqueConflict(err) {
+			return ErrAlreadyVoted
+		}
+		return err
+	})
+}
+
+// FindQuestionsByProduct 分页查询商品的问题，每个问题附带最多 topN 个回答
+func (dao *QaDao) FindQuestionsByProduct(ctx context.Context, productId uint64, offset, limit, topN int) ([]domain.Question, error) {
+	var questions []Question
+	err := dao.db.WithContext(ctx).
+		Where("product_id = ?", productId).
+		Order("vote_count DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&questions).Error
+	if err != nil || len(questions) == 0 {
+		return []domain.Question{}, err
+	}
+
+	ids := make([]uint64, 0, len(questions))
+	for _, q := range questions {
+		ids = append(ids, q.Id)
+	}
+
+	// 每个问题只取排名靠前的几个回答，窗口函数 ROW_NUMBER() 需要 MySQL 8.0 及以上
+	var answers []Answer
+	err = dao.db.WithContext(ctx).Raw(`SELECT id, question_id, user_id, is_merchant, content, vote_count, create_at, update_at FROM (
+		SELECT *, ROW_NUMBER() OVER (PARTITION BY question_id ORDER BY is_merchant DESC, vote_count DESC, id) AS rn
+		FROM answer WHERE question_id IN ?
+	) t WHERE rn <= ? ORDER BY question_id, rn`, ids, topN).Scan(&answers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	answersOf := make(map[uint64][]domain.Answer, len(questions))
+	for _, a := range answers {
+		answersOf[a.QuestionId] = append(answersOf[a.QuestionId], dao.answerToDomain(a))
+	}
+
+	res := make([]domain.Question, 0, len(questions))
+	for _, q := range questions {
+		question := dao.questionToDomain(q)
+		question.TopAnswers = answersOf[q.Id]
+		if question.TopAnswers == nil {
+			question.TopAnswers = []domain.Answer{}
+		}
+		res = append(res, question)
+	}
+
+	return res, nil
+}
+
+// FindAnswersByQuestion 分页查询问题的全部回答，商家回答优先
+func (dao *QaDao) FindAnswersByQuestion(ctx context.Context, questionId uint64, offset, limit int) ([]domain.Answer, error) {
+	var answers []Answer
+	err := dao.db.WithContext(ctx).
+		Where("question_id = ?", questionId).
+		Order("is_merchant DESC, vote_count DESC, id").
+		Offset(offset).Limit(limit).
+		Find(&answers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Answer, 0, len(answers))
+	for _, a := range answers {
+		res = append(res, dao.answerToDomain(a))
+	}
+	return res, nil
+}
+
+func (dao *QaDao) questionToDomain(q Question) domain.Question {
+	return domain.Question{
+		Id:          q.Id,
+		ProductId:   q.ProductId,
+		UserId:      q.UserId,
+		Content:     q.Content,
+		AnswerCount: q.AnswerCount,
+		VoteCount:   q.VoteCount,
+		CreateAt:    q.CreateAt,
+	}
+}
+
+func (dao *QaDao) answerToDomain(a Answer) domain.Answer {
+	return domain.Answer{
+		Id:         a.Id,
+		QuestionId: a.QuestionId,
+		UserId:     a.UserId,
+		IsMerchant: a.IsMerchant,
+		Content:    a.Content,
+		VoteCount:  a.VoteCount,
+		CreateAt:   a.CreateAt,
+	}
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"mall/internal/qa/domain"
+	"mall/internal/qa/repository/dao"
+)
+
+var (
+	ErrQuestionNotFound = dao.ErrQuestionNotFound
+	ErrAnswerNotFound   = dao.ErrAnswerNotFound
+	ErrAlreadyVoted     = dao.ErrAlreadyVoted
+)
+
+type QaRepository struct {
+	dao *dao.QaDao
+}
+
+func NewQaRepository(dao *dao.QaDao) *QaRepository {
+	return &QaRepository{
+		dao: dao,
+	}
+}
+
+func (repo *QaRepository) InsertQuestion(ctx context.Context, q domain.Question) (uint64, error) {
+	return repo.dao.InsertQuestion(ctx, q)
+}
+
+func (repo *QaRepository) FindQuestionById(ctx context.Context, id uint64) (domain.Question, error) {
+	return repo.dao.FindQuestionById(ctx, id)
+}
+
+func (repo *QaRepository) InsertAnswer(ctx context.Context, a domain.Answer) (domain.Answer, error) {
+	return repo.dao.InsertAnswer(ctx, a)
+}
+
+func (repo *QaRepository) InsertVote(ctx context.Context, targetType string, targetId, userId uint64) error {
+	return repo.dao.InsertVote(ctx, targetType, targetId, userId)
+}
+
+func (repo *QaRepository) FindProductQuestions(ctx context.Context, productId uint64, offset, limit, topN int) ([]domain.Question, error) {
+	return repo.dao.FindQuestionsByProduct(ctx, productId, offset, limit, topN)
+}
+
+func (repo *QaRepository) FindAnswers(ctx context.Context, questionId uint64, offset, limit int) ([]domain.Answer, error) {
+	return repo.dao.FindAnswersByQuestion(ctx, questionId, offset, limit)
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	ndomain "mall/internal/notification/domain"
+	nservice "mall/internal/notification/service"
+	"mall/internal/qa/domain"
+)
+
+// 通知内容中引用问题的最大字数
+const notifyQuoteLength = 50
+
+// AnswerNotifier 问题有新回答时的通知钩子，通知提问者
+type AnswerNotifier interface {
+	NotifyAnswered(ctx context.Context, question domain.Question, answer domain.Answer) error
+}
+
+// NotificationNotifier 通过站内通知告知提问者
+type NotificationNotifier struct {
+	notificationSvc *nservice.NotificationService
+}
+
+func NewNotificationNotifier(notificationSvc *nservice.NotificationService) AnswerNotifier {
+	return &NotificationNotifier{
+		notificationSvc: notificationSvc,
+	}
+}
+
+func (n *NotificationNotifier) NotifyAnswered(ctx context.Context, question domain.Question, answer domain.Answer) error {
+	title := "你的问题有了新回答"
+	if answer.IsMerchant {
+		title = "商家回答了你的问题"
+	}
+
+	return n.notificationSvc.Send(ctx, ndomain.Notification{
+		UserId:    question.UserId,
+		Type:      ndomain.TypeNewAnswer,
+		Title:     title,
+		Content:   fmt.Sprintf("你的问题「%s」有了新回答", quote(question.Content)),
+		ProductId: question.ProductId,
+	})
+}
+
+func quote(content string) string {
+	if utf8.RuneCountInString(content) <= notifyQuoteLength {
+		return content
+	}
+	return string([]rune(content)[:notifyQuoteLength]) + "..."
+}
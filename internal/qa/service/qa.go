@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	prepo "mall/internal/product/repository"
+	"mall/internal/qa/domain"
+	"mall/internal/qa/repository"
+	"mall/pkg/logger"
+	"mall/pkg/taskx"
+)
+
+var (
+	ErrQuestionNotFound = repository.ErrQuestionNotFound
+	ErrAnswerNotFound   = repository.ErrAnswerNotFound
+	ErrAlreadyVoted     = repository.ErrAlreadyVoted
+	ErrProductNotFound  = prepo.ErrProductNotFound
+	ErrProductNotOnList = prepo.ErrProductNotOnList
+	ErrInvalidContent   = errors.New("content must be 1 to 500 characters")
+)
+
+const (
+	maxContentLength = 500
+	// 问题列表中每个问题附带的回答数
+	topAnswers    = 3
+	notifyTimeout = time.Second * 5
+)
+
+type QaService struct {
+	repo        *repository.QaRepository
+	productRepo *prepo.ProductRepository
+	notifier    AnswerNotifier
+	tasks       *taskx.Group
+	l           logger.Logger
+}
+
+func NewQaService(repo *repository.QaRepository, productRepo *prepo.ProductRepository, notifier AnswerNotifier,
+	tasks *taskx.Group, l logger.Logger) *QaService {
+	return &QaService{
+		repo:        repo,
+		productRepo: productRepo,
+		notifier:    notifier,
+		tasks:       tasks,
+		l:           l,
+	}
+}
+
+// AskQuestion 对上架中的商品提问
+func (svc *QaService) AskQuestion(ctx context.Context, uid uint64, productId string, content string) (uint64, error) {
+	id, err := strconv.Atoi(productId)
+	if err != nil {
+		return 0, err
+	}
+	if !validContent(content) {
+		return 0, ErrInvalidContent
+	}
+	if _, err := svc.productRepo.FindProductById(ctx, uint64(id)); err != nil {
+		return 0, err
+	}
+
+	return svc.repo.InsertQuestion(ctx, domain.Question{
+		ProductId: uint64(id),
+		UserId:    uid,
+		Content:   content,
+	})
+}
+
+// AnswerQuestion 回答问题，商品所属商家的回答会被标记并优先展示
+func (svc *QaService) AnswerQuestion(ctx context.Context, uid uint64, questionId string, content string) (domain.Answer, error) {
+	id, err := strconv.Atoi(questionId)
+	if err != nil {
+		return domain.Answer{}, err
+	}
+	if !validContent(content) {
+		return domain.Answer{}, ErrInvalidContent
+	}
+
+	question, err := svc.repo.FindQuestionById(ctx, uint64(id))
+	if err != nil {
+		return domain.Answer{}, err
+	}
+	owner, err := svc.productRepo.FindProductOwner(ctx, question.ProductId)
+	if err != nil {
+		return domain.Answer{}, err
+	}
+
+	answer, err := svc.repo.InsertAnswer(ctx, domain.Answer{
+		QuestionId: question.Id,
+		UserId:     uid,
+		IsMerchant: owner == uid,
+		Content:    content,
+	})
+	if err != nil {
+		return domain.Answer{}, err
+	}
+
+	// 自问自答不通知；通知失败不影响回答，服务退出时不再发送
+	if question.UserId != uid {
+		started := svc.tasks.Go(func(ctx context.Context) {
+			ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+			defer cancel()
+			if err := svc.notifier.NotifyAnswered(ctx, question, answer); err != nil {
+				svc.l.Error("回答通知发送失败", logger.Error(err))
+			}
+		})
+		if !started {
+			svc.l.Warn("服务正在退出，跳过回答通知", logger.String("question_id", strconv.FormatUint(question.Id, 10)))
+		}
+	}
+
+	return answer, nil
+}
+
+func (svc *QaService) VoteQuestion(ctx context.Context, uid uint64, questionId string) error {
+	return svc.vote(ctx, uid, domain.VoteQuestion, questionId)
+}
+
+func (svc *QaService) VoteAnswer(ctx context.Context, uid uint64, answerId string) error {
+	return svc.vote(ctx, uid, domain.VoteAnswer, answerId)
+}
+
+func (svc *QaService) GetProductQuestions(ctx context.Context, productId string, page, size int) ([]domain.Question, error) {
+	id, err := strconv.Atoi(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc.repo.FindProductQuestions(ctx, uint64(id), (page-1)*size, size, topAnswers)
+}
+
+func (svc *QaService) GetAnswers(ctx context.Context, questionId string, page, size int) ([]domain.Answer, error) {
+	id, err := strconv.Atoi(questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc.repo.FindAnswers(ctx, uint64(id), (page-1)*size, size)
+}
+
+func (svc *QaService) vote(ctx context.Context, uid uint64, targetType string, targetId string) error {
+	id, err := strconv.Atoi(targetId)
+	if err != nil {
+		return err
+	}
+
+	return svc.repo.InsertVote(ctx, targetType, uint64(id), uid)
+}
+
+func validContent(content string) bool {
+	n := utf8.RuneCountInString(content)
+	return n > 0 && n <= maxContentLength
+}
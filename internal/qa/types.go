@@ -0,0 +1,5 @@
+package qa
+
+import "mall/internal/qa/web"
+
+type Handler = web.QaHandler
@@ -0,0 +1,172 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth"
+	"mall/internal/qa/service"
+	"mall/pkg/ginx"
+)
+
+type QaHandler struct {
+	svc *service.QaService
+}
+
+func NewQaHandler(svc *service.QaService) *QaHandler {
+	return &QaHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *QaHandler) RegisterRoute(r *gin.Engine) {
+	productGroup := r.Group("api/products")
+	{
+		productGroup.POST("/:id/questions", ctl.AskQuestion())        // 提问
+		productGroup.GET("/:id/questions", ctl.GetProductQuestions()) // 商品问答列表
+	}
+
+	questionGroup := r.Group("api/questions")
+	{
+		questionGroup.POST("/:id/answers", ctl.AnswerQuestion()) // 回答
+		questionGroup.GET("/:id/answers", ctl.GetAnswers())      // 问题的全部回答
+		questionGroup.POST("/:id/vote", ctl.VoteQuestion())      // 问题投票
+	}
+
+	r.POST("api/answers/:id/vote", ctl.VoteAnswer()) // 回答投票
+}
+
+func (ctl *QaHandler) AskQuestion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			Content string `json:"content"`
+		}
+		var req Req
+		if err := c.Bind(&req); err != nil {
+			return
+		}
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		id, err := ctl.svc.AskQuestion(c.Request.Context(), claim.Id, c.Param("id"), req.Content)
+		switch {
+		case errors.Is(err, service.ErrInvalidContent):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("content must be 1 to 500 characters")))
+			return
+		case errors.Is(err, service.ErrProductNotFound), errors.Is(err, service.ErrProductNotOnList):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("ask question successfully"), ginx.WithData(map[string]any{
+			"id": id,
+		})))
+	}
+}
+
+func (ctl *QaHandler) GetProductQuestions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, size := ginx.Pagination(c)
+		questions, err := ctl.svc.GetProductQuestions(c.Request.Context(), c.Param("id"), page, size)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(questions)))
+	}
+}
+
+func (ctl *QaHandler) AnswerQuestion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			Content string `json:"content"`
+		}
+		var req Req
+		if err := c.Bind(&req); err != nil {
+			return
+		}
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		answer, err := ctl.svc.AnswerQuestion(c.Request.Context(), claim.Id, c.Param("id"), req.Content)
+		switch {
+		case errors.Is(err, service.ErrInvalidContent):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("content must be 1 to 500 characters")))
+			return
+		case errors.Is(err, service.ErrQuestionNotFound), errors.Is(err, service.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("question not found")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("answer question successfully"), ginx.WithData(answer)))
+	}
+}
+
+func (ctl *QaHandler) GetAnswers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, size := ginx.Pagination(c)
+		answers, err := ctl.svc.GetAnswers(c.Request.Context(), c.Param("id"), page, size)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(answers)))
+	}
+}
+
+func (ctl *QaHandler) VoteQuestion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.svc.VoteQuestion(c.Request.Context(), claim.Id, c.Param("id"))
+		ctl.writeVoteResult(c, err)
+	}
+}
+
+func (ctl *QaHandler) VoteAnswer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.svc.VoteAnswer(c.Request.Context(), claim.Id, c.Param("id"))
+		ctl.writeVoteResult(c, err)
+	}
+}
+
+func (ctl *QaHandler) writeVoteResult(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrQuestionNotFound):
+		c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("question not found")))
+		return
+	case errors.Is(err, service.ErrAnswerNotFound):
+		c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("answer not found")))
+		return
+	case errors.Is(err, service.ErrAlreadyVoted):
+		c.JSON(http.StatusConflict, ginx.GetResponse(ginx.WithStatus(http.StatusConflict), ginx.WithMsg("you have already voted")))
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+		return
+	}
+
+	c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("vote successfully")))
+}
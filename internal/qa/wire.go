@@ -0,0 +1,35 @@
+//go:build wireinject
+
+package qa
+
+import (
+	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"mall/internal/notification"
+	"mall/internal/product"
+	"mall/internal/qa/repository"
+	"mall/internal/qa/repository/dao"
+	"mall/internal/qa/service"
+	"mall/internal/qa/web"
+	"mall/pkg/logger"
+	"mall/pkg/taskx"
+)
+
+func InitQaHandler(db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache, tasks *taskx.Group, l logger.Logger) *web.QaHandler {
+	wire.Build(
+		dao.NewQaDao,
+
+		repository.NewQaRepository,
+
+		product.NewProductRepository,
+
+		notification.NewNotificationService,
+
+		service.NewNotificationNotifier,
+		service.NewQaService,
+
+		web.NewQaHandler,
+	)
+	return new(web.QaHandler)
+}
@@ -0,0 +1,17 @@
+package domain
+
+// Entry 排行中的一项，CategoryId 为 0 表示全站排行
+type Entry struct {
+	ProductId uint64
+	Score     float64
+}
+
+// HotProduct 返回给前端的热门商品
+type HotProduct struct {
+	Rank      int     `json:"rank"`
+	ProductId uint64  `json:"productId"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	ImageUrl  string  `json:"imageUrl"`
+	Score     float64 `json:"score"`
+}
@@ -0,0 +1,73 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mall/internal/ranking/service"
+	"mall/pkg/logger"
+	"mall/pkg/redisx"
+)
+
+const rankingLockKey = "ranking:hot:lock"
+
+// RankingJob 定期衰减热度并把排行快照到数据库，多实例通过 Redis 锁互斥
+type RankingJob struct {
+	svc      *service.RankingService
+	cmd      redis.Cmdable
+	l        logger.Logger
+	interval time.Duration
+}
+
+func NewRankingJob(svc *service.RankingService, cmd redis.Cmdable, l logger.Logger) *RankingJob {
+	return &RankingJob{
+		svc:      svc,
+		cmd:      cmd,
+		l:        l,
+		interval: time.Minute * 10,
+	}
+}
+
+func (j *RankingJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.run(ctx)
+		}
+	}
+}
+
+func (j *RankingJob) run(ctx context.Context) {
+	lock := redisx.NewLock(j.cmd, rankingLockKey, j.interval)
+	ok, err := lock.TryLock(ctx)
+	if err != nil {
+		j.l.Error("热度排行:获取锁失败", logger.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := lock.Unlock(context.Background()); err != nil {
+			j.l.Warn("热度排行:释放锁失败", logger.Error(err))
+		}
+	}()
+
+	if err := j.svc.Decay(ctx); err != nil {
+		j.l.Error("热度排行:衰减失败", logger.Error(err))
+		return
+	}
+	n, err := j.svc.Snapshot(ctx)
+	if err != nil {
+		j.l.Error("热度排行:快照失败", logger.Error(err))
+		return
+	}
+	j.l.Debug("热度排行:快照成功", logger.Field{Key: "count", Val: n})
+}
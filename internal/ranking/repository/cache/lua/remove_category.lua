@@ -0,0 +1,11 @@
+-- 类目排行为空时把类目移出类目集合，并删除它的衰减时间；判断和删除是原子的，不会误删刚累加了热度的类目
+-- KEYS[1] 类目排行 zset，KEYS[2] 类目集合，KEYS[3] 每个排行上次衰减时间的 hash
+-- ARGV: 类目 id
+-- 返回是否移除（1 移除）
+if redis.call("exists", KEYS[1]) == 1 then
+    return 0
+end
+
+redis.call("srem", KEYS[2], ARGV[1])
+redis.call("hdel", KEYS[3], ARGV[1])
+return 1
@@ -0,0 +1,14 @@
+-- 按距这个排行上次衰减经过的时间衰减热度，并记录本次衰减时间；衰减和记录时间是原子的，中途失败不会重复衰减
+-- KEYS[1] 排行 zset，KEYS[2] 每个排行上次衰减时间的 hash
+-- ARGV: 排行在 hash 中的 field，当前时间（毫秒），半衰期（毫秒），最低热度
+-- 返回衰减后排行中的商品数，从未衰减过时只记录时间，返回 -1
+local last = redis.call("hget", KEYS[2], ARGV[1])
+redis.call("hset", KEYS[2], ARGV[1], ARGV[2])
+if not last then
+    return -1
+end
+
+local factor = 0.5 ^ ((tonumber(ARGV[2]) - tonumber(last)) / tonumber(ARGV[3]))
+redis.call("zunionstore", KEYS[1], 1, KEYS[1], "weights", tostring(factor))
+redis.call("zremrangebyscore", KEYS[1], "-inf", "(" .. ARGV[4])
+return redis.call("zcard", KEYS[1])
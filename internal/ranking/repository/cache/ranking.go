@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mall/internal/ranking/domain"
+)
+
+//go:embed lua/scale.lua
+var luaScale string
+
+//go:embed lua/remove_category.lua
+var luaRemoveCategory string
+
+const (
+	// 有排行数据的类目 id 集合，衰减和快照时遍历
+	categoriesKey = "ranking:hot:categories"
+	// 每个排行上一次衰减的时间，field 为类目 id，全站排行为 0
+	decayedAtKey = "ranking:hot:decayed_at"
+)
+
+// RankingCache 热度排行，全站和每个类目各一个 ZSET，member 为商品 id，score 为热度
+type RankingCache struct {
+	cmd redis.Cmdable
+}
+
+func NewRankingCache(cmd redis.Cmdable) *RankingCache {
+	return &RankingCache{
+		cmd: cmd,
+	}
+}
+
+// Incr 同时累加全站和所属类目的热度
+func (cache *RankingCache) Incr(ctx context.Context, productId, categoryId uint64, score float64) error {
+	member := strconv.FormatUint(productId, 10)
+	_, err := cache.cmd.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZIncrBy(ctx, cache.key(0), score, member)
+		if categoryId > 0 {
+			pipe.ZIncrBy(ctx, cache.key(categoryId), score, member)
+			pipe.SAdd(ctx, categoriesKey, categoryId)
+		}
+		return nil
+	})
+	return err
+}
+
+// Top 热度最高的 n 个商品，key 不存在时返回 false
+func (cache *RankingCache) Top(ctx context.Context, categoryId uint64, n int) ([]domain.Entry, bool, error) {
+	key := cache.key(categoryId)
+	zs, err := cache.cmd.ZRevRangeWithScores(ctx, key, 0, int64(n-1)).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(zs) == 0 {
+		exists, err := cache.cmd.Exists(ctx, key).Result()
+		return nil, exists > 0, err
+	}
+
+	res := make([]domain.Entry, 0, len(zs))
+	for _, z := range zs {
+		id, err := strconv.ParseUint(z.Member.(string), 10, 64)
+		if err != nil {
+			continue
+		}
+		res = append(res, domain.Entry{ProductId: id, Score: z.Score})
+	}
+	return res, true, nil
+}
+
+// Load 用快照恢复排行，只补充不存在的商品，不覆盖恢复期间新累加的热度
+func (cache *RankingCache) Load(ctx context.Context, categoryId uint64, entries []domain.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	zs := make([]redis.Z, 0, len(entries))
+	for _, e := range entries {
+		zs = append(zs, redis.Z{Score: e.Score, Member: strconv.FormatUint(e.ProductId, 10)})
+	}
+	_, err := cache.cmd.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAddNX(ctx, cache.key(categoryId), zs...)
+		if categoryId > 0 {
+			pipe.SAdd(ctx, categoriesKey, categoryId)
+		}
+		return nil
+	})
+	return err
+}
+
+func (cache *RankingCache) Categories(ctx context.Context) ([]uint64, error) {
+	members, err := cache.cmd.SMembers(ctx, categoriesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]uint64, 0, len(members))
+	for _, m := range members {
+		id, err := strconv.ParseUint(m, 10, 64)
+		if err != nil {
+			continue
+		}
+		res = append(res, id)
+	}
+	return res, nil
+}
+
+// Scale 按距这个排行上次衰减经过的时间衰减热度，并删除低于 min 的商品，返回衰减后排行中的商品数；
+// 从未衰减过的排行只记录时间，返回 -1
+func (cache *RankingCache) Scale(ctx context.Context, categoryId uint64, now time.Time, halfLife time.Duration, min float64) (int64, error) {
+	return cache.cmd.Eval(ctx, luaScale, []string{cache.key(categoryId), decayedAtKey},
+		categoryId, now.UnixMilli(), halfLife.Milliseconds(), min).Int64()
+}
+
+// RemoveCategory 类目排行为空时移出类目集合，返回是否移除
+func (cache *RankingCache) RemoveCategory(ctx context.Context, categoryId uint64) (bool, error) {
+	return cache.cmd.Eval(ctx, luaRemoveCategory, []string{cache.key(categoryId), categoriesKey, decayedAtKey},
+		categoryId).Bool()
+}
+
+func (cache *RankingCache) key(categoryId uint64) string {
+	if categoryId == 0 {
+		return "ranking:hot:global"
+	}
+	return fmt.Sprintf("ranking:hot:category:%d", categoryId)
+}
@@ -0,0 +1,10 @@
+package dao
+
+// HotRanking 热度排行的快照，Redis 数据丢失后从这里恢复
+type HotRanking struct {
+	Id         uint64  `gorm:"primaryKey,autoIncrement"`
+	CategoryId uint64  `gorm:"not null;uniqueIndex:uk_category_product,priority:1"` // 0 表示全站排行
+	ProductId  uint64  `gorm:"not null;uniqueIndex:uk_category_product,priority:2"`
+	Score      float64 `gorm:"not null"`
+	SnapshotAt int64   `gorm:"not null"`
+}
@@ -0,0 +1,63 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"mall/internal/ranking/domain"
+	"mall/pkg/gormx"
+)
+
+type RankingDao struct {
+	db  *gorm.DB
+	uow *gormx.UnitOfWork
+}
+
+func NewRankingDao(db *gorm.DB) *RankingDao {
+	return &RankingDao{
+		db:  db,
+		uow: gormx.NewUnitOfWork(db),
+	}
+}
+
+// ReplaceSnapshot 在一个事务中替换某个排行的快照
+func (dao *RankingDao) ReplaceSnapshot(ctx context.Context, categoryId uint64, entries []domain.Entry, now int64) error {
+	return dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+		if err := db.Where("category_id = ?", categoryId).Delete(&HotRanking{}).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+
+		rows := make([]HotRanking, 0, len(entries))
+		for _, e := range entries {
+			rows = append(rows, HotRanking{
+				CategoryId: categoryId,
+				ProductId:  e.ProductId,
+				Score:      e.Score,
+				SnapshotAt: now,
+			})
+		}
+		return db.Create(&rows).Error
+	})
+}
+
+func (dao *RankingDao) FindSnapshot(ctx context.Context, categoryId uint64) ([]domain.Entry, error) {
+	var rows []HotRanking
+	err := dao.db.WithContext(ctx).
+		Where("category_id = ?", categoryId).
+		Order("score DESC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Entry, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, domain.Entry{ProductId: row.ProductId, Score: row.Score})
+	}
+	return res, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"mall/internal/ranking/domain"
+	"mall/internal/ranking/repository/cache"
+	"mall/internal/ranking/repository/dao"
+)
+
+type RankingRepository struct {
+	dao   *dao.RankingDao
+	cache *cache.RankingCache
+}
+
+func NewRankingRepository(dao *dao.RankingDao, cache *cache.RankingCache) *RankingRepository {
+	return &RankingRepository{
+		dao:   dao,
+		cache: cache,
+	}
+}
+
+func (repo *RankingRepository) Incr(ctx context.Context, productId, categoryId uint64, score float64) error {
+	return repo.cache.Incr(ctx, productId, categoryId, score)
+}
+
+// FindTop 读取排行，Redis 中没有这个排行时从快照恢复
+func (repo *RankingRepository) FindTop(ctx context.Context, categoryId uint64, n int) ([]domain.Entry, error) {
+	entries, exists, err := repo.cache.Top(ctx, categoryId, n)
+	if err != nil || exists {
+		return entries, err
+	}
+
+	snapshot, err := repo.dao.FindSnapshot(ctx, categoryId)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.cache.Load(ctx, categoryId, snapshot); err != nil {
+		return nil, err
+	}
+	if len(snapshot) > n {
+		snapshot = snapshot[:n]
+	}
+	return snapshot, nil
+}
+
+// FindCachedTop 只读 Redis，供快照使用，避免把旧快照写回去
+func (repo *RankingRepository) FindCachedTop(ctx context.Context, categoryId uint64, n int) ([]domain.Entry, error) {
+	entries, _, err := repo.cache.Top(ctx, categoryId, n)
+	return entries, err
+}
+
+func (repo *RankingRepository) FindCategories(ctx context.Context) ([]uint64, error) {
+	return repo.cache.Categories(ctx)
+}
+
+func (repo *RankingRepository) Scale(ctx context.Context, categoryId uint64, now time.Time, halfLife time.Duration, min float64) (int64, error) {
+	return repo.cache.Scale(ctx, categoryId, now, halfLife, min)
+}
+
+func (repo *RankingRepository) RemoveCategory(ctx context.Context, categoryId uint64) (bool, error) {
+	return repo.cache.RemoveCategory(ctx, categoryId)
+}
+
+func (repo *RankingRepository) SaveSnapshot(ctx context.Context, categoryId uint64, entries []domain.Entry, now int64) error {
+	return repo.dao.ReplaceSnapshot(ctx, categoryId, entries, now)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	pdomain "mall/internal/product/domain"
+	prepo "mall/internal/product/repository"
+	"mall/internal/ranking/domain"
+	"mall/internal/ranking/repository"
+)
+
+const (
+	// 热度半衰期，一天前的一次浏览只相当于现在的半次
+	halfLife = time.Hour * 24
+	// 衰减后低于这个热度的商品移出排行，避免 ZSET 无限增长
+	minScore = 0.01
+	// 每个排行保存到快照的商品数量，也是接口最多能返回的数量
+	snapshotSize = 100
+)
+
+// 不同行为对热度的贡献，加购和购买按件数累加
+var eventWeights = map[string]float64{
+	pdomain.EventView:      1,
+	pdomain.EventAddToCart: 3,
+	pdomain.EventPurchase:  5,
+}
+
+type RankingService struct {
+	repo        *repository.RankingRepository
+	productRepo *prepo.ProductRepository
+}
+
+func NewRankingService(repo *repository.RankingRepository, productRepo *prepo.ProductRepository) *RankingService {
+	return &RankingService{
+		repo:        repo,
+		productRepo: productRepo,
+	}
+}
+
+// OnProductEvent 按行为类型累加商品热度
+func (svc *RankingService) OnProductEvent(ctx context.Context, evt pdomain.ProductEvent) error {
+	weight, ok := eventWeights[evt.Type]
+	if !ok {
+		return nil
+	}
+
+	return svc.repo.Incr(ctx, evt.ProductId, evt.CategoryId, weight*float64(max(evt.Count, 1)))
+}
+
+// GetTop 热度最高的 n 个商品，categoryId 为 0 时为全站排行
+// 排行中的商品可能已经下架或删除，返回前过滤掉
+func (svc *RankingService) GetTop(ctx context.Context, categoryId uint64, n int) ([]domain.HotProduct, error) {
+	if n < 1 || n > snapshotSize {
+		n = 20
+	}
+
+	// 多取一些，过滤掉不可售的商品后仍然尽量凑够 n 个
+	entries, err := svc.repo.FindTop(ctx, categoryId, min(n*2, snapshotSize))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint64, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ProductId)
+	}
+	summaries, err := svc.productRepo.FindProductSummaries(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	summaryOf := make(map[uint64]pdomain.ProductSummary, len(summaries))
+	for _, s := range summaries {
+		summaryOf[s.Id] = s
+	}
+
+	res := make([]domain.HotProduct, 0, n)
+	for _, e := range entries {
+		s, ok := summaryOf[e.ProductId]
+		if !ok || !s.IsActive || s.IsDeleted {
+			continue
+		}
+		res = append(res, domain.HotProduct{
+			Rank:      len(res) + 1,
+			ProductId: s.Id,
+			Name:      s.Name,
+			Price:     s.Price,
+			ImageUrl:  s.ImageUrl,
+			Score:     e.Score,
+		})
+		if len(res) == n {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+// Decay 按距上次衰减经过的时间衰减所有排行，每个排行单独记录衰减时间
+// 衰减系数由经过的时间算出，任务延迟、漏跑或中途失败都不影响结果
+func (svc *RankingService) Decay(ctx context.Context) error {
+	now := time.Now()
+	categories, err := svc.repo.FindCategories(ctx)
+	if err != nil {
+		return err
+	}
+	for _, categoryId := range append([]uint64{0}, categories...) {
+		if _, err := svc.repo.Scale(ctx, categoryId, now, halfLife, minScore); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Snapshot 把 Redis 中的排行保存到数据库，返回保存的排行数量
+func (svc *RankingService) Snapshot(ctx context.Context) (int, error) {
+	now := time.Now().UnixMilli()
+
+	global, err := svc.repo.FindCachedTop(ctx, 0, snapshotSize)
+	if err != nil {
+		return 0, err
+	}
+	// 全站排行为空多半是 Redis 数据丢失，保留旧快照等待恢复
+	if len(global) == 0 {
+		return 0, nil
+	}
+	if err := svc.repo.SaveSnapshot(ctx, 0, global, now); err != nil {
+		return 0, err
+	}
+
+	categories, err := svc.repo.FindCategories(ctx)
+	if err != nil {
+		return 1, err
+	}
+	for i, categoryId := range categories {
+		entries, err := svc.repo.FindCachedTop(ctx, categoryId, snapshotSize)
+		if err != nil {
+			return i + 1, err
+		}
+		if err := svc.repo.SaveSnapshot(ctx, categoryId, entries, now); err != nil {
+			return i + 1, err
+		}
+		// 快照已经清空，类目排行重新有热度时会再加入类目集合
+		if len(entries) == 0 {
+			if _, err := svc.repo.RemoveCategory(ctx, categoryId); err != nil {
+				return i + 1, err
+			}
+		}
+	}
+
+	return len(categories) + 1, nil
+}
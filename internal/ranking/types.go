@@ -0,0 +1,13 @@
+package ranking
+
+import (
+	"mall/internal/ranking/job"
+	"mall/internal/ranking/service"
+	"mall/internal/ranking/web"
+)
+
+type Handler = web.RankingHandler
+
+type RankingJob = job.RankingJob
+
+type RankingService = service.RankingService
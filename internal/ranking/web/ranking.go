@@ -0,0 +1,63 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/ranking/service"
+	"mall/pkg/ginx"
+)
+
+type RankingHandler struct {
+	svc *service.RankingService
+}
+
+func NewRankingHandler(svc *service.RankingService) *RankingHandler {
+	return &RankingHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *RankingHandler) RegisterRoute(r *gin.Engine) {
+	group := r.Group("api/rankings")
+	{
+		group.GET("/hot", ctl.GetGlobalTop())                  // 全站热门
+		group.GET("/hot/categories/:id", ctl.GetCategoryTop()) // 类目热门
+	}
+}
+
+func (ctl *RankingHandler) GetGlobalTop() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctl.top(c, 0)
+	}
+}
+
+func (ctl *RankingHandler) GetCategoryTop() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		categoryId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil || categoryId == 0 {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("invalid category id")))
+			return
+		}
+
+		ctl.top(c, categoryId)
+	}
+}
+
+func (ctl *RankingHandler) top(c *gin.Context, categoryId uint64) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("limit must be a number")))
+		return
+	}
+
+	products, err := ctl.svc.GetTop(c.Request.Context(), categoryId, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+		return
+	}
+
+	c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(products)))
+}
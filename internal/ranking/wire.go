@@ -0,0 +1,51 @@
+//go:build wireinject
+
+package ranking
+
+import (
+	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"mall/internal/product"
+	"mall/internal/ranking/job"
+	"mall/internal/ranking/repository"
+	"mall/internal/ranking/repository/cache"
+	"mall/internal/ranking/repository/dao"
+	"mall/internal/ranking/service"
+	"mall/internal/ranking/web"
+	"mall/pkg/logger"
+)
+
+var rankingSet = wire.NewSet(
+	dao.NewRankingDao,
+	cache.NewRankingCache,
+
+	repository.NewRankingRepository,
+
+	product.NewProductRepository,
+
+	service.NewRankingService,
+)
+
+func InitRankingService(db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache) *service.RankingService {
+	wire.Build(
+		rankingSet,
+	)
+	return new(service.RankingService)
+}
+
+func InitRankingHandler(db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache) *web.RankingHandler {
+	wire.Build(
+		rankingSet,
+		web.NewRankingHandler,
+	)
+	return new(web.RankingHandler)
+}
+
+func InitRankingJob(db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache, l logger.Logger) *job.RankingJob {
+	wire.Build(
+		rankingSet,
+		job.NewRankingJob,
+	)
+	return new(job.RankingJob)
+}
@@ -0,0 +1,22 @@
+package domain
+
+const (
+	SourceCooccur = "cooccur" // 同一购物车或同一浏览记录中共同出现
+	SourcePopular = "popular" // 同类目热门商品兜底
+)
+
+// Item 离线计算出的一条推荐
+type Item struct {
+	ProductId uint64
+	Score     float64
+	Source    string
+}
+
+// Recommendation 返回给前端的推荐商品
+type Recommendation struct {
+	ProductId uint64  `json:"productId"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	ImageUrl  string  `json:"imageUrl"`
+	Source    string  `json:"source"`
+}
@@ -0,0 +1,72 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mall/internal/recommend/service"
+	"mall/pkg/logger"
+	"mall/pkg/redisx"
+)
+
+const rebuildLockKey = "recommend:rebuild:lock"
+
+// RebuildJob 定期重新计算商品推荐，多实例通过 Redis 锁互斥
+type RebuildJob struct {
+	svc      *service.RecommendService
+	cmd      redis.Cmdable
+	l        logger.Logger
+	interval time.Duration
+}
+
+func NewRebuildJob(svc *service.RecommendService, cmd redis.Cmdable, l logger.Logger) *RebuildJob {
+	return &RebuildJob{
+		svc:      svc,
+		cmd:      cmd,
+		l:        l,
+		interval: time.Hour * 6,
+	}
+}
+
+// Start 启动时先计算一次，新部署或长时间停机后不用等到下一个周期才有推荐
+func (j *RebuildJob) Start(ctx context.Context) {
+	j.run(ctx)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.run(ctx)
+		}
+	}
+}
+
+func (j *RebuildJob) run(ctx context.Context) {
+	lock := redisx.NewLock(j.cmd, rebuildLockKey, j.interval)
+	ok, err := lock.TryLock(ctx)
+	if err != nil {
+		j.l.Error("计算推荐:获取锁失败", logger.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := lock.Unlock(context.Background()); err != nil {
+			j.l.Warn("计算推荐:释放锁失败", logger.Error(err))
+		}
+	}()
+
+	n, err := j.svc.Rebuild(ctx)
+	if err != nil {
+		j.l.Error("计算推荐:执行失败", logger.Error(err))
+		return
+	}
+	j.l.Info("计算推荐:执行成功", logger.Field{Key: "count", Val: n})
+}
@@ -0,0 +1,20 @@
+package dao
+
+// Recommendation 商品的相关推荐，每次离线计算后整体替换
+type Recommendation struct {
+	Id        uint64  `gorm:"primaryKey,autoIncrement"`
+	ProductId uint64  `gorm:"not null;uniqueIndex:uk_product_item,priority:1"`
+	ItemId    uint64  `gorm:"not null;uniqueIndex:uk_product_item,priority:2"` // 被推荐的商品
+	Score     float64 `gorm:"not null"`
+	Source    string  `gorm:"type:varchar(16);not null"` // cooccur 或 popular
+	UpdateAt  int64   `gorm:"not null;index"`            // 计算时间，早于最近一次计算的记录已失效
+}
+
+// CategoryPopular 类目下的热门商品，离线任务没有覆盖到的商品用它兜底
+type CategoryPopular struct {
+	Id         uint64  `gorm:"primaryKey,autoIncrement"`
+	CategoryId uint64  `gorm:"not null;uniqueIndex:uk_category_product,priority:1"`
+	ProductId  uint64  `gorm:"not null;uniqueIndex:uk_category_product,priority:2"`
+	Score      float64 `gorm:"not null"`
+	UpdateAt   int64   `gorm:"not null;index"`
+}
@@ -0,0 +1,125 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"mall/internal/recommend/domain"
+	"mall/pkg/gormx"
+)
+
+type RecommendDao struct {
+	db  *gorm.DB
+	uow *gormx.UnitOfWork
+}
+
+func NewRecommendDao(db *gorm.DB) *RecommendDao {
+	return &RecommendDao{
+		db:  db,
+		uow: gormx.NewUnitOfWork(db),
+	}
+}
+
+// ReplaceRecommendations 在一个事务中替换商品的全部推荐，读到的结果不会是新旧混合的
+func (dao *RecommendDao) ReplaceRecommendations(ctx context.Context, productId uint64, items []domain.Item, now int64) error {
+	return dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+		if err := db.Where("product_id = ?", productId).Delete(&Recommendation{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		recs := make([]Recommendation, 0, len(items))
+		for _, item := range items {
+			recs = append(recs, Recommendation{
+				ProductId: productId,
+				ItemId:    item.ProductId,
+				Score:     item.Score,
+				Source:    item.Source,
+				UpdateAt:  now,
+			})
+		}
+		return db.Create(&recs).Error
+	})
+}
+
+// DeleteRecommendationsBefore 删除本次计算没有覆盖到的商品的旧推荐
+func (dao *RecommendDao) DeleteRecommendationsBefore(ctx context.Context, before int64) (int64, error) {
+	res := dao.db.WithContext(ctx).Where("update_at < ?", before).Delete(&Recommendation{})
+	return res.RowsAffected, res.Error
+}
+
+func (dao *RecommendDao) FindRecommendations(ctx context.Context, productId uint64, limit int) ([]domain.Item, error) {
+	var recs []Recommendation
+	err := dao.db.WithContext(ctx).
+		Where("product_id = ?", productId).
+		Order("score DESC").
+		Limit(limit).
+		Find(&recs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.Item, 0, len(recs))
+	for _, rec := range recs {
+		items = append(items, domain.Item{
+			ProductId: rec.ItemId,
+			Score:     rec.Score,
+			Source:    rec.Source,
+		})
+	}
+	return items, nil
+}
+
+func (dao *RecommendDao) ReplaceCategoryPopular(ctx context.Context, categoryId uint64, items []domain.Item, now int64) error {
+	return dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+		if err := db.Where("category_id = ?", categoryId).Delete(&CategoryPopular{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		rows := make([]CategoryPopular, 0, len(items))
+		for _, item := range items {
+			rows = append(rows, CategoryPopular{
+				CategoryId: categoryId,
+				ProductId:  item.ProductId,
+				Score:      item.Score,
+				UpdateAt:   now,
+			})
+		}
+		return db.Create(&rows).Error
+	})
+}
+
+func (dao *RecommendDao) DeleteCategoryPopularBefore(ctx context.Context, before int64) (int64, error) {
+	res := dao.db.WithContext(ctx).Where("update_at < ?", before).Delete(&CategoryPopular{})
+	return res.RowsAffected, res.Error
+}
+
+func (dao *RecommendDao) FindCategoryPopular(ctx context.Context, categoryId uint64, limit int) ([]domain.Item, error) {
+	var rows []CategoryPopular
+	err := dao.db.WithContext(ctx).
+		Where("category_id = ?", categoryId).
+		Order("score DESC").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.Item, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, domain.Item{
+			ProductId: row.ProductId,
+			Score:     row.Score,
+			Source:    domain.SourcePopular,
+		})
+	}
+	return items, nil
+}
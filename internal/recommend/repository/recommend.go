@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"mall/internal/recommend/domain"
+	"mall/internal/recommend/repository/dao"
+)
+
+type RecommendRepository struct {
+	dao *dao.RecommendDao
+}
+
+func NewRecommendRepository(dao *dao.RecommendDao) *RecommendRepository {
+	return &RecommendRepository{
+		dao: dao,
+	}
+}
+
+func (repo *RecommendRepository) ReplaceRecommendations(ctx context.Context, productId uint64, items []domain.Item, now int64) error {
+	return repo.dao.ReplaceRecommendations(ctx, productId, items, now)
+}
+
+func (repo *RecommendRepository) DeleteRecommendationsBefore(ctx context.Context, before int64) (int64, error) {
+	return repo.dao.DeleteRecommendationsBefore(ctx, before)
+}
+
+func (repo *RecommendRepository) FindRecommendations(ctx context.Context, productId uint64, limit int) ([]domain.Item, error) {
+	return repo.dao.FindRecommendations(ctx, productId, limit)
+}
+
+func (repo *RecommendRepository) ReplaceCategoryPopular(ctx context.Context, categoryId uint64, items []domain.Item, now int64) error {
+	return repo.dao.ReplaceCategoryPopular(ctx, categoryId, items, now)
+}
+
+func (repo *RecommendRepository) DeleteCategoryPopularBefore(ctx context.Context, before int64) (int64, error) {
+	return repo.dao.DeleteCategoryPopularBefore(ctx, before)
+}
+
+func (repo *RecommendRepository) FindCategoryPopular(ctx context.Context, categoryId uint64, limit int) ([]domain.Item, error) {
+	return repo.dao.FindCategoryPopular(ctx, categoryId, limit)
+}
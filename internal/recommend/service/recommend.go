@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	cdomain "mall/internal/cart/domain"
+	crepo "mall/internal/cart/repository"
+	pdomain "mall/internal/product/domain"
+	prepo "mall/internal/product/repository"
+	"mall/internal/recommend/domain"
+	"mall/internal/recommend/repository"
+)
+
+var ErrProductNotFound = errors.New("product not found")
+
+const (
+	// 每个商品保存的推荐数量，接口最多返回这么多
+	maxItems = 20
+	// 单个购物车或浏览记录参与统计的商品数量上限，避免个别超大购物车拖慢计算
+	maxBasketSize = 50
+	// 每批读取的购物车用户数
+	basketBatchSize = 500
+	// 加购比浏览更能说明购买意向
+	cartWeight = 2.0
+	viewWeight = 1.0
+)
+
+type RecommendService struct {
+	repo        *repository.RecommendRepository
+	cartRepo    crepo.CartRepository
+	viewRepo    *prepo.ViewHistoryRepository
+	productRepo *prepo.ProductRepository
+}
+
+func NewRecommendService(repo *repository.RecommendRepository, cartRepo crepo.CartRepository, viewRepo *prepo.ViewHistoryRepository,
+	productRepo *prepo.ProductRepository) *RecommendService {
+	return &RecommendService{
+		repo:        repo,
+		cartRepo:    cartRepo,
+		viewRepo:    viewRepo,
+		productRepo: productRepo,
+	}
+}
+
+// GetRecommendations 商品的相关推荐，推荐不足时用同类目热门商品补齐
+// 离线结果可能已经过时，返回前按商品当前状态过滤掉下架、删除和无货的商品
+func (svc *RecommendService) GetRecommendations(ctx context.Context, productId string, limit int) ([]domain.Recommendation, error) {
+	id, err := strconv.Atoi(productId)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 1 || limit > maxItems {
+		limit = maxItems
+	}
+
+	self, err := svc.productRepo.FindProductSummaries(ctx, []uint64{uint64(id)})
+	if err != nil {
+		return nil, err
+	}
+	if len(self) == 0 || self[0].IsDeleted {
+		return nil, ErrProductNotFound
+	}
+
+	items, err := svc.repo.FindRecommendations(ctx, uint64(id), maxItems)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < maxItems {
+		popular, err := svc.repo.FindCategoryPopular(ctx, self[0].CategoryId, maxItems)
+		if err != nil {
+			return nil, err
+		}
+		items = fill(items, popular, uint64(id), maxItems)
+	}
+
+	ids := make([]uint64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductId)
+	}
+	summaries, err := svc.productRepo.FindProductSummaries(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	summaryOf := make(map[uint64]pdomain.ProductSummary, len(summaries))
+	for _, s := range summaries {
+		summaryOf[s.Id] = s
+	}
+
+	res := make([]domain.Recommendation, 0, limit)
+	for _, item := range items {
+		s, ok := summaryOf[item.ProductId]
+		if !ok || !available(s) {
+			continue
+		}
+		res = append(res, domain.Recommendation{
+			ProductId: s.Id,
+			Name:      s.Name,
+			Price:     s.Price,
+			ImageUrl:  s.ImageUrl,
+			Source:    item.Source,
+		})
+		if len(res) == limit {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+// Rebuild 根据所有购物车和浏览记录重新计算推荐，返回有推荐结果的商品数量
+// 两个商品出现在同一个购物车或同一个人的浏览记录中记为一次共现，
+// 得分为共现次数除以两者各自出现次数的几何平均，避免热门商品出现在所有商品的推荐里
+func (svc *RecommendService) Rebuild(ctx context.Context) (int, error) {
+	now := time.Now().UnixMilli()
+	c := newCounter()
+
+	var afterUid uint64
+	for {
+		items, err := svc.cartRepo.FindBaskets(ctx, afterUid, basketBatchSize)
+		if err != nil {
+			return 0, err
+		}
+		if len(items) == 0 {
+			break
+		}
+		for _, basket := range groupByUser(items) {
+			c.add(basket, cartWeight)
+		}
+		afterUid = items[len(items)-1].UserID
+	}
+
+	err := svc.viewRepo.ScanViews(ctx, func(productIds []uint64) error {
+		c.add(productIds, viewWeight)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	summaryOf, err := svc.findSummaries(ctx, c.products())
+	if err != nil {
+		return 0, err
+	}
+
+	// 同类目热门，按出现次数排序
+	byCategory := make(map[uint64][]domain.Item)
+	for id, s := range summaryOf {
+		if !s.IsActive || s.IsDeleted {
+			continue
+		}
+		byCategory[s.CategoryId] = append(byCategory[s.CategoryId], domain.Item{
+			ProductId: id,
+			Score:     c.occur[id],
+			Source:    domain.SourcePopular,
+		})
+	}
+	for categoryId, items := range byCategory {
+		items = top(items, maxItems)
+		byCategory[categoryId] = items
+		if err := svc.repo.ReplaceCategoryPopular(ctx, categoryId, items, now); err != nil {
+			return 0, err
+		}
+	}
+
+	n := 0
+	for id, s := range summaryOf {
+		if s.IsDeleted {
+			continue
+		}
+		var items []domain.Item
+		for other, count := range c.pair[id] {
+			o, ok := summaryOf[other]
+			if !ok || !o.IsActive || o.IsDeleted {
+				continue
+			}
+			items = append(items, domain.Item{
+				ProductId: other,
+				Score:     count / math.Sqrt(c.occur[id]*c.occur[other]),
+				Source:    domain.SourceCooccur,
+			})
+		}
+		items = fill(top(items, maxItems), byCategory[s.CategoryId], id, maxItems)
+		if err := svc.repo.ReplaceRecommendations(ctx, id, items, now); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	// 本次没有统计到的商品，旧的推荐已经不可信，删掉后由接口用类目热门兜底
+	if _, err := svc.repo.DeleteRecommendationsBefore(ctx, now); err != nil {
+		return n, err
+	}
+	if _, err := svc.repo.DeleteCategoryPopularBefore(ctx, now); err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+func (svc *RecommendService) findSummaries(ctx context.Context, ids []uint64) (map[uint64]pdomain.ProductSummary, error) {
+	res := make(map[uint64]pdomain.ProductSummary, len(ids))
+	for start := 0; start < len(ids); start += basketBatchSize {
+		end := min(start+basketBatchSize, len(ids))
+		summaries, err := svc.productRepo.FindProductSummaries(ctx, ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range summaries {
+			res[s.Id] = s
+		}
+	}
+	return res, nil
+}
+
+// counter 统计商品的出现次数和两两共现次数，均为加权值
+type counter struct {
+	occur map[uint64]float64
+	pair  map[uint64]map[uint64]float64
+}
+
+func newCounter() *counter {
+	return &counter{
+		occur: make(map[uint64]float64),
+		pair:  make(map[uint64]map[uint64]float64),
+	}
+}
+
+func (c *counter) add(ids []uint64, weight float64) {
+	seen := make(map[uint64]struct{}, len(ids))
+	basket := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		basket = append(basket, id)
+		if len(basket) == maxBasketSize {
+			break
+		}
+	}
+
+	for _, a := range basket {
+		c.occur[a] += weight
+		if len(basket) < 2 {
+			continue
+		}
+		if c.pair[a] == nil {
+			c.pair[a] = make(map[uint64]float64)
+		}
+		for _, b := range basket {
+			if a != b {
+				c.pair[a][b] += weight
+			}
+		}
+	}
+}
+
+func (c *counter) products() []uint64 {
+	ids := make([]uint64, 0, len(c.occur))
+	for id := range c.occur {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func groupByUser(items []cdomain.CartItem) [][]uint64 {
+	var res [][]uint64
+	for i, item := range items {
+		if i == 0 || item.UserID != items[i-1].UserID {
+			res = append(res, nil)
+		}
+		res[len(res)-1] = append(res[len(res)-1], item.ProductID)
+	}
+	return res
+}
+
+// top 按得分倒序取前 n 个，得分相同时按商品 id 排序保证结果稳定
+func top(items []domain.Item, n int) []domain.Item {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].ProductId < items[j].ProductId
+	})
+	if len(items) > n {
+		items = items[:n]
+	}
+	return items
+}
+
+// fill 用 backup 补齐 items 到 n 个，跳过商品自身和已有的商品
+func fill(items, backup []domain.Item, self uint64, n int) []domain.Item {
+	seen := make(map[uint64]struct{}, len(items))
+	for _, item := range items {
+		seen[item.ProductId] = struct{}{}
+	}
+	for _, item := range backup {
+		if len(items) >= n {
+			break
+		}
+		if _, ok := seen[item.ProductId]; ok || item.ProductId == self {
+			continue
+		}
+		seen[item.ProductId] = struct{}{}
+		items = append(items, item)
+	}
+	return items
+}
+
+func available(s pdomain.ProductSummary) bool {
+	return s.IsActive && !s.IsDeleted && s.Stock > 0
+}
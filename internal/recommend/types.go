@@ -0,0 +1,10 @@
+package recommend
+
+import (
+	"mall/internal/recommend/job"
+	"mall/internal/recommend/web"
+)
+
+type Handler = web.RecommendHandler
+
+type RebuildJob = job.RebuildJob
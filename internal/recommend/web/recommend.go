@@ -0,0 +1,48 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/recommend/service"
+	"mall/pkg/ginx"
+)
+
+type RecommendHandler struct {
+	svc *service.RecommendService
+}
+
+func NewRecommendHandler(svc *service.RecommendService) *RecommendHandler {
+	return &RecommendHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *RecommendHandler) RegisterRoute(r *gin.Engine) {
+	r.GET("api/products/:id/recommendations", ctl.GetRecommendations()) // 相关推荐
+}
+
+func (ctl *RecommendHandler) GetRecommendations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("limit must be a number")))
+			return
+		}
+
+		recs, err := ctl.svc.GetRecommendations(c.Request.Context(), c.Param("id"), limit)
+		switch {
+		case errors.Is(err, service.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(recs)))
+	}
+}
@@ -0,0 +1,44 @@
+//go:build wireinject
+
+package recommend
+
+import (
+	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"mall/internal/cart"
+	"mall/internal/product"
+	"mall/internal/recommend/job"
+	"mall/internal/recommend/repository"
+	"mall/internal/recommend/repository/dao"
+	"mall/internal/recommend/service"
+	"mall/internal/recommend/web"
+	"mall/pkg/logger"
+)
+
+var recommendSet = wire.NewSet(
+	dao.NewRecommendDao,
+
+	repository.NewRecommendRepository,
+
+	product.NewViewHistoryRepository,
+	product.NewProductRepository,
+
+	service.NewRecommendService,
+)
+
+func InitRecommendHandler(db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache, cartRepo cart.Repository) *web.RecommendHandler {
+	wire.Build(
+		recommendSet,
+		web.NewRecommendHandler,
+	)
+	return new(web.RecommendHandler)
+}
+
+func InitRebuildJob(db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache, cartRepo cart.Repository, l logger.Logger) *job.RebuildJob {
+	wire.Build(
+		recommendSet,
+		job.NewRebuildJob,
+	)
+	return new(job.RebuildJob)
+}
@@ -0,0 +1,31 @@
+package domain
+
+// 评价审核状态
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// 评价列表排序方式
+const (
+	SortLatest     = "latest"      // 最新
+	SortHelpful    = "helpful"     // 最有帮助
+	SortRatingDesc = "rating_desc" // 星级从高到低
+	SortRatingAsc  = "rating_asc"  // 星级从低到高
+)
+
+type Review struct {
+	Id           uint64   `json:"id"`
+	ProductId    uint64   `json:"productId"`
+	UserId       uint64   `json:"userId"`
+	MerchantId   uint64   `json:"-"`
+	Rating       int      `json:"rating"` // 1-5 星
+	Content      string   `json:"content"`
+	Images       []string `json:"images"`
+	Status       string   `json:"status"`
+	HelpfulCount int64    `json:"helpfulCount"`
+	Reply        string   `json:"reply"` // 商家回复
+	ReplyAt      int64    `json:"replyAt"`
+	CreateAt     int64    `json:"createAt"`
+}
@@ -0,0 +1,26 @@
+package dao
+
+// Review 商品评价，每个用户对每个商品只能评价一次
+type Review struct {
+	Id           uint64 `gorm:"primaryKey,autoIncrement"`
+	ProductId    uint64 `gorm:"not null;uniqueIndex:uk_product_user,priority:1;index:idx_product_status,priority:1"`
+	UserId       uint64 `gorm:"not null;uniqueIndex:uk_product_user,priority:2"`
+	MerchantId   uint64 `gorm:"not null"` // 商品所属商家，回复评价时校验
+	Rating       int    `gorm:"not null"`
+	Content      string `gorm:"type:text"`
+	Images       string `gorm:"type:text"`                                                                      // 图片 URL 的 JSON 数组
+	Status       string `gorm:"type:varchar(16);not null;index:idx_product_status,priority:2;index:idx_status"` // 审核队列按它查询
+	HelpfulCount int64  `gorm:"not null;default:0"`
+	Reply        string `gorm:"type:text"`
+	ReplyAt      int64
+	CreateAt     int64
+	UpdateAt     int64
+}
+
+// ReviewVote 用户对评价的“有帮助”投票，每人每条评价一票
+type ReviewVote struct {
+	Id       uint64 `gorm:"primaryKey,autoIncrement"`
+	ReviewId uint64 `gorm:"not null;uniqueIndex:uk_review_user,priority:1"`
+	UserId   uint64 `gorm:"not null;uniqueIndex:uk_review_user,priority:2"`
+	CreateAt int64
+}
@@ -0,0 +1,204 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mall/internal/review/domain"
+	"mall/pkg/gormx"
+)
+
+var (
+	ErrReviewNotFound      = errors.New("review not found")
+	ErrReviewDuplicate     = errors.New("product has already been reviewed by the user")
+	ErrReviewAlreadyVoted  = errors.New("review has already been voted by the user")
+	ErrReviewStatusChanged = errors.New("review status has been changed")
+)
+
+type ReviewDao struct {
+	db  *gorm.DB
+	uow *gormx.UnitOfWork
+}
+
+func NewReviewDao(db *gorm.DB) *ReviewDao {
+	return &ReviewDao{
+		db:  db,
+		uow: gormx.NewUnitOfWork(db),
+	}
+}
+
+func (dao *ReviewDao) Insert(ctx context.Context, r domain.Review) (uint64, error) {
+	images, err := json.Marshal(r.Images)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UnixMilli()
+	review := Review{
+		ProductId:  r.ProductId,
+		UserId:     r.UserId,
+		MerchantId: r.MerchantId,
+		Rating:     r.Rating,
+		Content:    r.Content,
+		Images:     string(images),
+		Status:     r.Status,
+		CreateAt:   now,
+		UpdateAt:   now,
+	}
+	err = dao.db.WithContext(ctx).Create(&review).Error
+	if gormx.IsUniqueConflict(err) {
+		return 0, ErrReviewDuplicate
+	}
+
+	return review.Id, err
+}
+
+func (dao *ReviewDao) FindById(ctx context.Context, id uint64) (domain.Review, error) {
+	var review Review
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&review).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Review{}, ErrReviewNotFound
+		}
+		return domain.Review{}, err
+	}
+
+	return dao.toDomain(review), nil
+}
+
+// UpdateStatus 只有当前状态仍为 from 时才修改，防止并发审核重复计算评分，加入 ctx 中的事务
+func (dao *ReviewDao) UpdateStatus(ctx context.Context, id uint64, from, to string) error {
+	res := gormx.DB(ctx, dao.db).Model(&Review{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]any{
+			"status":    to,
+			"update_at": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrReviewStatusChanged
+	}
+
+	return nil
+}
+
+func (dao *ReviewDao) UpdateReply(ctx context.Context, id uint64, reply string) error {
+	now := time.Now().UnixMilli()
+	res := dao.db.WithContext(ctx).Model(&Review{}).Where("id = ?", id).Updates(map[string]any{
+		"reply":     reply,
+		"reply_at":  now,
+		"update_at": now,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrReviewNotFound
+	}
+
+	return nil
+}
+
+// InsertVote 记录投票并增加已通过评价的有帮助数
+func (dao *ReviewDao) InsertVote(ctx context.Context, reviewId, userId uint64) error {
+	return dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+
+		res := db.Model(&Review{}).
+			Where("id = ? AND status = ?", reviewId, domain.ReviewApproved).
+			Update("helpful_count", gorm.Expr("helpful_count + 1"))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrReviewNotFound
+		}
+
+		err := db.Create(&ReviewVote{
+			ReviewId: reviewId,
+			UserId:   userId,
+			CreateAt: time.Now().UnixMilli(),
+		}).Error
+		if gormx.IsUniqueConflict(err) {
+			return ErrReviewAlreadyVoted
+		}
+		return err
+	})
+}
+
+// FindApprovedByProduct 分页查询商品已通过审核的评价
+func (dao *ReviewDao) FindApprovedByProduct(ctx context.Context, productId uint64, sort string, offset, limit int) ([]domain.Review, error) {
+	order := "id DESC"
+	switch sort {
+	case domain.SortHelpful:
+		order = "helpful_count DESC, id DESC"
+	case domain.SortRatingDesc:
+		order = "rating DESC, id DESC"
+	case domain.SortRatingAsc:
+		order = "rating ASC, id DESC"
+	}
+
+	var reviews []Review
+	err := dao.db.WithContext(ctx).
+		Where("product_id = ? AND status = ?", productId, domain.ReviewApproved).
+		Order(order).
+		Offset(offset).Limit(limit).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.toDomains(reviews), nil
+}
+
+// FindPending 待审核队列，先提交的先审核
+func (dao *ReviewDao) FindPending(ctx context.Context, offset, limit int) ([]domain.Review, error) {
+	var reviews []Review
+	err := dao.db.WithContext(ctx).
+		Where("status = ?", domain.ReviewPending).
+		Order("id").
+		Offset(offset).Limit(limit).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.toDomains(reviews), nil
+}
+
+func (dao *ReviewDao) toDomains(reviews []Review) []domain.Review {
+	res := make([]domain.Review, 0, len(reviews))
+	for _, r := range reviews {
+		res = append(res, dao.toDomain(r))
+	}
+	return res
+}
+
+func (dao *ReviewDao) toDomain(r Review) domain.Review {
+	var images []string
+	_ = json.Unmarshal([]byte(r.Images), &images)
+	if images == nil {
+		images = []string{}
+	}
+
+	return domain.Review{
+		Id:           r.Id,
+		ProductId:    r.ProductId,
+		UserId:       r.UserId,
+		MerchantId:   r.MerchantId,
+		Rating:       r.Rating,
+		Content:      r.Content,
+		Images:       images,
+		Status:       r.Status,
+		HelpfulCount: r.HelpfulCount,
+		Reply:        r.Reply,
+		ReplyAt:      r.ReplyAt,
+		CreateAt:     r.CreateAt,
+	}
+}
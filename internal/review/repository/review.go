@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"mall/internal/review/domain"
+	"mall/internal/review/repository/dao"
+)
+
+var (
+	ErrReviewNotFound      = dao.ErrReviewNotFound
+	ErrReviewDuplicate     = dao.ErrReviewDuplicate
+	ErrReviewAlreadyVoted  = dao.ErrReviewAlreadyVoted
+	ErrReviewStatusChanged = dao.ErrReviewStatusChanged
+)
+
+type ReviewRepository struct {
+	dao *dao.ReviewDao
+}
+
+func NewReviewRepository(dao *dao.ReviewDao) *ReviewRepository {
+	return &ReviewRepository{
+		dao: dao,
+	}
+}
+
+func (repo *ReviewRepository) InsertReview(ctx context.Context, review domain.Review) (uint64, error) {
+	return repo.dao.Insert(ctx, review)
+}
+
+func (repo *ReviewRepository) FindReviewById(ctx context.Context, id uint64) (domain.Review, error) {
+	return repo.dao.FindById(ctx, id)
+}
+
+func (repo *ReviewRepository) UpdateStatus(ctx context.Context, id uint64, from, to string) error {
+	return repo.dao.UpdateStatus(ctx, id, from, to)
+}
+
+func (repo *ReviewRepository) UpdateReply(ctx context.Context, id uint64, reply string) error {
+	return repo.dao.UpdateReply(ctx, id, reply)
+}
+
+func (repo *ReviewRepository) InsertVote(ctx context.Context, reviewId, userId uint64) error {
+	return repo.dao.InsertVote(ctx, reviewId, userId)
+}
+
+func (repo *ReviewRepository) FindProductReviews(ctx context.Context, productId uint64, sort string, offset, limit int) ([]domain.Review, error) {
+	return repo.dao.FindApprovedByProduct(ctx, productId, sort, offset, limit)
+}
+
+func (repo *ReviewRepository) FindPendingReviews(ctx context.Context, offset, limit int) ([]domain.Review, error) {
+	return repo.dao.FindPending(ctx, offset, limit)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"unicode/utf8"
+
+	prepo "mall/internal/product/repository"
+	"mall/internal/review/domain"
+	"mall/internal/review/repository"
+	"mall/pkg/gormx"
+)
+
+var (
+	ErrReviewNotFound      = repository.ErrReviewNotFound
+	ErrReviewDuplicate     = repository.ErrReviewDuplicate
+	ErrReviewAlreadyVoted  = repository.ErrReviewAlreadyVoted
+	ErrReviewStatusChanged = repository.ErrReviewStatusChanged
+	ErrProductNotFound     = prepo.ErrProductNotFound
+	ErrInvalidRating       = errors.New("rating must be between 1 and 5")
+	ErrInvalidContent      = errors.New("review content is too long")
+	ErrInvalidImages       = errors.New("invalid review images")
+	ErrReviewOwnProduct    = errors.New("merchant cannot review own product")
+	ErrReviewNotOwned      = errors.New("review does not belong to the merchant's product")
+)
+
+const (
+	maxContentLength = 2000
+	maxReviewImages  = 9
+	maxReplyLength   = 1000
+)
+
+type ReviewService struct {
+	repo        *repository.ReviewRepository
+	productRepo *prepo.ProductRepository
+	uow         *gormx.UnitOfWork
+}
+
+func NewReviewService(repo *repository.ReviewRepository, productRepo *prepo.ProductRepository, uow *gormx.UnitOfWork) *ReviewService {
+	return &ReviewService{
+		repo:        repo,
+		productRepo: productRepo,
+		uow:         uow,
+	}
+}
+
+// AddReview 提交评价，审核通过后才会展示并计入商品评分
+func (svc *ReviewService) AddReview(ctx context.Context, uid uint64, productId string, review domain.Review) (uint64, error) {
+	id, err := strconv.Atoi(productId)
+	if err != nil {
+		return 0, err
+	}
+	if review.Rating < 1 || review.Rating > 5 {
+		return 0, ErrInvalidRating
+	}
+	if utf8.RuneCountInString(review.Content) > maxContentLength {
+		return 0, ErrInvalidContent
+	}
+	if !validImages(review.Images) {
+		return 0, ErrInvalidImages
+	}
+
+	owner, err := svc.productRepo.FindProductOwner(ctx, uint64(id))
+	if err != nil {
+		return 0, err
+	}
+	if owner == uid {
+		return 0, ErrReviewOwnProduct
+	}
+
+	review.ProductId = uint64(id)
+	review.UserId = uid
+	review.MerchantId = owner
+	review.Status = domain.ReviewPending
+	if review.Images == nil {
+		review.Images = []string{}
+	}
+
+	return svc.repo.InsertReview(ctx, review)
+}
+
+func (svc *ReviewService) GetProductReviews(ctx context.Context, productId string, sort string, page, size int) ([]domain.Review, error) {
+	id, err := strconv.Atoi(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc.repo.FindProductReviews(ctx, uint64(id), sort, (page-1)*size, size)
+}
+
+// GetPendingReviews 管理员的待审核队列
+func (svc *ReviewService) GetPendingReviews(ctx context.Context, page, size int) ([]domain.Review, error) {
+	return svc.repo.FindPendingReviews(ctx, (page-1)*size, size)
+}
+
+func (svc *ReviewService) ApproveReview(ctx context.Context, reviewId string) error {
+	return svc.moderate(ctx, reviewId, domain.ReviewApproved)
+}
+
+func (svc *ReviewService) RejectReview(ctx context.Context, reviewId string) error {
+	return svc.moderate(ctx, reviewId, domain.ReviewRejected)
+}
+
+// moderate 修改审核状态，并在同一个事务中调整商品的评分汇总。
+// 审核由管理员完成，商家不能隐藏自己商品的差评
+func (svc *ReviewService) moderate(ctx context.Context, reviewId string, to string) error {
+	review, err := svc.findReview(ctx, reviewId)
+	if err != nil {
+		return err
+	}
+	if review.Status == to {
+		return nil
+	}
+
+	var sumDelta, countDelta int64
+	if review.Status == domain.ReviewApproved {
+		sumDelta, countDelta = -int64(review.Rating), -1
+	}
+	if to == domain.ReviewApproved {
+		sumDelta, countDelta = int64(review.Rating), 1
+	}
+
+	return svc.uow.Do(ctx, func(ctx context.Context) error {
+		if err := svc.repo.UpdateStatus(ctx, review.Id, review.Status, to); err != nil {
+			return err
+		}
+		// 待审核直接拒绝时不影响评分
+		if sumDelta == 0 && countDelta == 0 {
+			return nil
+		}
+		return svc.productRepo.AdjustRating(ctx, review.ProductId, sumDelta, countDelta)
+	})
+}
+
+// ReplyReview 商家回复评价，重复回复会覆盖之前的内容
+func (svc *ReviewService) ReplyReview(ctx context.Context, merchantId uint64, reviewId string, reply string) error {
+	if reply == "" || utf8.RuneCountInString(reply) > maxReplyLength {
+		return ErrInvalidContent
+	}
+
+	review, err := svc.ownedReview(ctx, merchantId, reviewId)
+	if err != nil {
+		return err
+	}
+
+	return svc.repo.UpdateReply(ctx, review.Id, reply)
+}
+
+// VoteHelpful 标记评价有帮助，每个用户只能投一次
+func (svc *ReviewService) VoteHelpful(ctx context.Context, uid uint64, reviewId string) error {
+	id, err := strconv.Atoi(reviewId)
+	if err != nil {
+		return err
+	}
+
+	return svc.repo.InsertVote(ctx, uint64(id), uid)
+}
+
+func (svc *ReviewService) findReview(ctx context.Context, reviewId string) (domain.Review, error) {
+	id, err := strconv.Atoi(reviewId)
+	if err != nil {
+		return domain.Review{}, err
+	}
+
+	return svc.repo.FindReviewById(ctx, uint64(id))
+}
+
+func (svc *ReviewService) ownedReview(ctx context.Context, merchantId uint64, reviewId string) (domain.Review, error) {
+	review, err := svc.findReview(ctx, reviewId)
+	if err != nil {
+		return domain.Review{}, err
+	}
+	if review.MerchantId != merchantId {
+		return domain.Review{}, ErrReviewNotOwned
+	}
+
+	return review, nil
+}
+
+func validImages(images []string) bool {
+	if len(images) > maxReviewImages {
+		return false
+	}
+	for _, img := range images {
+		u, err := url.ParseRequestURI(img)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,5 @@
+package review
+
+import "mall/internal/review/web"
+
+type Handler = web.ReviewHandler
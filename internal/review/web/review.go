@@ -0,0 +1,235 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth"
+	"mall/internal/review/domain"
+	"mall/internal/review/service"
+	"mall/pkg/ginx"
+)
+
+type ReviewHandler struct {
+	svc *service.ReviewService
+}
+
+func NewReviewHandler(svc *service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *ReviewHandler) RegisterRoute(r *gin.Engine) {
+	productGroup := r.Group("api/products")
+	{
+		productGroup.POST("/:id/reviews", ctl.AddReview())        // 发表评价
+		productGroup.GET("/:id/reviews", ctl.GetProductReviews()) // 商品评价列表
+	}
+
+	reviewGroup := r.Group("api/reviews")
+	{
+		reviewGroup.POST("/:id/helpful", ctl.VoteHelpful()) // 有帮助
+		reviewGroup.POST("/:id/reply", ctl.ReplyReview())   // 商家回复
+	}
+
+	adminGroup := r.Group("api/admin/reviews")
+	{
+		adminGroup.GET("/pending", ctl.GetPendingReviews())  // 待审核队列
+		adminGroup.POST("/:id/approve", ctl.ApproveReview()) // 审核通过
+		adminGroup.POST("/:id/reject", ctl.RejectReview())   // 审核拒绝
+	}
+}
+
+func (ctl *ReviewHandler) AddReview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			Rating  int      `json:"rating"`
+			Content string   `json:"content"`
+			Images  []string `json:"images"`
+		}
+		var req Req
+		if err := c.Bind(&req); err != nil {
+			return
+		}
+
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		id, err := ctl.svc.AddReview(c.Request.Context(), claim.Id, c.Param("id"), domain.Review{
+			Rating:  req.Rating,
+			Content: req.Content,
+			Images:  req.Images,
+		})
+		switch {
+		case errors.Is(err, service.ErrInvalidRating):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("rating must be between 1 and 5")))
+			return
+		case errors.Is(err, service.ErrInvalidContent):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("content is too long")))
+			return
+		case errors.Is(err, service.ErrInvalidImages):
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("at most 9 http(s) image urls are allowed")))
+			return
+		case errors.Is(err, service.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+			return
+		case errors.Is(err, service.ErrReviewOwnProduct):
+			c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("cannot review your own product")))
+			return
+		case errors.Is(err, service.ErrReviewDuplicate):
+			c.JSON(http.StatusConflict, ginx.GetResponse(ginx.WithStatus(http.StatusConflict), ginx.WithMsg("you have already reviewed this product")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("review submitted, waiting for moderation"), ginx.WithData(map[string]any{
+			"id": id,
+		})))
+	}
+}
+
+func (ctl *ReviewHandler) GetProductReviews() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sort := c.DefaultQuery("sort", domain.SortLatest)
+		switch sort {
+		case domain.SortLatest, domain.SortHelpful, domain.SortRatingDesc, domain.SortRatingAsc:
+		default:
+			c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("sort must be latest, helpful, rating_desc or rating_asc")))
+			return
+		}
+
+		page, size := ginx.Pagination(c)
+		reviews, err := ctl.svc.GetProductReviews(c.Request.Context(), c.Param("id"), sort, page, size)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(reviews)))
+	}
+}
+
+func (ctl *ReviewHandler) VoteHelpful() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.svc.VoteHelpful(c.Request.Context(), claim.Id, c.Param("id"))
+		switch {
+		case errors.Is(err, service.ErrReviewNotFound):
+			c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("review not found")))
+			return
+		case errors.Is(err, service.ErrReviewAlreadyVoted):
+			c.JSON(http.StatusConflict, ginx.GetResponse(ginx.WithStatus(http.StatusConflict), ginx.WithMsg("you have already voted")))
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("vote successfully")))
+	}
+}
+
+func (ctl *ReviewHandler) ReplyReview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			Content string `json:"content"`
+		}
+		var req Req
+		if err := c.Bind(&req); err != nil {
+			return
+		}
+
+		claim, ok := auth.MerchantClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.svc.ReplyReview(c.Request.Context(), claim.Id, c.Param("id"), req.Content)
+		if !ctl.handleMerchantErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("reply successfully")))
+	}
+}
+
+func (ctl *ReviewHandler) ApproveReview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := auth.AdminClaim(c); !ok {
+			return
+		}
+
+		err := ctl.svc.ApproveReview(c.Request.Context(), c.Param("id"))
+		if !ctl.handleMerchantErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("review approved")))
+	}
+}
+
+func (ctl *ReviewHandler) RejectReview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := auth.AdminClaim(c); !ok {
+			return
+		}
+
+		err := ctl.svc.RejectReview(c.Request.Context(), c.Param("id"))
+		if !ctl.handleMerchantErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("review rejected")))
+	}
+}
+
+func (ctl *ReviewHandler) GetPendingReviews() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := auth.AdminClaim(c); !ok {
+			return
+		}
+
+		page, size := ginx.Pagination(c)
+		reviews, err := ctl.svc.GetPendingReviews(c.Request.Context(), page, size)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(reviews)))
+	}
+}
+
+// handleMerchantErr 处理商家和管理员操作评价时的错误，已写回响应时返回 false
+func (ctl *ReviewHandler) handleMerchantErr(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrReviewNotFound):
+		c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("review not found")))
+		return false
+	case errors.Is(err, service.ErrReviewNotOwned):
+		c.JSON(http.StatusForbidden, ginx.GetResponse(ginx.WithStatus(http.StatusForbidden), ginx.WithMsg("review does not belong to your product")))
+		return false
+	case errors.Is(err, service.ErrReviewStatusChanged):
+		c.JSON(http.StatusConflict, ginx.GetResponse(ginx.WithStatus(http.StatusConflict), ginx.WithMsg("review has been moderated, please refresh")))
+		return false
+	case errors.Is(err, service.ErrInvalidContent):
+		c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("reply must be 1 to 1000 characters")))
+		return false
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,31 @@
+//go:build wireinject
+
+package review
+
+import (
+	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"mall/internal/product"
+	"mall/internal/review/repository"
+	"mall/internal/review/repository/dao"
+	"mall/internal/review/service"
+	"mall/internal/review/web"
+	"mall/pkg/gormx"
+)
+
+func InitReviewHandler(db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache) *web.ReviewHandler {
+	wire.Build(
+		dao.NewReviewDao,
+
+		repository.NewReviewRepository,
+
+		product.NewProductRepository,
+		gormx.NewUnitOfWork,
+
+		service.NewReviewService,
+
+		web.NewReviewHandler,
+	)
+	return new(web.ReviewHandler)
+}
@@ -8,6 +8,7 @@ type User struct {
 	Password   string
 	Phone      string
 	IsMerchant bool
+	IsAdmin    bool
 	Birthday   time.Time
 }
 
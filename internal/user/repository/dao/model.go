@@ -9,6 +9,7 @@ type User struct {
 	Birthday   sql.NullTime
 	Password   string
 	IsMerchant bool `gorm:"default:false"` // 是否为商家
+	IsAdmin    bool `gorm:"default:false"` // 是否为管理员，只能直接在数据库中设置
 	CreateAt   int64
 	UpdateAt   int64
 }
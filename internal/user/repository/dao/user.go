@@ -235,6 +235,7 @@ func (dao *UserDao) userDaoToDomain(user User) domain.User {
 		Birthday:   user.Birthday.Time,
 		Phone:      user.Phone,
 		IsMerchant: user.IsMerchant,
+		IsAdmin:    user.IsAdmin,
 	}
 }
 
@@ -0,0 +1,12 @@
+package service
+
+import "context"
+
+// LoginHook 登录成功后的回调，由其他模块实现，如把匿名设备上的数据合并到用户名下
+// deviceId 为客户端通过 X-Device-Id 头上报的设备 id，由服务端签发，回调自行校验，可能为空
+type LoginHook interface {
+	// Name 回调结果在登录响应中的 key
+	Name() string
+	// AfterLogin 返回需要告诉客户端的结果，没有时返回 nil
+	AfterLogin(ctx context.Context, uid uint64, deviceId string) (any, error)
+}
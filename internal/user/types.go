@@ -1,5 +1,10 @@
 package user
 
-import "mall/internal/user/web"
+import (
+	"mall/internal/user/service"
+	"mall/internal/user/web"
+)
 
 type Handler = web.UserHandler // 暴露出去给 ioc 使用
+
+type LoginHook = service.LoginHook
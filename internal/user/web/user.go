@@ -21,15 +21,18 @@ type UserHandler struct {
 	codeSvc *service.CodeService
 	jwtHdl  *jwt.TokenHandler
 	ssHdl   *jwt.RedisSession
+	hooks   []service.LoginHook
 	l       logger.Logger
 }
 
-func NewUserHandler(userSvc *service.UserService, codeSvc *service.CodeService, jwtHdl *jwt.TokenHandler, ssHdl *jwt.RedisSession, l logger.Logger) *UserHandler {
+func NewUserHandler(userSvc *service.UserService, codeSvc *service.CodeService, jwtHdl *jwt.TokenHandler, ssHdl *jwt.RedisSession,
+	hooks []service.LoginHook, l logger.Logger) *UserHandler {
 	return &UserHandler{
 		userSvc: userSvc,
 		codeSvc: codeSvc,
 		jwtHdl:  jwtHdl,
 		ssHdl:   ssHdl,
+		hooks:   hooks,
 		l:       l,
 	}
 }
@@ -106,19 +109,21 @@ func (ctl *UserHandler) VerificationCode() gin.HandlerFunc {
 			return Response{}, NewBusinessError("failed to set session: %w", err)
 		}
 
-		err = ctl.jwtHdl.GenerateToken(c, user.Id, ssid, user.IsMerchant)
+		err = ctl.jwtHdl.GenerateToken(c, user.Id, ssid, user.IsMerchant, user.IsAdmin)
 		if err != nil {
 			ctl.l.Error(fmt.Sprintf("%s:生成 JWT 失败", req.Biz), logger.String("phone", req.Phone), logger.Error(err))
 			return Response{}, NewBusinessError("failed to generate JWT: %w", err)
 		}
+		afterLogin := ctl.afterLogin(c, user.Id)
 
 		maskedPhone := req.Phone[:3] + "****" + req.Phone[len(req.Phone)-4:]
 		ctl.l.Info(fmt.Sprintf("%s:用户处理成功", req.Biz), logger.String("phone", maskedPhone))
 
 		return GetResponse(WithStatus(http.StatusOK), WithMsg(fmt.Sprintf("%s successfully", req.Biz)), WithData(map[string]interface{}{
-			"id":    user.Id,
-			"phone": user.Phone,
-			"name":  user.Name,
+			"id":         user.Id,
+			"phone":      user.Phone,
+			"name":       user.Name,
+			"afterLogin": afterLogin,
 		})), nil
 	}, func(c *gin.Context, err error) (Response, bool) {
 		var busErr *BusinessError
@@ -256,14 +261,17 @@ func (ctl *UserHandler) NameLogin() gin.HandlerFunc {
 			return Response{}, NewBusinessError("failed to set session", err)
 		}
 
-		err = ctl.jwtHdl.GenerateToken(c, user.Id, ssid, user.IsMerchant)
+		err = ctl.jwtHdl.GenerateToken(c, user.Id, ssid, user.IsMerchant, user.IsAdmin)
 		if err != nil {
 			ctl.l.Error("用户名登录:生成 JWT 失败", logger.String("phone", user.Phone), logger.Error(err))
 			return Response{}, NewBusinessError("failed to generate JWT", err)
 		}
+		afterLogin := ctl.afterLogin(c, user.Id)
 
 		ctl.l.Info("用户登录成功")
-		return GetResponse(WithStatus(http.StatusOK), WithMsg("name login successfully")), nil
+		return GetResponse(WithStatus(http.StatusOK), WithMsg("name login successfully"), WithData(map[string]interface{}{
+			"afterLogin": afterLogin,
+		})), nil
 	}, func(c *gin.Context, err error) (Response, bool) {
 		// 根据错误类型记录日志
 		var busErr *BusinessError
@@ -438,3 +446,19 @@ func (ctl *UserHandler) UpdateAddress() gin.HandlerFunc {
 		return GetResponse(WithStatus(http.StatusInternalServerError), WithMsg("system erro")), false
 	})
 }
+
+// afterLogin 依次执行登录回调，返回各回调的结果，按回调名称区分；回调失败不影响登录结果
+func (ctl *UserHandler) afterLogin(c *gin.Context, uid uint64) map[string]any {
+	deviceId := c.GetHeader("X-Device-Id")
+	results := make(map[string]any)
+	for _, hook := range ctl.hooks {
+		res, err := hook.AfterLogin(c.Request.Context(), uid, deviceId)
+		if err != nil {
+			ctl.l.Error("登录回调失败", logger.String("hook", hook.Name()), logger.Field{Key: "uid", Val: uid}, logger.Error(err))
+		}
+		if res != nil {
+			results[hook.Name()] = res
+		}
+	}
+	return results
+}
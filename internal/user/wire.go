@@ -31,7 +31,7 @@ var userSet = wire.NewSet(
 	web.NewUserHandler,
 )
 
-func InitUserHandler(db *gorm.DB, cmd redis.Cmdable) *web.UserHandler {
+func InitUserHandler(db *gorm.DB, cmd redis.Cmdable, hooks []service.LoginHook) *web.UserHandler {
 	wire.Build(
 		auth.JWTSet,
 
@@ -39,3 +39,12 @@ func InitUserHandler(db *gorm.DB, cmd redis.Cmdable) *web.UserHandler {
 	)
 	return new(web.UserHandler)
 }
+
+// NewUserRepository 供订单等模块读取用户的收货地址
+func NewUserRepository(db *gorm.DB) *repository.UserRepository {
+	wire.Build(
+		dao.NewUserDao,
+		repository.NewUserRepository,
+	)
+	return new(repository.UserRepository)
+}
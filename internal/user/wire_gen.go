@@ -20,7 +20,7 @@ import (
 
 // Injectors from wire.go:
 
-func InitUserHandler(db *gorm.DB, cmd redis.Cmdable) *web.UserHandler {
+func InitUserHandler(db *gorm.DB, cmd redis.Cmdable, hooks []service.LoginHook) *web.UserHandler {
 	userDao := dao.NewUserDao(db)
 	userRepository := repository.NewUserRepository(userDao)
 	redisSession := jwt.NewRedisSession(cmd)
@@ -31,10 +31,17 @@ func InitUserHandler(db *gorm.DB, cmd redis.Cmdable) *web.UserHandler {
 	codeService := service.NewCodeService(codeRepository, smsService)
 	tokenHandler := jwt.NewJwtHandler()
 	logger := InitLogger()
-	userHandler := web.NewUserHandler(userService, codeService, tokenHandler, redisSession, logger)
+	userHandler := web.NewUserHandler(userService, codeService, tokenHandler, redisSession, hooks, logger)
 	return userHandler
 }
 
+// NewUserRepository 供订单等模块读取用户的收货地址
+func NewUserRepository(db *gorm.DB) *repository.UserRepository {
+	userDao := dao.NewUserDao(db)
+	userRepository := repository.NewUserRepository(userDao)
+	return userRepository
+}
+
 // wire.go:
 
 var userSet = wire.NewSet(dao.NewUserDao, cache.NewUserCache, cache.NewCodeCache, repository.NewUserRepository, repository.NewCodeRepository, InitSMSService, service.NewUserService, service.NewCodeService, InitLogger, web.NewUserHandler)
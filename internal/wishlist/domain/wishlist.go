@@ -0,0 +1,28 @@
+package domain
+
+type Wishlist struct {
+	Id        uint64 `json:"id"`
+	UserId    uint64 `json:"-"`
+	Name      string `json:"name"`
+	ItemCount int64  `json:"itemCount"`
+	CreateAt  int64  `json:"createAt"`
+}
+
+type WishlistItem struct {
+	Id          uint64  `json:"id"`
+	WishlistId  uint64  `json:"wishlistId"`
+	UserId      uint64  `json:"-"`
+	ProductId   uint64  `json:"productId"`
+	SavedPrice  float64 `json:"savedPrice"` // 收藏时的价格
+	LastPrice   float64 `json:"-"`          // 比价任务最近一次看到的价格
+	LastInStock bool    `json:"-"`          // 比价任务最近一次看到时是否有货
+	CreateAt    int64   `json:"createAt"`
+
+	// 以下为展示时填充的商品当前信息
+	Name         string  `json:"name"`
+	ImageUrl     string  `json:"imageUrl"`
+	Price        float64 `json:"price"`
+	InStock      bool    `json:"inStock"`
+	Available    bool    `json:"available"`    // 商品仍在上架中
+	PriceDropped bool    `json:"priceDropped"` // 当前价格低于收藏时的价格
+}
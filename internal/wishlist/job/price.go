@@ -0,0 +1,71 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"mall/internal/wishlist/service"
+	"mall/pkg/logger"
+	"mall/pkg/redisx"
+)
+
+const priceWatchLockKey = "wishlist:price:lock"
+
+// PriceWatchJob 定期比较收藏商品的价格和库存，多实例通过 Redis 锁互斥
+type PriceWatchJob struct {
+	svc      *service.WishlistService
+	cmd      redis.Cmdable
+	l        logger.Logger
+	interval time.Duration
+}
+
+func NewPriceWatchJob(svc *service.WishlistService, cmd redis.Cmdable, l logger.Logger) *PriceWatchJob {
+	return &PriceWatchJob{
+		svc:      svc,
+		cmd:      cmd,
+		l:        l,
+		interval: time.Minute * 10,
+	}
+}
+
+func (j *PriceWatchJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.run(ctx)
+		}
+	}
+}
+
+func (j *PriceWatchJob) run(ctx context.Context) {
+	lock := redisx.NewLock(j.cmd, priceWatchLockKey, j.interval)
+	ok, err := lock.TryLock(ctx)
+	if err != nil {
+		j.l.Error("收藏比价:获取锁失败", logger.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := lock.Unlock(context.Background()); err != nil {
+			j.l.Warn("收藏比价:释放锁失败", logger.Error(err))
+		}
+	}()
+
+	n, err := j.svc.ComparePrices(ctx)
+	if err != nil {
+		j.l.Error("收藏比价:执行失败", logger.Error(err))
+		return
+	}
+	if n > 0 {
+		j.l.Info("收藏比价:发送通知", logger.Field{Key: "count", Val: n})
+	}
+}
@@ -0,0 +1,22 @@
+package dao
+
+// Wishlist 用户的收藏夹，同一用户下名称唯一
+type Wishlist struct {
+	Id       uint64 `gorm:"primaryKey,autoIncrement"`
+	UserId   uint64 `gorm:"not null;uniqueIndex:uk_user_name,priority:1"`
+	Name     string `gorm:"type:varchar(64);not null;uniqueIndex:uk_user_name,priority:2"`
+	CreateAt int64
+	UpdateAt int64
+}
+
+// WishlistItem 收藏夹中的商品
+type WishlistItem struct {
+	Id          uint64  `gorm:"primaryKey,autoIncrement"`
+	WishlistId  uint64  `gorm:"not null;uniqueIndex:uk_list_product,priority:1"`
+	UserId      uint64  `gorm:"not null"`
+	ProductId   uint64  `gorm:"not null;uniqueIndex:uk_list_product,priority:2;index"`
+	SavedPrice  float64 `gorm:"not null"`               // 收藏时的价格
+	LastPrice   float64 `gorm:"not null"`               // 比价任务最近一次看到的价格
+	LastInStock bool    `gorm:"not null;default:false"` // 比价任务最近一次看到时是否有货
+	CreateAt    int64
+}
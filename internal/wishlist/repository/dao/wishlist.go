@@ -0,0 +1,224 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"mall/internal/wishlist/domain"
+	"mall/pkg/gormx"
+)
+
+var (
+	ErrWishlistNotFound      = errors.New("wishlist not found")
+	ErrWishlistDuplicateName = errors.New("duplicate wishlist name")
+	ErrItemExists            = errors.New("product is already in the wishlist")
+	ErrItemNotFound          = errors.New("product is not in the wishlist")
+)
+
+type WishlistDao struct {
+	db  *gorm.DB
+	uow *gormx.UnitOfWork
+}
+
+func NewWishlistDao(db *gorm.DB) *WishlistDao {
+	return &WishlistDao{
+		db:  db,
+		uow: gormx.NewUnitOfWork(db),
+	}
+}
+
+func (dao *WishlistDao) InsertList(ctx context.Context, uid uint64, name string) (uint64, error) {
+	now := time.Now().UnixMilli()
+	list := Wishlist{
+		UserId:   uid,
+		Name:     name,
+		CreateAt: now,
+		UpdateAt: now,
+	}
+	err := dao.db.WithContext(ctx).Create(&list).Error
+	if gormx.IsUniqueConflict(err) {
+		return 0, ErrWishlistDuplicateName
+	}
+
+	return list.Id, err
+}
+
+func (dao *WishlistDao) CountLists(ctx context.Context, uid uint64) (int64, error) {
+	var count int64
+	err := dao.db.WithContext(ctx).Model(&Wishlist{}).Where("user_id = ?", uid).Count(&count).Error
+	return count, err
+}
+
+// FindLists 用户的所有收藏夹及其中的商品数量
+func (dao *WishlistDao) FindLists(ctx context.Context, uid uint64) ([]domain.Wishlist, error) {
+	type row struct {
+		Wishlist
+		ItemCount int64
+	}
+	var rows []row
+	err := dao.db.WithContext(ctx).Model(&Wishlist{}).
+		Select("wishlist.*, (SELECT COUNT(*) FROM wishlist_item wi WHERE wi.wishlist_id = wishlist.id) AS item_count").
+		Where("user_id = ?", uid).
+		Order("id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Wishlist, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, domain.Wishlist{
+			Id:        r.Id,
+			UserId:    r.UserId,
+			Name:      r.Name,
+			ItemCount: r.ItemCount,
+			CreateAt:  r.CreateAt,
+		})
+	}
+	return res, nil
+}
+
+// CheckList 确认收藏夹存在且属于该用户
+func (dao *WishlistDao) CheckList(ctx context.Context, uid, id uint64) error {
+	var count int64
+	err := dao.db.WithContext(ctx).Model(&Wishlist{}).Where("id = ? AND user_id = ?", id, uid).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrWishlistNotFound
+	}
+
+	return nil
+}
+
+func (dao *WishlistDao) RenameList(ctx context.Context, uid, id uint64, name string) error {
+	res := dao.db.WithContext(ctx).Model(&Wishlist{}).
+		Where("id = ? AND user_id = ?", id, uid).
+		Updates(map[string]any{
+			"name":      name,
+			"update_at": time.Now().UnixMilli(),
+		})
+	if gormx.IsUniqueConflict(res.Error) {
+		return ErrWishlistDuplicateName
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrWishlistNotFound
+	}
+
+	return nil
+}
+
+// DeleteList 删除收藏夹及其中的商品
+func (dao *WishlistDao) DeleteList(ctx context.Context, uid, id uint64) error {
+	return dao.uow.Do(ctx, func(ctx context.Context) error {
+		db := gormx.DB(ctx, dao.db)
+
+		res := db.Where("id = ? AND user_id = ?", id, uid).Delete(&Wishlist{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrWishlistNotFound
+		}
+
+		return db.Where("wishlist_id = ?", id).Delete(&WishlistItem{}).Error
+	})
+}
+
+func (dao *WishlistDao) InsertItem(ctx context.Context, item domain.WishlistItem) error {
+	err := dao.db.WithContext(ctx).Create(&WishlistItem{
+		WishlistId:  item.WishlistId,
+		UserId:      item.UserId,
+		ProductId:   item.ProductId,
+		SavedPrice:  item.SavedPrice,
+		LastPrice:   item.LastPrice,
+		LastInStock: item.LastInStock,
+		CreateAt:    time.Now().UnixMilli(),
+	}).Error
+	if gormx.IsUniqueConflict(err) {
+		return ErrItemExists
+	}
+
+	return err
+}
+
+func (dao *WishlistDao) CountItems(ctx context.Context, listId uint64) (int64, error) {
+	var count int64
+	err := dao.db.WithContext(ctx).Model(&WishlistItem{}).Where("wishlist_id = ?", listId).Count(&count).Error
+	return count, err
+}
+
+func (dao *WishlistDao) DeleteItem(ctx context.Context, uid, listId, productId uint64) error {
+	res := dao.db.WithContext(ctx).
+		Where("wishlist_id = ? AND user_id = ? AND product_id = ?", listId, uid, productId).
+		Delete(&WishlistItem{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrItemNotFound
+	}
+
+	return nil
+}
+
+func (dao *WishlistDao) FindItems(ctx context.Context, uid, listId uint64, offset, limit int) ([]domain.WishlistItem, error) {
+	var items []WishlistItem
+	err := dao.db.WithContext(ctx).
+		Where("wishlist_id = ? AND user_id = ?", listId, uid).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.toDomains(items), nil
+}
+
+// FindItemsAfter 按 id 顺序分批读取所有收藏项，供比价任务使用
+func (dao *WishlistDao) FindItemsAfter(ctx context.Context, lastId uint64, limit int) ([]domain.WishlistItem, error) {
+	var items []WishlistItem
+	err := dao.db.WithContext(ctx).Where("id > ?", lastId).Order("id").Limit(limit).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.toDomains(items), nil
+}
+
+// UpdateLastSeen 记录比价任务看到的价格和库存状态
+func (dao *WishlistDao) UpdateLastSeen(ctx context.Context, ids []uint64, price float64, inStock bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return dao.db.WithContext(ctx).Model(&WishlistItem{}).Where("id IN ?", ids).Updates(map[string]any{
+		"last_price":    price,
+		"last_in_stock": inStock,
+	}).Error
+}
+
+func (dao *WishlistDao) toDomains(items []WishlistItem) []domain.WishlistItem {
+	res := make([]domain.WishlistItem, 0, len(items))
+	for _, it := range items {
+		res = append(res, domain.WishlistItem{
+			Id:          it.Id,
+			WishlistId:  it.WishlistId,
+			UserId:      it.UserId,
+			ProductId:   it.ProductId,
+			SavedPrice:  it.SavedPrice,
+			LastPrice:   it.LastPrice,
+			LastInStock: it.LastInStock,
+			CreateAt:    it.CreateAt,
+		})
+	}
+	return res
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"mall/internal/wishlist/domain"
+	"mall/internal/wishlist/repository/dao"
+)
+
+var (
+	ErrWishlistNotFound      = dao.ErrWishlistNotFound
+	ErrWishlistDuplicateName = dao.ErrWishlistDuplicateName
+	ErrItemExists            = dao.ErrItemExists
+	ErrItemNotFound          = dao.ErrItemNotFound
+)
+
+type WishlistRepository struct {
+	dao *dao.WishlistDao
+}
+
+func NewWishlistRepository(dao *dao.WishlistDao) *WishlistRepository {
+	return &WishlistRepository{
+		dao: dao,
+	}
+}
+
+func (repo *WishlistRepository) InsertList(ctx context.Context, uid uint64, name string) (uint64, error) {
+	return repo.dao.InsertList(ctx, uid, name)
+}
+
+func (repo *WishlistRepository) CountLists(ctx context.Context, uid uint64) (int64, error) {
+	return repo.dao.CountLists(ctx, uid)
+}
+
+func (repo *WishlistRepository) FindLists(ctx context.Context, uid uint64) ([]domain.Wishlist, error) {
+	return repo.dao.FindLists(ctx, uid)
+}
+
+func (repo *WishlistRepository) CheckList(ctx context.Context, uid, id uint64) error {
+	return repo.dao.CheckList(ctx, uid, id)
+}
+
+func (repo *WishlistRepository) RenameList(ctx context.Context, uid, id uint64, name string) error {
+	return repo.dao.RenameList(ctx, uid, id, name)
+}
+
+func (repo *WishlistRepository) DeleteList(ctx context.Context, uid, id uint64) error {
+	return repo.dao.DeleteList(ctx, uid, id)
+}
+
+func (repo *WishlistRepository) InsertItem(ctx context.Context, item domain.WishlistItem) error {
+	return repo.dao.InsertItem(ctx, item)
+}
+
+func (repo *WishlistRepository) CountItems(ctx context.Context, listId uint64) (int64, error) {
+	return repo.dao.CountItems(ctx, listId)
+}
+
+func (repo *WishlistRepository) DeleteItem(ctx context.Context, uid, listId, productId uint64) error {
+	return repo.dao.DeleteItem(ctx, uid, listId, productId)
+}
+
+func (repo *WishlistRepository) FindItems(ctx context.Context, uid, listId uint64, offset, limit int) ([]domain.WishlistItem, error) {
+	return repo.dao.FindItems(ctx, uid, listId, offset, limit)
+}
+
+func (repo *WishlistRepository) FindItemsAfter(ctx context.Context, lastId uint64, limit int) ([]domain.WishlistItem, error) {
+	return repo.dao.FindItemsAfter(ctx, lastId, limit)
+}
+
+func (repo *WishlistRepository) UpdateLastSeen(ctx context.Context, ids []uint64, price float64, inStock bool) error {
+	return repo.dao.UpdateLastSeen(ctx, ids, price, inStock)
+}
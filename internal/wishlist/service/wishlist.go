@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	ndomain "mall/internal/notification/domain"
+	nservice "mall/internal/notification/service"
+	pdomain "mall/internal/product/domain"
+	prepo "mall/internal/product/repository"
+	"mall/internal/wishlist/domain"
+	"mall/internal/wishlist/repository"
+)
+
+var (
+	ErrWishlistNotFound      = repository.ErrWishlistNotFound
+	ErrWishlistDuplicateName = repository.ErrWishlistDuplicateName
+	ErrItemExists            = repository.ErrItemExists
+	ErrItemNotFound          = repository.ErrItemNotFound
+	ErrProductNotFound       = prepo.ErrProductNotFound
+	ErrProductNotOnList      = prepo.ErrProductNotOnList
+	ErrInvalidName           = errors.New("wishlist name must be 1 to 64 characters")
+	ErrTooManyWishlists      = errors.New("too many wishlists")
+	ErrWishlistFull          = errors.New("wishlist is full")
+)
+
+const (
+	maxWishlists    = 20
+	maxWishlistSize = 200
+	// 比价任务每批处理的收藏项数量
+	compareBatchSize = 500
+)
+
+type WishlistService struct {
+	repo            *repository.WishlistRepository
+	productRepo     *prepo.ProductRepository
+	notificationSvc *nservice.NotificationService
+}
+
+func NewWishlistService(repo *repository.WishlistRepository, productRepo *prepo.ProductRepository, notificationSvc *nservice.NotificationService) *WishlistService {
+	return &WishlistService{
+		repo:            repo,
+		productRepo:     productRepo,
+		notificationSvc: notificationSvc,
+	}
+}
+
+func (svc *WishlistService) CreateList(ctx context.Context, uid uint64, name string) (uint64, error) {
+	if !validName(name) {
+		return 0, ErrInvalidName
+	}
+	count, err := svc.repo.CountLists(ctx, uid)
+	if err != nil {
+		return 0, err
+	}
+	if count >= maxWishlists {
+		return 0, ErrTooManyWishlists
+	}
+
+	return svc.repo.InsertList(ctx, uid, name)
+}
+
+func (svc *WishlistService) GetLists(ctx context.Context, uid uint64) ([]domain.Wishlist, error) {
+	return svc.repo.FindLists(ctx, uid)
+}
+
+func (svc *WishlistService) RenameList(ctx context.Context, uid uint64, listId string, name string) error {
+	id, err := strconv.Atoi(listId)
+	if err != nil {
+		return err
+	}
+	if !validName(name) {
+		return ErrInvalidName
+	}
+
+	return svc.repo.RenameList(ctx, uid, uint64(id), name)
+}
+
+func (svc *WishlistService) DeleteList(ctx context.Context, uid uint64, listId string) error {
+	id, err := strconv.Atoi(listId)
+	if err != nil {
+		return err
+	}
+
+	return svc.repo.DeleteList(ctx, uid, uint64(id))
+}
+
+// AddItem 收藏上架中的商品，记录收藏时的价格
+func (svc *WishlistService) AddItem(ctx context.Context, uid uint64, listId string, productId uint64) error {
+	id, err := strconv.Atoi(listId)
+	if err != nil {
+		return err
+	}
+	if err := svc.repo.CheckList(ctx, uid, uint64(id)); err != nil {
+		return err
+	}
+	count, err := svc.repo.CountItems(ctx, uint64(id))
+	if err != nil {
+		return err
+	}
+	if count >= maxWishlistSize {
+		return ErrWishlistFull
+	}
+
+	detail, err := svc.productRepo.FindProductById(ctx, productId)
+	if err != nil {
+		return err
+	}
+
+	return svc.repo.InsertItem(ctx, domain.WishlistItem{
+		WishlistId:  uint64(id),
+		UserId:      uid,
+		ProductId:   productId,
+		SavedPrice:  detail.Product.Price,
+		LastPrice:   detail.Product.Price,
+		LastInStock: detail.Product.Stock > 0,
+	})
+}
+
+func (svc *WishlistService) RemoveItem(ctx context.Context, uid uint64, listId string, productId string) error {
+	lid, err := strconv.Atoi(listId)
+	if err != nil {
+		return err
+	}
+	pid, err := strconv.Atoi(productId)
+	if err != nil {
+		return err
+	}
+
+	return svc.repo.DeleteItem(ctx, uid, uint64(lid), uint64(pid))
+}
+
+// GetItems 收藏夹中的商品，附带商品当前的价格和库存
+func (svc *WishlistService) GetItems(ctx context.Context, uid uint64, listId string, page, size int) ([]domain.WishlistItem, error) {
+	id, err := strconv.Atoi(listId)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.repo.CheckList(ctx, uid, uint64(id)); err != nil {
+		return nil, err
+	}
+
+	items, err := svc.repo.FindItems(ctx, uid, uint64(id), (page-1)*size, size)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := svc.productSummaries(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		s, ok := summaries[items[i].ProductId]
+		if !ok {
+			continue
+		}
+		items[i].Name = s.Name
+		items[i].ImageUrl = s.ImageUrl
+		items[i].Price = s.Price
+		items[i].Available = s.IsActive && !s.IsDeleted
+		items[i].InStock = items[i].Available && s.Stock > 0
+		items[i].PriceDropped = s.Price < items[i].SavedPrice
+	}
+
+	return items, nil
+}
+
+// ComparePrices 对比所有收藏项与商品当前的价格和库存，降价或重新有货时发送站内通知
+// 与上次看到的状态比较，同一次变化只通知一次，返回发送的通知数量
+func (svc *WishlistService) ComparePrices(ctx context.Context) (int, error) {
+	var (
+		lastId uint64
+		total  int
+	)
+	for {
+		items, err := svc.repo.FindItemsAfter(ctx, lastId, compareBatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(items) == 0 {
+			return total, nil
+		}
+
+		n, err := svc.compareBatch(ctx, items)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if len(items) < compareBatchSize {
+			return total, nil
+		}
+		lastId = items[len(items)-1].Id
+	}
+}
+
+func (svc *WishlistService) compareBatch(ctx context.Context, items []domain.WishlistItem) (int, error) {
+	summaries, err := svc.productSummaries(ctx, items)
+	if err != nil {
+		return 0, err
+	}
+
+	type notifyKey struct {
+		userId    uint64
+		productId uint64
+		typ       string
+	}
+	type seenKey struct {
+		productId uint64
+		price     float64
+	}
+	var (
+		notifications []ndomain.Notification
+		notified      = make(map[notifyKey]struct{})
+		changed       = make(map[seenKey][]uint64) // 商品和要记录的价格 -> 需要更新的收藏项
+	)
+	notify := func(item domain.WishlistItem, typ, title, content string) {
+		key := notifyKey{userId: item.UserId, productId: item.ProductId, typ: typ}
+		// 同一商品在用户的多个收藏夹中时只通知一次
+		if _, ok := notified[key]; ok {
+			return
+		}
+		notified[key] = struct{}{}
+		notifications = append(notifications, ndomain.Notification{
+			UserId:    item.UserId,
+			Type:      typ,
+			Title:     title,
+			Content:   content,
+			ProductId: item.ProductId,
+		})
+	}
+
+	for _, item := range items {
+		s, ok := summaries[item.ProductId]
+		if !ok || s.IsDeleted {
+			continue
+		}
+		inStock := s.IsActive && s.Stock > 0
+
+		if s.IsActive && s.Price < item.LastPrice {
+			notify(item, ndomain.TypePriceDrop, "收藏的商品降价了",
+				fmt.Sprintf("%s 从 %.2f 降到了 %.2f", s.Name, item.LastPrice, s.Price))
+		}
+		if inStock && !item.LastInStock {
+			notify(item, ndomain.TypeBackInStock, "收藏的商品有货了",
+				fmt.Sprintf("%s 已经重新有货", s.Name))
+		}
+		// 下架期间不记录价格，重新上架后再和下架前的价格比较，避免下架改价再上架时漏掉降价通知
+		price := item.LastPrice
+		if s.IsActive {
+			price = s.Price
+		}
+		if price != item.LastPrice || inStock != item.LastInStock {
+			key := seenKey{productId: item.ProductId, price: price}
+			changed[key] = append(changed[key], item.Id)
+		}
+	}
+
+	// 先发通知再更新状态，中途失败时下次会重试，宁可重复也不遗漏
+	if err := svc.notificationSvc.Send(ctx, notifications...); err != nil {
+		return 0, err
+	}
+	for key, ids := range changed {
+		s := summaries[key.productId]
+		if err := svc.repo.UpdateLastSeen(ctx, ids, key.price, s.IsActive && s.Stock > 0); err != nil {
+			return len(notifications), err
+		}
+	}
+
+	return len(notifications), nil
+}
+
+func (svc *WishlistService) productSummaries(ctx context.Context, items []domain.WishlistItem) (map[uint64]pdomain.ProductSummary, error) {
+	ids := make([]uint64, 0, len(items))
+	seen := make(map[uint64]struct{}, len(items))
+	for _, item := range items {
+		if _, ok := seen[item.ProductId]; ok {
+			continue
+		}
+		seen[item.ProductId] = struct{}{}
+		ids = append(ids, item.ProductId)
+	}
+
+	summaries, err := svc.productRepo.FindProductSummaries(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint64]pdomain.ProductSummary, len(summaries))
+	for _, s := range summaries {
+		res[s.Id] = s
+	}
+	return res, nil
+}
+
+func validName(name string) bool {
+	n := utf8.RuneCountInString(name)
+	return n > 0 && n <= 64
+}
@@ -0,0 +1,10 @@
+package wishlist
+
+import (
+	"mall/internal/wishlist/job"
+	"mall/internal/wishlist/web"
+)
+
+type Handler = web.WishlistHandler
+
+type PriceWatchJob = job.PriceWatchJob
@@ -0,0 +1,200 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/auth"
+	"mall/internal/wishlist/service"
+	"mall/pkg/ginx"
+)
+
+type WishlistHandler struct {
+	svc *service.WishlistService
+}
+
+func NewWishlistHandler(svc *service.WishlistService) *WishlistHandler {
+	return &WishlistHandler{
+		svc: svc,
+	}
+}
+
+func (ctl *WishlistHandler) RegisterRoute(r *gin.Engine) {
+	group := r.Group("api/wishlists")
+	{
+		group.GET("/", ctl.GetLists())                          // 收藏夹列表
+		group.POST("/", ctl.CreateList())                       // 新建收藏夹
+		group.PUT("/:id", ctl.RenameList())                     // 重命名
+		group.DELETE("/:id", ctl.DeleteList())                  // 删除收藏夹
+		group.GET("/:id/items", ctl.GetItems())                 // 收藏夹中的商品
+		group.POST("/:id/items", ctl.AddItem())                 // 收藏商品
+		group.DELETE("/:id/items/:productId", ctl.RemoveItem()) // 取消收藏
+	}
+}
+
+func (ctl *WishlistHandler) GetLists() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		lists, err := ctl.svc.GetLists(c.Request.Context(), claim.Id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(lists)))
+	}
+}
+
+func (ctl *WishlistHandler) CreateList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			Name string `json:"name"`
+		}
+		var req Req
+		if err := c.Bind(&req); err != nil {
+			return
+		}
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		id, err := ctl.svc.CreateList(c.Request.Context(), claim.Id, req.Name)
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("create wishlist successfully"), ginx.WithData(map[string]any{
+			"id": id,
+		})))
+	}
+}
+
+func (ctl *WishlistHandler) RenameList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			Name string `json:"name"`
+		}
+		var req Req
+		if err := c.Bind(&req); err != nil {
+			return
+		}
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.svc.RenameList(c.Request.Context(), claim.Id, c.Param("id"), req.Name)
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("rename wishlist successfully")))
+	}
+}
+
+func (ctl *WishlistHandler) DeleteList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.svc.DeleteList(c.Request.Context(), claim.Id, c.Param("id"))
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("delete wishlist successfully")))
+	}
+}
+
+func (ctl *WishlistHandler) GetItems() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		page, size := ginx.Pagination(c)
+		items, err := ctl.svc.GetItems(c.Request.Context(), claim.Id, c.Param("id"), page, size)
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithData(items)))
+	}
+}
+
+func (ctl *WishlistHandler) AddItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		type Req struct {
+			ProductId uint64 `json:"productId"`
+		}
+		var req Req
+		if err := c.Bind(&req); err != nil {
+			return
+		}
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.svc.AddItem(c.Request.Context(), claim.Id, c.Param("id"), req.ProductId)
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("add to wishlist successfully")))
+	}
+}
+
+func (ctl *WishlistHandler) RemoveItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claim, ok := auth.UserClaim(c)
+		if !ok {
+			return
+		}
+
+		err := ctl.svc.RemoveItem(c.Request.Context(), claim.Id, c.Param("id"), c.Param("productId"))
+		if !writeErr(c, err) {
+			return
+		}
+
+		c.JSON(http.StatusOK, ginx.GetResponse(ginx.WithStatus(http.StatusOK), ginx.WithMsg("remove from wishlist successfully")))
+	}
+}
+
+// writeErr 把错误写回响应，没有错误时返回 true
+func writeErr(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrInvalidName):
+		c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("name must be 1 to 64 characters")))
+	case errors.Is(err, service.ErrTooManyWishlists):
+		c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("too many wishlists")))
+	case errors.Is(err, service.ErrWishlistFull):
+		c.JSON(http.StatusBadRequest, ginx.GetResponse(ginx.WithStatus(http.StatusBadRequest), ginx.WithMsg("wishlist is full")))
+	case errors.Is(err, service.ErrWishlistDuplicateName):
+		c.JSON(http.StatusConflict, ginx.GetResponse(ginx.WithStatus(http.StatusConflict), ginx.WithMsg("duplicate wishlist name")))
+	case errors.Is(err, service.ErrItemExists):
+		c.JSON(http.StatusConflict, ginx.GetResponse(ginx.WithStatus(http.StatusConflict), ginx.WithMsg("product is already in the wishlist")))
+	case errors.Is(err, service.ErrWishlistNotFound):
+		c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("wishlist not found")))
+	case errors.Is(err, service.ErrItemNotFound):
+		c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product is not in the wishlist")))
+	case errors.Is(err, service.ErrProductNotFound), errors.Is(err, service.ErrProductNotOnList):
+		c.JSON(http.StatusNotFound, ginx.GetResponse(ginx.WithStatus(http.StatusNotFound), ginx.WithMsg("product not found")))
+	default:
+		c.JSON(http.StatusInternalServerError, ginx.GetResponse(ginx.WithStatus(http.StatusInternalServerError), ginx.WithMsg("system error")))
+	}
+
+	return false
+}
@@ -0,0 +1,44 @@
+//go:build wireinject
+
+package wishlist
+
+import (
+	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"mall/internal/notification"
+	"mall/internal/product"
+	"mall/internal/wishlist/job"
+	"mall/internal/wishlist/repository"
+	"mall/internal/wishlist/repository/dao"
+	"mall/internal/wishlist/service"
+	"mall/internal/wishlist/web"
+	"mall/pkg/logger"
+)
+
+var wishlistSet = wire.NewSet(
+	dao.NewWishlistDao,
+
+	repository.NewWishlistRepository,
+
+	product.NewProductRepository,
+	notification.NewNotificationService,
+
+	service.NewWishlistService,
+)
+
+func InitWishlistHandler(db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache) *web.WishlistHandler {
+	wire.Build(
+		wishlistSet,
+		web.NewWishlistHandler,
+	)
+	return new(web.WishlistHandler)
+}
+
+func InitPriceWatchJob(db *gorm.DB, cmd redis.Cmdable, pc *product.ProductCache, l logger.Logger) *job.PriceWatchJob {
+	wire.Build(
+		wishlistSet,
+		job.NewPriceWatchJob,
+	)
+	return new(job.PriceWatchJob)
+}
@@ -0,0 +1,43 @@
+package ioc
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"mall/internal/cart"
+	"mall/internal/product"
+	"mall/internal/ranking"
+	"mall/internal/recommend"
+	"mall/internal/wishlist"
+	"mall/pkg/delay"
+	"mall/pkg/taskx"
+)
+
+// Job 随服务启动的后台任务，ctx 取消时退出
+type Job interface {
+	Start(ctx context.Context)
+}
+
+type App struct {
+	Server *gin.Engine
+	Jobs   []Job
+	// Delay 延时任务调度器，退出时需要等待执行中的任务
+	Delay *delay.Scheduler
+	// Tasks 请求中启动的后台协程，如批量导入，退出时需要等待
+	Tasks *taskx.Group
+}
+
+func InitJobs(purgeJob *product.PurgeJob, scheduleJob *product.ScheduleJob, priceWatchJob *wishlist.PriceWatchJob,
+	rebuildJob *recommend.RebuildJob, rankingJob *ranking.RankingJob, flushJob *cart.FlushJob,
+	flashStockJob *product.FlashStockJob) []Job {
+	return []Job{
+		purgeJob,
+		scheduleJob,
+		priceWatchJob,
+		rebuildJob,
+		rankingJob,
+		flushJob,
+		flashStockJob,
+	}
+}
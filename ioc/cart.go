@@ -0,0 +1,29 @@
+package ioc
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+
+	"mall/internal/cart"
+)
+
+type cartConfig struct {
+	Storage string `yaml:"storage"` // mysql 或 redis
+}
+
+// InitCartRepository 按配置选择只用 MySQL 或以 Redis 为准、异步写回 MySQL 的购物车
+func InitCartRepository(db *gorm.DB, cmd redis.Cmdable) cart.Repository {
+	var cfg cartConfig
+	err := viper.UnmarshalKey("cart", &cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	switch cfg.Storage {
+	case "redis":
+		return cart.NewRedisCartRepository(db, cmd)
+	default:
+		return cart.NewDBCartRepository(db)
+	}
+}
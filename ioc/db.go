@@ -10,7 +10,17 @@ import (
 	glogger "gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 
+	cdao "mall/internal/cart/repository/dao"
+	ndao "mall/internal/notification/repository/dao"
+	odao "mall/internal/order/repository/dao"
+	pdao "mall/internal/product/repository/dao"
+	qdao "mall/internal/qa/repository/dao"
+	rankdao "mall/internal/ranking/repository/dao"
+	recdao "mall/internal/recommend/repository/dao"
+	rdao "mall/internal/review/repository/dao"
 	"mall/internal/user/repository/dao"
+	wdao "mall/internal/wishlist/repository/dao"
+	"mall/pkg/delay"
 )
 
 func InitDB(l logger.Logger) *gorm.DB {
@@ -63,7 +73,20 @@ func InitDB(l logger.Logger) *gorm.DB {
 }
 
 func Migrate(db *gorm.DB) {
-	err := db.AutoMigrate(&dao.User{})
+	err := db.AutoMigrate(&dao.User{},
+		&pdao.Product{}, &pdao.ProductImage{}, &pdao.ProductAttribute{},
+		&pdao.Category{}, &pdao.ProductCategory{}, &pdao.ProductHistory{}, &pdao.IdempotencyKey{}, &pdao.StockLog{},
+		&pdao.AttributeTemplate{},
+		&rdao.Review{}, &rdao.ReviewVote{},
+		&qdao.Question{}, &qdao.Answer{}, &qdao.QaVote{},
+		&ndao.Notification{},
+		&wdao.Wishlist{}, &wdao.WishlistItem{},
+		&cdao.Cart{}, &cdao.SavedItem{},
+		&odao.Order{}, &odao.OrderItem{},
+		&recdao.Recommendation{}, &recdao.CategoryPopular{},
+		&rankdao.HotRanking{},
+		&delay.DelayTask{},
+	)
 	if err != nil {
 		panic(err)
 	}
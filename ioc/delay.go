@@ -0,0 +1,32 @@
+package ioc
+
+import (
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+
+	"mall/pkg/delay"
+	"mall/pkg/logger"
+)
+
+type delayConfig struct {
+	Store string `yaml:"store"` // redis 或 mysql
+}
+
+// InitDelayScheduler 按配置选择延时任务存放在 Redis 还是 MySQL，默认 Redis
+func InitDelayScheduler(db *gorm.DB, cmd redis.Cmdable, l logger.Logger) *delay.Scheduler {
+	var cfg delayConfig
+	err := viper.UnmarshalKey("delay", &cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	var store delay.Store
+	switch cfg.Store {
+	case "mysql":
+		store = delay.NewMySQLStore(db)
+	default:
+		store = delay.NewRedisStore(cmd, "delay")
+	}
+	return delay.NewScheduler(store, l)
+}
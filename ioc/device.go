@@ -0,0 +1,25 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+
+	"mall/internal/product"
+)
+
+type deviceConfig struct {
+	Key string `yaml:"key"` // 匿名设备 id 的签名密钥
+}
+
+// InitDeviceIds 匿名访客的设备 id 由服务端签发，密钥来自配置
+func InitDeviceIds() *product.DeviceIds {
+	var cfg deviceConfig
+	err := viper.UnmarshalKey("device", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	if cfg.Key == "" {
+		panic("device.key is required")
+	}
+
+	return product.NewDeviceIds([]byte(cfg.Key))
+}
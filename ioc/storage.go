@@ -0,0 +1,46 @@
+package ioc
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"mall/pkg/storage"
+	"mall/pkg/storage/local"
+	"mall/pkg/storage/s3"
+)
+
+type storageConfig struct {
+	Type  string `yaml:"type"` // local 或 s3
+	Local struct {
+		Dir     string `yaml:"dir"`
+		BaseURL string `yaml:"baseURL"`
+	} `yaml:"local"`
+	S3 s3.Config `yaml:"s3"`
+}
+
+func InitBlob() storage.Blob {
+	var cfg storageConfig
+	err := viper.UnmarshalKey("storage", &cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	switch cfg.Type {
+	case "s3":
+		return s3.NewBlob(cfg.S3)
+	default:
+		return local.NewBlob(cfg.Local.Dir, cfg.Local.BaseURL)
+	}
+}
+
+// registerStatic 使用本地存储时，由服务自身提供 /static 下的文件
+func registerStatic(server *gin.Engine) {
+	var cfg storageConfig
+	if err := viper.UnmarshalKey("storage", &cfg); err != nil {
+		panic(err)
+	}
+
+	if cfg.Type != "s3" && cfg.Local.Dir != "" {
+		server.Static("/static", cfg.Local.Dir)
+	}
+}
@@ -5,27 +5,79 @@ import (
 	"github.com/gin-gonic/gin"
 	"mall/internal/auth"
 	"mall/internal/auth/jwt"
+	"mall/internal/cart"
+	"mall/internal/notification"
+	"mall/internal/order"
 	"mall/internal/product"
+	"mall/internal/qa"
+	"mall/internal/ranking"
+	"mall/internal/recommend"
+	"mall/internal/review"
 	"mall/internal/user"
+	"mall/internal/wishlist"
 	logger2 "mall/pkg/logger"
 	"mall/pkg/middleware/logger"
+	"net/http"
 )
 
-func InitWeb(mdl []gin.HandlerFunc, userHdl *user.Handler, productHdl *product.Handler) *gin.Engine {
+func InitWeb(mdl []gin.HandlerFunc, userHdl *user.Handler, productHdl *product.Handler, reviewHdl *review.Handler, qaHdl *qa.Handler,
+	notificationHdl *notification.Handler, wishlistHdl *wishlist.Handler, recommendHdl *recommend.Handler,
+	rankingHdl *ranking.Handler, cartHdl *cart.Handler, orderHdl *order.Handler) *gin.Engine {
 	server := gin.Default()
 	server.Use(mdl...)
+	registerStatic(server)
 	userHdl.RegisterRoute(server)
 	productHdl.RegisterRoute(server)
+	reviewHdl.RegisterRoute(server)
+	qaHdl.RegisterRoute(server)
+	notificationHdl.RegisterRoute(server)
+	wishlistHdl.RegisterRoute(server)
+	recommendHdl.RegisterRoute(server)
+	rankingHdl.RegisterRoute(server)
+	cartHdl.RegisterRoute(server)
+	orderHdl.RegisterRoute(server)
 
 	return server
 }
 
+// InitLoginHooks 登录成功后需要执行的各模块回调
+func InitLoginHooks(historySvc *product.ViewHistoryService, guestCartSvc *cart.GuestCartService) []user.LoginHook {
+	return []user.LoginHook{
+		historySvc,
+		guestCartSvc,
+	}
+}
+
+// InitProductEventHooks 订阅商品浏览、加购、下单、彻底删除事件的各模块
+func InitProductEventHooks(rankingSvc *ranking.RankingService, cartPurgeHook *cart.PurgeHook) []product.EventHook {
+	return []product.EventHook{
+		rankingSvc,
+		cartPurgeHook,
+	}
+}
+
 func InitMiddleware(jwtHdl *jwt.TokenHandler, sessionHdl *jwt.RedisSession, l logger2.Logger) []gin.HandlerFunc {
 	return []gin.HandlerFunc{
 		auth.NewTokenEffectiveBuilder(jwtHdl, sessionHdl).
 			IgnorePath("/api/user/send-code").
 			IgnorePath("/api/user/verify-code").
 			IgnorePath("/api/user/login").
+			IgnorePath("/api/devices").
+			IgnorePrefix("/static/").
+			Optional(http.MethodGet, "/api/products/:id").
+			Optional(http.MethodGet, "/api/products/viewed").
+			Optional(http.MethodDelete, "/api/products/viewed").
+			Optional(http.MethodGet, "/api/products/:id/recommendations").
+			Optional(http.MethodGet, "/api/rankings/hot").
+			Optional(http.MethodGet, "/api/rankings/hot/categories/:id").
+			Optional(http.MethodGet, "/api/cart/").
+			Optional(http.MethodDelete, "/api/cart/").
+			Optional(http.MethodPost, "/api/cart/items").
+			Optional(http.MethodPut, "/api/cart/items/:productId").
+			Optional(http.MethodDelete, "/api/cart/items/:productId").
+			Optional(http.MethodPost, "/api/cart/items/remove").
+			Optional(http.MethodPut, "/api/cart/selection").
+			Optional(http.MethodPut, "/api/cart/items/:productId/note").
 			Check(),
 
 		logger.NewMiddlewareBuilder(func(ctx context.Context, al *logger.AccessLog) {
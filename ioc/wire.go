@@ -3,16 +3,24 @@
 package ioc
 
 import (
-	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
 	"mall/internal/auth"
+	"mall/internal/cart"
+	"mall/internal/notification"
+	"mall/internal/order"
 	"mall/internal/product"
+	"mall/internal/qa"
+	"mall/internal/ranking"
+	"mall/internal/recommend"
+	"mall/internal/review"
 	"mall/internal/user"
+	"mall/internal/wishlist"
+	"mall/pkg/taskx"
 )
 
-var BaseSet = wire.NewSet(InitRedis, InitDB)
+var BaseSet = wire.NewSet(InitRedis, InitDB, InitBlob, taskx.NewGroup)
 
-func InitGin() *gin.Engine {
+func InitApp() *App {
 	wire.Build(
 		BaseSet,
 
@@ -20,13 +28,51 @@ func InitGin() *gin.Engine {
 
 		auth.JWTSet,
 
+		InitLoginHooks,
 		user.InitUserHandler,
 
+		InitDeviceIds,
+		product.InitProductCache,
 		product.InitProductHandler,
+		product.InitViewHistoryService,
+		product.InitPurgeJob,
+		product.InitScheduleJob,
+		product.InitFlashStockJob,
+
+		review.InitReviewHandler,
+
+		qa.InitQaHandler,
+
+		notification.InitNotificationHandler,
+
+		wishlist.InitWishlistHandler,
+		wishlist.InitPriceWatchJob,
+
+		recommend.InitRecommendHandler,
+		recommend.InitRebuildJob,
+
+		InitProductEventHooks,
+		ranking.InitRankingService,
+		ranking.InitRankingHandler,
+		ranking.InitRankingJob,
+
+		InitCartRepository,
+		cart.InitPurgeHook,
+		cart.InitGuestCartService,
+		cart.InitCartHandler,
+		cart.InitFlushJob,
+
+		order.InitOrderHandler,
+
+		InitDelayScheduler,
 
 		InitMiddleware,
 
 		InitWeb,
+
+		InitJobs,
+
+		wire.Struct(new(App), "*"),
 	)
-	return nil
+	return new(App)
 }
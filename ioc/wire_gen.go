@@ -7,28 +7,70 @@
 package ioc
 
 import (
-	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
 	"mall/internal/auth/jwt"
+	"mall/internal/cart"
+	"mall/internal/notification"
+	"mall/internal/order"
 	"mall/internal/product"
+	"mall/internal/qa"
+	"mall/internal/ranking"
+	"mall/internal/recommend"
+	"mall/internal/review"
 	"mall/internal/user"
+	"mall/internal/wishlist"
+	"mall/pkg/taskx"
 )
 
 // Injectors from wire.go:
 
-func InitGin() *gin.Engine {
+func InitApp() *App {
 	tokenHandler := jwt.NewJwtHandler()
 	cmdable := InitRedis()
 	redisSession := jwt.NewRedisSession(cmdable)
 	logger := InitLogger()
 	v := InitMiddleware(tokenHandler, redisSession, logger)
 	db := InitDB(logger)
-	userHandler := user.InitUserHandler(db, cmdable)
-	productHandler := product.InitProductHandler(db, cmdable)
-	engine := InitWeb(v, userHandler, productHandler)
-	return engine
+	productCache := product.InitProductCache(cmdable)
+	deviceIds := InitDeviceIds()
+	viewHistoryService := product.InitViewHistoryService(db, cmdable, productCache, deviceIds)
+	repository := InitCartRepository(db, cmdable)
+	rankingService := ranking.InitRankingService(db, cmdable, productCache)
+	purgeHook := cart.InitPurgeHook(repository, db)
+	v2 := InitProductEventHooks(rankingService, purgeHook)
+	guestCartService := cart.InitGuestCartService(repository, db, cmdable, productCache, v2, deviceIds, logger)
+	v3 := InitLoginHooks(viewHistoryService, guestCartService)
+	userHandler := user.InitUserHandler(db, cmdable, v3)
+	blob := InitBlob()
+	group := taskx.NewGroup()
+	productHandler := product.InitProductHandler(db, cmdable, productCache, blob, logger, v2, group, deviceIds)
+	reviewHandler := review.InitReviewHandler(db, cmdable, productCache)
+	qaHandler := qa.InitQaHandler(db, cmdable, productCache, group, logger)
+	notificationHandler := notification.InitNotificationHandler(db)
+	wishlistHandler := wishlist.InitWishlistHandler(db, cmdable, productCache)
+	recommendHandler := recommend.InitRecommendHandler(db, cmdable, productCache, repository)
+	rankingHandler := ranking.InitRankingHandler(db, cmdable, productCache)
+	cartHandler := cart.InitCartHandler(repository, guestCartService, db, cmdable, productCache, v2, logger)
+	scheduler := InitDelayScheduler(db, cmdable, logger)
+	orderHandler := order.InitOrderHandler(repository, db, cmdable, productCache, v2, logger, scheduler)
+	engine := InitWeb(v, userHandler, productHandler, reviewHandler, qaHandler, notificationHandler, wishlistHandler, recommendHandler, rankingHandler, cartHandler, orderHandler)
+	purgeJob := product.InitPurgeJob(db, cmdable, productCache, logger, v2)
+	scheduleJob := product.InitScheduleJob(db, cmdable, productCache, logger)
+	priceWatchJob := wishlist.InitPriceWatchJob(db, cmdable, productCache, logger)
+	rebuildJob := recommend.InitRebuildJob(db, cmdable, productCache, repository, logger)
+	rankingJob := ranking.InitRankingJob(db, cmdable, productCache, logger)
+	flushJob := cart.InitFlushJob(repository, cmdable, logger)
+	flashStockJob := product.InitFlashStockJob(db, cmdable, productCache, logger)
+	v4 := InitJobs(purgeJob, scheduleJob, priceWatchJob, rebuildJob, rankingJob, flushJob, flashStockJob)
+	app := &App{
+		Server: engine,
+		Jobs:   v4,
+		Delay:  scheduler,
+		Tasks:  group,
+	}
+	return app
 }
 
 // wire.go:
 
-var BaseSet = wire.NewSet(InitRedis, InitDB)
+var BaseSet = wire.NewSet(InitRedis, InitDB, InitBlob, taskx.NewGroup)
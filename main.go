@@ -21,13 +21,20 @@ func main() {
 	initViper()
 	initLogger()
 
-	router := ioc.InitGin()
+	app := ioc.InitApp()
 
 	server := &http.Server{
 		Addr:    "0.0.0.0:9000",
-		Handler: router,
+		Handler: app.Server,
 	}
 
+	// 启动后台任务
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	for _, job := range app.Jobs {
+		go job.Start(jobCtx)
+	}
+	go app.Delay.Start(jobCtx)
+
 	go func() {
 		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("listen: %s\n", err)
@@ -44,6 +51,7 @@ func main() {
 	// 阻塞直到收到信号
 	<-quit
 	zap.L().Info("shutting down server")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 
@@ -54,6 +62,15 @@ func main() {
 		zap.L().Error("Server forced shutting down", zap.Error(err))
 	}
 
+	// 等待执行中的延时任务，超时未结束的任务由其他实例重新执行
+	if err := app.Delay.Shutdown(ctx); err != nil {
+		zap.L().Warn("Delay tasks not drained", zap.Error(err))
+	}
+	// 等待请求中启动的后台任务，超时后取消
+	if err := app.Tasks.Shutdown(ctx); err != nil {
+		zap.L().Warn("Background tasks not drained", zap.Error(err))
+	}
+
 	zap.L().Info("Server exited gracefully")
 }
 
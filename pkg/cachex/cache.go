@@ -0,0 +1,248 @@
+package cachex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// emptyPrefix 空值在 redis 中的标记，后面跟着空值错误在 emptyErrs 中的下标
+// JSON 编码的值不会以 # 开头
+const emptyPrefix = "#empty:"
+
+// Cache 通用的旁路缓存：本地 L1 -> redis -> loader
+//
+//   - 同一个 key 的并发回源通过 singleflight 合并
+//   - loader 返回 emptyErrs 中的错误时缓存空值，防止不存在的 key 穿透到数据库
+//   - 过期时间带随机抖动，避免大量 key 同时过期
+//   - Del 删除 redis 和本地缓存，并通过 pub/sub 通知其他实例删除本地缓存
+type Cache[T any] struct {
+	cmd     redis.Cmdable
+	channel string
+	local   *localCache[T]
+	group   singleflight.Group
+
+	ttl       time.Duration
+	emptyTTL  time.Duration
+	jitter    float64
+	emptyErrs []error
+}
+
+type Option func(*options)
+
+type options struct {
+	ttl       time.Duration
+	emptyTTL  time.Duration
+	jitter    float64
+	emptyErrs []error
+	localSize int
+	localTTL  time.Duration
+}
+
+// WithTTL 设置 redis 中正常值的过期时间
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithEmpty 设置需要缓存为空值的错误以及空值的过期时间
+func WithEmpty(ttl time.Duration, errs ...error) Option {
+	return func(o *options) {
+		o.emptyTTL = ttl
+		o.emptyErrs = errs
+	}
+}
+
+// WithJitter 过期时间在 [ttl, ttl*(1+jitter)) 之间随机
+func WithJitter(jitter float64) Option {
+	return func(o *options) {
+		o.jitter = jitter
+	}
+}
+
+// WithLocal 开启本地 L1 缓存，size 为最大条目数
+func WithLocal(size int, ttl time.Duration) Option {
+	return func(o *options) {
+		o.localSize = size
+		o.localTTL = ttl
+	}
+}
+
+// New 创建缓存，prefix 同时作为 key 前缀和失效通知的频道名
+func New[T any](cmd redis.Cmdable, prefix string, opts ...Option) *Cache[T] {
+	o := options{
+		ttl:      time.Minute * 5,
+		emptyTTL: time.Minute,
+		jitter:   0.1,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c := &Cache[T]{
+		cmd:       cmd,
+		channel:   "cachex:invalidate:" + prefix,
+		ttl:       o.ttl,
+		emptyTTL:  o.emptyTTL,
+		jitter:    o.jitter,
+		emptyErrs: o.emptyErrs,
+	}
+	if o.localSize > 0 {
+		c.local = newLocalCache[T](o.localSize, o.localTTL)
+		c.subscribe()
+	}
+
+	return c
+}
+
+// Get 依次读取本地缓存、redis，都未命中时调用 load 回源并写回缓存
+func (c *Cache[T]) Get(ctx context.Context, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	if c.local != nil {
+		if val, err, ok := c.local.get(key); ok {
+			return val, err
+		}
+	}
+
+	type result struct {
+		val T
+		err error
+	}
+	res, err, _ := c.group.Do(key, func() (interface{}, error) {
+		// 结果会共享给其他调用方，不能因为第一个调用方取消而失败
+		ctx := context.WithoutCancel(ctx)
+
+		val, err, ok := c.getRemote(ctx, key)
+		if !ok {
+			val, err = load(ctx)
+			if err != nil && c.emptyIndex(err) < 0 {
+				return nil, err
+			}
+			c.setRemote(ctx, key, val, err)
+		}
+
+		if c.local != nil {
+			c.local.set(key, val, err)
+		}
+		return result{val: val, err: err}, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	r := res.(result)
+	return r.val, r.err
+}
+
+// Del 删除缓存，并通知其他实例删除本地缓存
+func (c *Cache[T]) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		// 正在进行的回源可能读到旧数据，之后的请求不再复用它
+		c.group.Forget(key)
+		if c.local != nil {
+			c.local.del(key)
+		}
+	}
+
+	err := c.cmd.Del(ctx, keys...).Err()
+	if c.local != nil {
+		if perr := c.cmd.Publish(ctx, c.channel, strings.Join(keys, "\n")).Err(); perr != nil {
+			err = errors.Join(err, perr)
+		}
+	}
+
+	return err
+}
+
+func (c *Cache[T]) getRemote(ctx context.Context, key string) (T, error, bool) {
+	var val T
+	raw, err := c.cmd.Get(ctx, key).Result()
+	if err != nil {
+		// redis 不可用时直接回源
+		return val, nil, false
+	}
+
+	if idx, ok := strings.CutPrefix(raw, emptyPrefix); ok {
+		i, err := strconv.Atoi(idx)
+		if err != nil || i < 0 || i >= len(c.emptyErrs) {
+			return val, nil, false
+		}
+		return val, c.emptyErrs[i], true
+	}
+
+	if err := json.Unmarshal([]byte(raw), &val); err != nil {
+		return val, nil, false
+	}
+	return val, nil, true
+}
+
+func (c *Cache[T]) setRemote(ctx context.Context, key string, val T, loadErr error) {
+	var (
+		raw string
+		ttl = c.ttl
+	)
+	if loadErr != nil {
+		raw = emptyPrefix + strconv.Itoa(c.emptyIndex(loadErr))
+		ttl = c.emptyTTL
+	} else {
+		data, err := json.Marshal(val)
+		if err != nil {
+			log.Printf("缓存序列化失败 %v", err.Error())
+			return
+		}
+		raw = string(data)
+	}
+
+	if err := c.cmd.Set(ctx, key, raw, c.withJitter(ttl)).Err(); err != nil {
+		log.Printf("缓存更新失败 %v", err.Error())
+	}
+}
+
+func (c *Cache[T]) emptyIndex(err error) int {
+	for i, e := range c.emptyErrs {
+		if errors.Is(err, e) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *Cache[T]) withJitter(ttl time.Duration) time.Duration {
+	if c.jitter <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int63n(int64(float64(ttl)*c.jitter)+1))
+}
+
+// subscribe 监听其他实例发出的失效通知，删除本地缓存
+// cmd 不支持订阅时（如 pipeline）只依赖本地缓存的过期时间
+func (c *Cache[T]) subscribe() {
+	sub, ok := c.cmd.(interface {
+		Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+	})
+	if !ok {
+		return
+	}
+
+	pubsub := sub.Subscribe(context.Background(), c.channel)
+	go func() {
+		// 断线后 go-redis 会自动重连并重新订阅
+		for msg := range pubsub.Channel() {
+			for _, key := range strings.Split(msg.Payload, "\n") {
+				c.local.del(key)
+			}
+		}
+	}()
+}
@@ -0,0 +1,83 @@
+package cachex
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// localCache 进程内的 LRU 缓存，容量有上限，每个条目带过期时间
+type localCache[T any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type localEntry[T any] struct {
+	key      string
+	val      T
+	err      error // 非 nil 表示缓存的是空值
+	expireAt time.Time
+}
+
+func newLocalCache[T any](capacity int, ttl time.Duration) *localCache[T] {
+	return &localCache[T]{
+		capacity: capacity,
+		ttl:      ttl,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element, capacity),
+	}
+}
+
+func (c *localCache[T]) get(key string) (T, error, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero T
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, nil, false
+	}
+	entry := elem.Value.(*localEntry[T])
+	if time.Now().After(entry.expireAt) {
+		c.removeElement(elem)
+		return zero, nil, false
+	}
+	c.ll.MoveToFront(elem)
+
+	return entry.val, entry.err, true
+}
+
+func (c *localCache[T]) set(key string, val T, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expireAt := time.Now().Add(c.ttl)
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*localEntry[T])
+		entry.val, entry.err, entry.expireAt = val, err, expireAt
+		c.ll.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&localEntry[T]{key: key, val: val, err: err, expireAt: expireAt})
+	for c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *localCache[T]) del(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+func (c *localCache[T]) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*localEntry[T]).key)
+}
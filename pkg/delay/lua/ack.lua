@@ -0,0 +1,10 @@
+-- 确认任务，凭证不一致时说明任务已经被重新投递，不做任何事
+-- KEYS[1] 任务队列 zset，KEYS[2] 任务内容 hash，KEYS[3] 任务凭证 hash
+-- ARGV: 任务 id，凭证
+if redis.call("hget", KEYS[3], ARGV[1]) ~= ARGV[2] then
+    return 0
+end
+redis.call("zrem", KEYS[1], ARGV[1])
+redis.call("hdel", KEYS[2], ARGV[1])
+redis.call("hdel", KEYS[3], ARGV[1])
+return 1
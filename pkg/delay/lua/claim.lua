@@ -0,0 +1,18 @@
+-- 取出到期的任务，并把它们的可见时间推迟到可见性超时之后，返回任务 id 和内容交替的列表
+-- KEYS[1] 任务队列 zset（分数为可见时间），KEYS[2] 任务内容 hash，KEYS[3] 任务凭证 hash
+-- ARGV: 当前时间（毫秒），最多取出的数量，可见性超时的时间（毫秒），本次取出的凭证
+local ids = redis.call("zrangebyscore", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, tonumber(ARGV[2]))
+local res = {}
+for _, id in ipairs(ids) do
+    local body = redis.call("hget", KEYS[2], id)
+    if body then
+        redis.call("zadd", KEYS[1], ARGV[3], id)
+        redis.call("hset", KEYS[3], id, ARGV[4])
+        table.insert(res, id)
+        table.insert(res, body)
+    else
+        redis.call("zrem", KEYS[1], id)
+        redis.call("hdel", KEYS[3], id)
+    end
+end
+return res
@@ -0,0 +1,10 @@
+-- 任务执行失败，更新任务内容并在指定时间重新投递，凭证不一致时不做任何事
+-- KEYS[1] 任务队列 zset，KEYS[2] 任务内容 hash，KEYS[3] 任务凭证 hash
+-- ARGV: 任务 id，凭证，重新投递的时间（毫秒），任务内容
+if redis.call("hget", KEYS[3], ARGV[1]) ~= ARGV[2] then
+    return 0
+end
+redis.call("zadd", KEYS[1], ARGV[3], ARGV[1])
+redis.call("hset", KEYS[2], ARGV[1], ARGV[4])
+redis.call("hdel", KEYS[3], ARGV[1])
+return 1
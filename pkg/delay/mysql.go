@@ -0,0 +1,126 @@
+package delay
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DelayTask MySQL 中的延时任务，需要加入迁移
+type DelayTask struct {
+	Id        uint64 `gorm:"primaryKey,autoIncrement"`
+	TaskId    string `gorm:"type:varchar(128);not null;uniqueIndex:uk_task_id"`
+	Type      string `gorm:"type:varchar(64);not null"`
+	Payload   string `gorm:"type:text"`
+	Attempt   int    `gorm:"not null"`
+	RunAt     int64  `gorm:"not null"`
+	VisibleAt int64  `gorm:"not null;index"`            // 到期或可见性超时的时间，不晚于当前时间的任务可以取出
+	Lease     string `gorm:"type:varchar(36);not null"` // 取出时的凭证
+	CreateAt  int64
+	UpdateAt  int64
+}
+
+// MySQLStore 使用 MySQL 表保存任务，取出时用 SKIP LOCKED 跳过其他实例正在取出的行，需要 MySQL 8.0 及以上
+type MySQLStore struct {
+	db *gorm.DB
+}
+
+func NewMySQLStore(db *gorm.DB) *MySQLStore {
+	return &MySQLStore{
+		db: db,
+	}
+}
+
+func (s *MySQLStore) Add(ctx context.Context, task Task) error {
+	now := time.Now().UnixMilli()
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"type", "payload", "attempt", "run_at", "visible_at", "lease", "update_at"}),
+	}).Create(&DelayTask{
+		TaskId:    task.Id,
+		Type:      task.Type,
+		Payload:   task.Payload,
+		Attempt:   task.Attempt,
+		RunAt:     task.RunAt.UnixMilli(),
+		VisibleAt: task.RunAt.UnixMilli(),
+		CreateAt:  now,
+		UpdateAt:  now,
+	}).Error
+}
+
+func (s *MySQLStore) Claim(ctx context.Context, now time.Time, n int, visibility time.Duration) ([]Task, error) {
+	lease := uuid.New().String()
+	var rows []DelayTask
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("visible_at <= ?", now.UnixMilli()).
+			Order("visible_at").
+			Limit(n).
+			Find(&rows).Error
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+
+		ids := make([]uint64, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, row.Id)
+		}
+		return tx.Model(&DelayTask{}).Where("id IN ?", ids).Updates(map[string]any{
+			"visible_at": now.Add(visibility).UnixMilli(),
+			"lease":      lease,
+			"update_at":  now.UnixMilli(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0, len(rows))
+	for _, row := range rows {
+		tasks = append(tasks, Task{
+			Id:      row.TaskId,
+			Type:    row.Type,
+			Payload: row.Payload,
+			RunAt:   time.UnixMilli(row.RunAt),
+			Attempt: row.Attempt,
+			lease:   lease,
+		})
+	}
+	return tasks, nil
+}
+
+func (s *MySQLStore) Ack(ctx context.Context, task Task) error {
+	res := s.db.WithContext(ctx).Where("task_id = ? AND lease = ?", task.Id, task.lease).Delete(&DelayTask{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (s *MySQLStore) Retry(ctx context.Context, task Task, runAt time.Time) error {
+	res := s.db.WithContext(ctx).Model(&DelayTask{}).
+		Where("task_id = ? AND lease = ?", task.Id, task.lease).
+		Updates(map[string]any{
+			"attempt":    task.Attempt,
+			"visible_at": runAt.UnixMilli(),
+			"lease":      "",
+			"update_at":  time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (s *MySQLStore) Cancel(ctx context.Context, id string) error {
+	return s.db.WithContext(ctx).Where("task_id = ?", id).Delete(&DelayTask{}).Error
+}
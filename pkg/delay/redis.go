@@ -0,0 +1,114 @@
+package delay
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed lua/claim.lua
+var luaClaim string
+
+//go:embed lua/ack.lua
+var luaAck string
+
+//go:embed lua/retry.lua
+var luaRetry string
+
+// RedisStore 使用 Redis 保存任务：zset 按可见时间排序任务 id，hash 保存任务内容和取出时的凭证，
+// 取出、确认和重试都在 Lua 脚本中完成
+type RedisStore struct {
+	cmd       redis.Cmdable
+	queueKey  string
+	tasksKey  string
+	leasesKey string
+}
+
+// NewRedisStore prefix 为 key 前缀，不同的调度器使用不同的前缀
+func NewRedisStore(cmd redis.Cmdable, prefix string) *RedisStore {
+	return &RedisStore{
+		cmd:       cmd,
+		queueKey:  prefix + ":queue",
+		tasksKey:  prefix + ":tasks",
+		leasesKey: prefix + ":leases",
+	}
+}
+
+func (s *RedisStore) Add(ctx context.Context, task Task) error {
+	body, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.cmd.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.tasksKey, task.Id, body)
+		pipe.ZAdd(ctx, s.queueKey, redis.Z{Score: float64(task.RunAt.UnixMilli()), Member: task.Id})
+		pipe.HDel(ctx, s.leasesKey, task.Id)
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) Claim(ctx context.Context, now time.Time, n int, visibility time.Duration) ([]Task, error) {
+	lease := uuid.New().String()
+	res, err := s.cmd.Eval(ctx, luaClaim, s.keys(), now.UnixMilli(), n, now.Add(visibility).UnixMilli(), lease).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0, len(res)/2)
+	for i := 0; i+1 < len(res); i += 2 {
+		var task Task
+		// 无法解析的任务没有类型，找不到处理函数，重试次数用完后丢弃
+		_ = json.Unmarshal([]byte(res[i+1]), &task)
+		task.Id = res[i]
+		task.lease = lease
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (s *RedisStore) Ack(ctx context.Context, task Task) error {
+	n, err := s.cmd.Eval(ctx, luaAck, s.keys(), task.Id, task.lease).Int()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (s *RedisStore) Retry(ctx context.Context, task Task, runAt time.Time) error {
+	body, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	n, err := s.cmd.Eval(ctx, luaRetry, s.keys(), task.Id, task.lease, runAt.UnixMilli(), body).Int()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (s *RedisStore) Cancel(ctx context.Context, id string) error {
+	_, err := s.cmd.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, s.queueKey, id)
+		pipe.HDel(ctx, s.tasksKey, id)
+		pipe.HDel(ctx, s.leasesKey, id)
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) keys() []string {
+	return []string{s.queueKey, s.tasksKey, s.leasesKey}
+}
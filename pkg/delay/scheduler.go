@@ -0,0 +1,266 @@
+package delay
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"mall/pkg/logger"
+)
+
+type Option func(*options)
+
+type options struct {
+	pollInterval time.Duration
+	visibility   time.Duration
+	workers      int
+	maxAttempts  int
+	backoffBase  time.Duration
+	backoffMax   time.Duration
+}
+
+// WithPollInterval 设置没有到期任务时轮询存储的间隔
+func WithPollInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = interval
+	}
+}
+
+// WithVisibility 设置可见性超时，同时是单个任务执行的超时时间
+func WithVisibility(visibility time.Duration) Option {
+	return func(o *options) {
+		o.visibility = visibility
+	}
+}
+
+// WithWorkers 设置同时执行的任务数
+func WithWorkers(workers int) Option {
+	return func(o *options) {
+		o.workers = workers
+	}
+}
+
+// WithRetry 设置最多执行次数和退避时间，第 n 次失败后等待 base*2^(n-1)，不超过 max
+func WithRetry(maxAttempts int, base, max time.Duration) Option {
+	return func(o *options) {
+		o.maxAttempts = maxAttempts
+		o.backoffBase = base
+		o.backoffMax = max
+	}
+}
+
+// Scheduler 延时任务调度器
+//
+//   - 按任务类型注册处理函数，Schedule 添加在指定时间执行的任务
+//   - 至少执行一次：任务取出后在可见性超时内没有确认会重新投递，处理函数需要幂等
+//   - 执行失败按指数退避重试，超过最多次数后丢弃并记录日志
+//   - 多实例共用同一份存储，每个任务同一时间只被一个实例取出
+type Scheduler struct {
+	store Store
+	l     logger.Logger
+	opts  options
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	started atomic.Bool
+	done    chan struct{}
+	running sync.WaitGroup
+	// 任务执行使用的 ctx，不随 Start 的 ctx 取消，排空超时后才取消
+	runCtx    context.Context
+	cancelRun context.CancelFunc
+}
+
+func NewScheduler(store Store, l logger.Logger, opts ...Option) *Scheduler {
+	o := options{
+		pollInterval: time.Second,
+		visibility:   time.Second * 30,
+		workers:      8,
+		maxAttempts:  10,
+		backoffBase:  time.Second * 5,
+		backoffMax:   time.Minute * 10,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		store:     store,
+		l:         l,
+		opts:      o,
+		handlers:  make(map[string]Handler),
+		done:      make(chan struct{}),
+		runCtx:    runCtx,
+		cancelRun: cancel,
+	}
+}
+
+// Register 注册任务类型的处理函数，重复注册时覆盖
+func (s *Scheduler) Register(taskType string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[taskType] = h
+}
+
+// Schedule 添加任务，Id 为空时生成随机 Id，RunAt 为空时立即执行；返回任务 Id
+func (s *Scheduler) Schedule(ctx context.Context, task Task) (string, error) {
+	if task.Type == "" {
+		return "", ErrInvalidTask
+	}
+	if task.Id == "" {
+		task.Id = uuid.New().String()
+	}
+	if task.RunAt.IsZero() {
+		task.RunAt = time.Now()
+	}
+	task.Attempt = 0
+
+	return task.Id, s.store.Add(ctx, task)
+}
+
+// Cancel 取消还没有执行的任务
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	return s.store.Cancel(ctx, id)
+}
+
+// Start 轮询并执行到期的任务，ctx 取消后不再取新任务，执行中的任务继续执行，由 Shutdown 等待
+func (s *Scheduler) Start(ctx context.Context) {
+	if !s.started.CompareAndSwap(false, true) {
+		return
+	}
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.pollInterval)
+	defer ticker.Stop()
+
+	sem := make(chan struct{}, s.opts.workers)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.poll(ctx, sem)
+		}
+	}
+}
+
+// Shutdown 等待 Start 退出和执行中的任务结束，调用前先取消 Start 的 ctx。
+// ctx 到期时取消仍在执行的任务并返回，这些任务在可见性超时后重新投递
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	if !s.started.Load() {
+		return nil
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		<-s.done
+		s.running.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		s.cancelRun()
+		return ctx.Err()
+	}
+}
+
+// poll 取出到期的任务交给空闲的 worker，只取能立即执行的数量，避免取出后等待期间可见性超时
+func (s *Scheduler) poll(ctx context.Context, sem chan struct{}) {
+	for {
+		free := cap(sem) - len(sem)
+		if free == 0 {
+			return
+		}
+
+		tasks, err := s.store.Claim(ctx, time.Now(), free, s.opts.visibility)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.l.Error("延时任务:取任务失败", logger.Error(err))
+			}
+			return
+		}
+		for _, task := range tasks {
+			sem <- struct{}{}
+			s.running.Add(1)
+			go func(task Task) {
+				defer func() {
+					<-sem
+					s.running.Done()
+				}()
+				s.run(task)
+			}(task)
+		}
+		if len(tasks) < free {
+			return
+		}
+	}
+}
+
+func (s *Scheduler) run(task Task) {
+	err := s.handle(task)
+
+	// 执行被取消时也要提交结果，提交使用独立的 ctx
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	if err == nil {
+		if err := s.store.Ack(ctx, task); err != nil {
+			s.l.Warn("延时任务:确认失败", logger.String("id", task.Id), logger.Error(err))
+		}
+		return
+	}
+
+	task.Attempt++
+	if task.Attempt >= s.opts.maxAttempts {
+		s.l.Error("延时任务:重试次数用完，放弃执行", logger.String("id", task.Id),
+			logger.String("type", task.Type), logger.Error(err))
+		if err := s.store.Ack(ctx, task); err != nil {
+			s.l.Warn("延时任务:确认失败", logger.String("id", task.Id), logger.Error(err))
+		}
+		return
+	}
+
+	s.l.Warn("延时任务:执行失败", logger.String("id", task.Id), logger.String("type", task.Type),
+		logger.Field{Key: "attempt", Val: task.Attempt}, logger.Error(err))
+	if err := s.store.Retry(ctx, task, time.Now().Add(s.backoff(task.Attempt))); err != nil {
+		s.l.Warn("延时任务:重试失败", logger.String("id", task.Id), logger.Error(err))
+	}
+}
+
+// handle 调用处理函数，处理函数 panic 时按失败处理
+func (s *Scheduler) handle(task Task) (err error) {
+	s.mu.RLock()
+	h, ok := s.handlers[task.Type]
+	s.mu.RUnlock()
+	// 滚动发布时新类型的任务可能被旧版本的实例取到，退避后由其他实例重试
+	if !ok {
+		return ErrNoHandler
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panic: %v", r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(s.runCtx, s.opts.visibility)
+	defer cancel()
+	return h(ctx, task)
+}
+
+// backoff 第 attempt 次失败后的等待时间
+func (s *Scheduler) backoff(attempt int) time.Duration {
+	d := s.opts.backoffBase
+	for i := 1; i < attempt && d < s.opts.backoffMax; i++ {
+		d *= 2
+	}
+	return min(d, s.opts.backoffMax)
+}
@@ -0,0 +1,44 @@
+package delay
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidTask = errors.New("task type is required")
+	// ErrLeaseLost 任务执行超过了可见性超时，已经被重新投递，本次执行的结果不再提交
+	ErrLeaseLost = errors.New("task lease is lost")
+	ErrNoHandler = errors.New("no handler for the task type")
+)
+
+// Task 延时任务，同一 Id 重复添加时覆盖之前未执行的任务
+type Task struct {
+	Id      string    `json:"id"`
+	Type    string    `json:"type"`
+	Payload string    `json:"payload"`
+	RunAt   time.Time `json:"runAt"`
+	Attempt int       `json:"attempt"` // 已经失败的次数
+
+	// lease 取出任务时的凭证，确认或重试时校验，防止超时后被重新投递的任务被旧的执行者确认
+	lease string
+}
+
+// Handler 执行一种类型的任务，返回错误时按退避时间重试
+type Handler func(ctx context.Context, task Task) error
+
+// Store 延时任务的存储，多个实例共用同一份存储时每个任务同一时间只会被一个实例取出
+type Store interface {
+	// Add 添加任务，Id 已存在时覆盖
+	Add(ctx context.Context, task Task) error
+	// Claim 取出最多 n 个到期的任务，取出的任务在 visibility 内对其他实例不可见，
+	// 超时没有 Ack 或 Retry 时重新投递
+	Claim(ctx context.Context, now time.Time, n int, visibility time.Duration) ([]Task, error)
+	// Ack 任务执行完成，删除任务
+	Ack(ctx context.Context, task Task) error
+	// Retry 任务执行失败，在 runAt 重新投递，保存 task.Attempt
+	Retry(ctx context.Context, task Task, runAt time.Time) error
+	// Cancel 删除还没有执行的任务，任务不存在时不返回错误
+	Cancel(ctx context.Context, id string) error
+}
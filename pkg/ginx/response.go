@@ -1,4 +1,10 @@
-package web
+package ginx
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
 
 type Response struct {
 	Status int         `json:"status"`
@@ -36,3 +42,17 @@ func WithMsg(msg string) func(*Response) {
 		r.Msg = msg
 	}
 }
+
+// Pagination 从 query 中解析分页参数，默认第 1 页，每页 20 条
+func Pagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	size, err := strconv.Atoi(c.Query("size"))
+	if err != nil || size < 1 || size > 100 {
+		size = 20
+	}
+
+	return page, size
+}
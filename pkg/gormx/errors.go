@@ -0,0 +1,15 @@
+package gormx
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const uniqueConflictErrNo uint16 = 1062
+
+// IsUniqueConflict 判断是否为唯一索引冲突
+func IsUniqueConflict(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == uniqueConflictErrNo
+}
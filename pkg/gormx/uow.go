@@ -0,0 +1,89 @@
+package gormx
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// txState ctx 中保存的事务以及提交、回滚后要执行的回调
+type txState struct {
+	tx            *gorm.DB
+	afterCommit   []func()
+	afterRollback []func()
+}
+
+// UnitOfWork 把多个 DAO 的写操作放进同一个事务
+// DAO 内部通过 DB(ctx, db) 取连接，即可自动加入外层事务
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{
+		db: db,
+	}
+}
+
+// Do 在事务中执行 fn，fn 返回错误或 panic 时回滚
+// 如果 ctx 中已经存在事务，则直接复用，不再开启新的事务
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	state := &txState{}
+	committed := false
+	// 放在 defer 中，fn panic 回滚时也会执行
+	defer func() {
+		if committed {
+			return
+		}
+		for _, f := range state.afterRollback {
+			f()
+		}
+	}()
+	err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.tx = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+	if err != nil {
+		return err
+	}
+
+	committed = true
+	for _, f := range state.afterCommit {
+		f()
+	}
+	return nil
+}
+
+// DB 返回 ctx 中的事务，没有事务时返回 db
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
+
+// AfterCommit 在最外层事务提交后执行 fn，回滚时不执行；ctx 中没有事务时立即执行
+// 用于删除缓存等不能早于提交发生的操作
+func AfterCommit(ctx context.Context, fn func()) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+
+	fn()
+}
+
+// AfterRollback 在最外层事务回滚后执行 fn，提交时不执行；ctx 中没有事务时不执行
+// 用于撤销已经在事务之外生效的操作，例如 Redis 中的预扣
+func AfterRollback(ctx context.Context, fn func()) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterRollback = append(state.afterRollback, fn)
+	}
+}
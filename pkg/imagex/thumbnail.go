@@ -0,0 +1,71 @@
+package imagex
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/jpeg"
+)
+
+// Thumbnail 等比缩放，使最长边不超过 maxSide，小图不放大
+// 缩小时使用区域平均，避免最近邻采样带来的锯齿
+func Thumbnail(src image.Image, maxSide int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+
+	dw, dh := maxSide, maxSide
+	if w > h {
+		dh = max(1, h*maxSide/w)
+	} else {
+		dw = max(1, w*maxSide/h)
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				off := sy*rgba.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(rgba.Pix[off])
+					g += uint64(rgba.Pix[off+1])
+					bl += uint64(rgba.Pix[off+2])
+					a += uint64(rgba.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(bl / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+// EncodeJPEG 将图片编码为 JPEG，透明区域填充为白色
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	bg := image.NewRGBA(img.Bounds())
+	draw.Draw(bg, bg.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(bg, bg.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, bg, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,96 @@
+package redisx
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrLockNotHold = errors.New("lock is not held")
+
+//go:embed lua/unlock.lua
+var luaUnlock string
+
+//go:embed lua/refresh.lua
+var luaRefresh string
+
+// Lock 基于 SET NX 的分布式锁，用于多实例间的互斥
+type Lock struct {
+	cmd   redis.Cmdable
+	key   string
+	token string
+	ttl   time.Duration
+}
+
+func NewLock(cmd redis.Cmdable, key string, ttl time.Duration) *Lock {
+	return &Lock{
+		cmd:   cmd,
+		key:   key,
+		token: uuid.New().String(),
+		ttl:   ttl,
+	}
+}
+
+// TryLock 尝试获取锁，锁已被其他实例持有时返回 false
+func (l *Lock) TryLock(ctx context.Context) (bool, error) {
+	return l.cmd.SetNX(ctx, l.key, l.token, l.ttl).Result()
+}
+
+// Unlock 释放锁，锁已过期或被其他实例持有时返回 ErrLockNotHold
+func (l *Lock) Unlock(ctx context.Context) error {
+	res, err := l.cmd.Eval(ctx, luaUnlock, []string{l.key}, l.token).Int()
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return ErrLockNotHold
+	}
+
+	return nil
+}
+
+// Refresh 把锁的过期时间重置为 ttl，锁已过期或被其他实例持有时返回 ErrLockNotHold
+func (l *Lock) Refresh(ctx context.Context) error {
+	res, err := l.cmd.Eval(ctx, luaRefresh, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return ErrLockNotHold
+	}
+
+	return nil
+}
+
+// KeepAlive 每隔 ttl/3 续期一次，直到调用返回的 stop。
+// 续期失败说明锁可能已经丢失，此时取消返回的 ctx，持有者应尽快停止
+func (l *Lock) KeepAlive(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := l.Refresh(ctx); err != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, func() {
+		close(done)
+		cancel()
+	}
+}
@@ -0,0 +1,110 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mall/pkg/storage"
+)
+
+// Blob 本地文件系统存储，适合开发环境或由 nginx 直接提供静态文件的部署
+type Blob struct {
+	dir     string
+	baseURL string
+}
+
+func NewBlob(dir string, baseURL string) *Blob {
+	return &Blob{
+		dir:     dir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (b *Blob) Put(ctx context.Context, key string, data io.Reader, size int64, contentType string) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，避免读到写了一半的文件
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (b *Blob) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", storage.ErrObjectNotFound, key)
+	}
+	return f, err
+}
+
+func (b *Blob) Exists(ctx context.Context, key string) (bool, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(path)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (b *Blob) Delete(ctx context.Context, key string) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (b *Blob) URL(key string) string {
+	return b.baseURL + "/" + key
+}
+
+func (b *Blob) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+
+	return filepath.Join(b.dir, clean), nil
+}
@@ -0,0 +1,171 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"mall/pkg/storage"
+)
+
+type Config struct {
+	Endpoint  string // 例如 http://localhost:9000（MinIO）或 https://s3.ap-east-1.amazonaws.com
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PublicURL 对外访问地址，例如 CDN 域名；为空时使用 Endpoint/Bucket
+	PublicURL string
+}
+
+// Blob S3 兼容的对象存储，使用 path-style 地址，可直接对接 MinIO 等本地替身
+type Blob struct {
+	cfg    Config
+	client *http.Client
+}
+
+func NewBlob(cfg Config) *Blob {
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	cfg.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	return &Blob{
+		cfg:    cfg,
+		client: &http.Client{Timeout: time.Second * 30},
+	}
+}
+
+func (b *Blob) Put(ctx context.Context, key string, data io.Reader, size int64, contentType string) error {
+	// 签名需要 payload 的哈希，图片不大，直接读到内存
+	body, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+
+	req, err := b.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp, key)
+}
+
+func (b *Blob) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := b.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp, key); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (b *Blob) Exists(ctx context.Context, key string) (bool, error) {
+	req, err := b.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err := checkResponse(resp, key); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b *Blob) Delete(ctx context.Context, key string) error {
+	req, err := b.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// S3 删除不存在的对象也返回 204
+	return checkResponse(resp, key)
+}
+
+func (b *Blob) URL(key string) string {
+	if b.cfg.PublicURL != "" {
+		return b.cfg.PublicURL + "/" + key
+	}
+
+	return b.cfg.Endpoint + b.objectPath(key)
+}
+
+func (b *Blob) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	u, err := url.Parse(b.cfg.Endpoint + b.objectPath(key))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+
+	sum := sha256.Sum256(body)
+	b.sign(req, hex.EncodeToString(sum[:]), time.Now().UTC())
+
+	return req, nil
+}
+
+// objectPath 返回 /bucket/key，key 的每一段都做 URI 编码
+func (b *Blob) objectPath(key string) string {
+	segments := strings.Split(strings.TrimLeft(key, "/"), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+
+	return "/" + b.cfg.Bucket + "/" + strings.Join(segments, "/")
+}
+
+func checkResponse(resp *http.Response, key string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", storage.ErrObjectNotFound, key)
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3: %s %s: %s", resp.Status, key, msg)
+}
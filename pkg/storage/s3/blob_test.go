@@ -0,0 +1,145 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"mall/pkg/storage"
+)
+
+// fakeS3 本地替身，按 path-style 地址保存对象，并校验请求带有签名和正确的 payload 哈希
+type fakeS3 struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func newFakeS3(t *testing.T) *fakeS3 {
+	return &fakeS3{
+		t:       t,
+		objects: make(map[string][]byte),
+		types:   make(map[string]string),
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, signAlgorithm+" Credential=ak/") ||
+		!strings.Contains(auth, "/us-east-1/s3/aws4_request") ||
+		!strings.Contains(auth, "SignedHeaders=host;x-amz-content-sha256;x-amz-date") {
+		f.t.Errorf("unexpected Authorization header %q", auth)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	sum := sha256.Sum256(body)
+	if r.Header.Get("x-amz-content-sha256") != hex.EncodeToString(sum[:]) {
+		f.t.Errorf("payload hash mismatch for %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := r.URL.EscapedPath()
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[path] = body
+		f.types[path] = r.Header.Get("Content-Type")
+	case http.MethodGet, http.MethodHead:
+		data, ok := f.objects[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[path])
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestBlob(t *testing.T) {
+	fake := newFakeS3(t)
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	b := NewBlob(Config{
+		Endpoint:  srv.URL + "/",
+		Bucket:    "mall",
+		AccessKey: "ak",
+		SecretKey: "sk",
+	})
+	ctx := context.Background()
+	key := "images/ab/a b.png"
+	data := []byte("png data")
+
+	exists, err := b.Exists(ctx, key)
+	if err != nil || exists {
+		t.Fatalf("Exists before Put = %v, %v; want false, nil", exists, err)
+	}
+	if _, err := b.Get(ctx, key); !errors.Is(err, storage.ErrObjectNotFound) {
+		t.Fatalf("Get before Put err = %v; want ErrObjectNotFound", err)
+	}
+
+	if err := b.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := fake.types["/mall/images/ab/a%20b.png"]; got != "image/png" {
+		t.Errorf("stored content type = %q; want image/png", got)
+	}
+
+	exists, err = b.Exists(ctx, key)
+	if err != nil || !exists {
+		t.Fatalf("Exists after Put = %v, %v; want true, nil", exists, err)
+	}
+	rc, err := b.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("Get = %q, %v; want %q", got, err, data)
+	}
+
+	if err := b.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	exists, err = b.Exists(ctx, key)
+	if err != nil || exists {
+		t.Fatalf("Exists after Delete = %v, %v; want false, nil", exists, err)
+	}
+}
+
+func TestBlobURL(t *testing.T) {
+	b := NewBlob(Config{Endpoint: "http://localhost:9000", Bucket: "mall"})
+	if got, want := b.URL("images/ab/x.png"), "http://localhost:9000/mall/images/ab/x.png"; got != want {
+		t.Errorf("URL = %q; want %q", got, want)
+	}
+
+	b = NewBlob(Config{Endpoint: "http://localhost:9000", Bucket: "mall", PublicURL: "https://cdn.example.com/"})
+	if got, want := b.URL("images/ab/x.png"), "https://cdn.example.com/images/ab/x.png"; got != want {
+		t.Errorf("URL = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrObjectNotFound = errors.New("object not found")
+
+// Blob 对象存储抽象，key 使用 / 分隔的相对路径
+type Blob interface {
+	Put(ctx context.Context, key string, data io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+	// URL 返回对象对外访问的地址
+	URL(key string) string
+}
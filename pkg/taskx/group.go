@@ -0,0 +1,62 @@
+package taskx
+
+import (
+	"context"
+	"sync"
+)
+
+// Group 跟踪请求返回后仍在后台执行的协程，服务退出时等待它们结束
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	closed  bool
+	running sync.WaitGroup
+}
+
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go 在后台执行 fn，fn 收到的 ctx 不随请求取消，只在 Shutdown 超时后取消。
+// Shutdown 之后不再接受新任务，返回 false
+func (g *Group) Go(fn func(ctx context.Context)) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return false
+	}
+
+	g.running.Add(1)
+	go func() {
+		defer g.running.Done()
+		fn(g.ctx)
+	}()
+	return true
+}
+
+// Shutdown 等待执行中的任务结束，ctx 到期时取消它们并返回 ctx 的错误
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		g.running.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		g.cancel()
+		return ctx.Err()
+	}
+}